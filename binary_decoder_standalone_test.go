@@ -1,7 +1,13 @@
 package avro
 
 import (
+	"bytes"
 	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -12,12 +18,38 @@ func TestBool(t *testing.T) {
 		}
 	}
 	for expected, invalid := range badBooleans {
-		if _, err := NewBinaryDecoder(invalid).ReadBoolean(); err != expected {
+		if _, err := NewBinaryDecoder(invalid).ReadBoolean(); !errors.Is(err, expected) {
 			t.Fatalf("Unexpected error for boolean: expected %v, actual %v", expected, err)
 		}
 	}
 }
 
+func TestInvalidBoolError(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x01, 0x7F, 0x00})
+	_, err := dec.ReadBoolean()
+	assert(t, err, nil)
+	_, err = dec.ReadBoolean()
+	assertError(t, err, InvalidBool)
+	var boolErr *InvalidBoolError
+	assert(t, errors.As(err, &boolErr), true)
+	assert(t, *boolErr, InvalidBoolError{Value: 0x7F, Pos: 1})
+	assert(t, err.Error(), "ReadBoolean at position 1: Invalid bool value 0x7F")
+
+	// the invalid byte is consumed
+	assert(t, dec.Tell(), int64(2))
+	value, err := dec.ReadBoolean()
+	assert(t, err, nil)
+	assert(t, value, false)
+
+	stream := NewStreamBinaryDecoder(bytes.NewReader([]byte{0x00, 0x02}))
+	_, err = stream.ReadBoolean()
+	assert(t, err, nil)
+	_, err = stream.ReadBoolean()
+	assert(t, errors.As(err, &boolErr), true)
+	assert(t, *boolErr, InvalidBoolError{Value: 0x02, Pos: 1})
+	assert(t, stream.Tell(), int64(2))
+}
+
 func TestInt(t *testing.T) {
 	for value, bytes := range goodInts {
 		if actual, _ := NewBinaryDecoder(bytes).ReadInt(); actual != value {
@@ -68,7 +100,7 @@ func TestBytes(t *testing.T) {
 		pair := badBytes[index]
 		expected := pair[0].(error)
 		arr := pair[1].([]byte)
-		if _, err := NewBinaryDecoder(arr).ReadBytes(); err != expected {
+		if _, err := NewBinaryDecoder(arr).ReadBytes(); !errors.Is(err, expected) {
 			t.Fatalf("Unexpected error for bytes: expected %v, actual %v", expected, err)
 		}
 	}
@@ -85,8 +117,992 @@ func TestString(t *testing.T) {
 		pair := badStrings[index]
 		expected := pair[0].(error)
 		arr := pair[1].([]byte)
-		if _, err := NewBinaryDecoder(arr).ReadString(); err != expected {
+		if _, err := NewBinaryDecoder(arr).ReadString(); !errors.Is(err, expected) {
+			t.Fatalf("Unexpected error for string: expected %v, actual %v", expected, err)
+		}
+	}
+}
+
+func TestNullDoesNotAdvance(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x02, 0x04})
+	dec.Seek(1)
+	value, err := dec.ReadNull()
+	assert(t, value, nil)
+	assert(t, err, nil)
+	assert(t, dec.Tell(), int64(1))
+
+	long, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, long, int64(2))
+}
+
+func TestLongFromReader(t *testing.T) {
+	for value, encoded := range goodLongs {
+		if actual, err := ReadLongFromReader(bytes.NewReader(encoded)); err != nil || actual != value {
+			t.Fatalf("Unexpected long: expected %v, actual %v, error %v\n", value, actual, err)
+		}
+	}
+
+	if _, err := ReadLongFromReader(bytes.NewReader([]byte{0x80})); err != EOF {
+		t.Fatalf("Unexpected error for truncated long: expected %v, actual %v", EOF, err)
+	}
+}
+
+func TestVarintSize(t *testing.T) {
+	for value, encoded := range goodInts {
+		dec := NewBinaryDecoder(append(encoded, 0x02))
+		actual, size, err := dec.ReadIntWithSize()
+		assert(t, err, nil)
+		assert(t, actual, value)
+		assert(t, size, len(encoded))
+		assert(t, dec.Tell(), int64(len(encoded)))
+	}
+	for value, encoded := range goodLongs {
+		dec := NewBinaryDecoder(append(encoded, 0x02))
+		actual, size, err := dec.ReadLongWithSize()
+		assert(t, err, nil)
+		assert(t, actual, value)
+		assert(t, size, len(encoded))
+		assert(t, dec.Tell(), int64(len(encoded)))
+	}
+}
+
+func TestVarintLimitsPerDecoder(t *testing.T) {
+	// 987654321 takes 5 bytes as an int and as a long
+	strict := NewBinaryDecoder(goodInts[987654321])
+	strict.SetMaxIntBufSize(4)
+	lenient := NewBinaryDecoder(goodInts[987654321])
+
+	_, err := strict.ReadInt()
+	assertError(t, err, IntOverflow)
+	value, err := lenient.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(987654321))
+
+	strict = NewBinaryDecoder(goodLongs[987654321])
+	strict.SetMaxLongBufSize(4)
+	lenient = NewBinaryDecoder(goodLongs[987654321])
+
+	_, err = strict.ReadLong()
+	assertError(t, err, LongOverflow)
+	longValue, err := lenient.ReadLong()
+	assert(t, err, nil)
+	assert(t, longValue, int64(987654321))
+
+	strict.Reset(goodLongs[987654321])
+	_, err = strict.ReadLong()
+	assertError(t, err, LongOverflow)
+}
+
+func TestMaxVarintLen(t *testing.T) {
+	assert(t, MaxVarintLen32(), 5)
+	assert(t, MaxVarintLen64(), 10)
+
+	// the most negative values take the most bytes
+	buf := &bytes.Buffer{}
+	NewBinaryEncoder(buf).WriteInt(math.MinInt32)
+	assert(t, buf.Len(), MaxVarintLen32())
+	dec := NewBinaryDecoder(buf.Bytes())
+	assert(t, dec.MaxIntBufSize(), MaxVarintLen32())
+	intValue, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, intValue, int32(math.MinInt32))
+
+	buf.Reset()
+	NewBinaryEncoder(buf).WriteLong(math.MinInt64)
+	assert(t, buf.Len(), MaxVarintLen64())
+	dec = NewBinaryDecoder(buf.Bytes())
+	assert(t, dec.MaxLongBufSize(), MaxVarintLen64())
+	longValue, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, longValue, int64(math.MinInt64))
+
+	// one more byte than the limit overflows
+	_, err = NewBinaryDecoder(append(bytes.Repeat([]byte{0xFF}, MaxVarintLen32()), 0x01)).ReadInt()
+	assertError(t, err, IntOverflow)
+	_, err = NewBinaryDecoder(append(bytes.Repeat([]byte{0xFF}, MaxVarintLen64()), 0x01)).ReadLong()
+	assertError(t, err, LongOverflow)
+
+	dec.SetMaxIntBufSize(3)
+	dec.SetMaxLongBufSize(7)
+	assert(t, dec.MaxIntBufSize(), 3)
+	assert(t, dec.MaxLongBufSize(), 7)
+}
+
+func TestSkip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteInt(987654321)
+	enc.WriteLong(-5468631321897454687)
+	enc.WriteFloat(1.15)
+	enc.WriteDouble(-53.964)
+	enc.WriteBytes([]byte{0x01, 0x02, 0x03})
+	enc.WriteString("oppan gangnam style!")
+	enc.WriteRaw([]byte{0x0A, 0x0B})
+	enc.WriteString("end")
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	assert(t, dec.SkipInt(), nil)
+	assert(t, dec.SkipLong(), nil)
+	assert(t, dec.SkipFloat(), nil)
+	assert(t, dec.SkipDouble(), nil)
+	assert(t, dec.SkipBytes(), nil)
+	assert(t, dec.SkipString(), nil)
+	assert(t, dec.SkipFixed(2), nil)
+	value, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "end")
+
+	assertError(t, NewBinaryDecoder([]byte{0x08, 0xFF}).SkipBytes(), EOF)
+	assertError(t, NewBinaryDecoder([]byte{0x05, 0x66}).SkipString(), InvalidStringLength)
+	assertError(t, NewBinaryDecoder([]byte{0x00}).SkipDouble(), EOF)
+	assertError(t, NewBinaryDecoder([]byte{0x00}).SkipFixed(-1), NegativeBytesLength)
+}
+
+func skipBenchmarkPayload() []byte {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	for i := 0; i < 1000; i++ {
+		enc.WriteBytes(make([]byte, 64))
+		enc.WriteString("a string value that is skipped")
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkSkip(b *testing.B) {
+	payload := skipBenchmarkPayload()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewBinaryDecoder(payload)
+		for j := 0; j < 1000; j++ {
+			dec.SkipBytes()
+			dec.SkipString()
+		}
+	}
+}
+
+func BenchmarkDecodeAndDiscard(b *testing.B) {
+	payload := skipBenchmarkPayload()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewBinaryDecoder(payload)
+		for j := 0; j < 1000; j++ {
+			dec.ReadBytes()
+			dec.ReadString()
+		}
+	}
+}
+
+func TestReadEnumWith(t *testing.T) {
+	symbols := []string{"HEARTS", "SPADES", "CLUBS"}
+	dec := NewBinaryDecoder([]byte{0x04, 0x00, 0x02, 0x06, 0x01})
+	for _, expected := range []string{"CLUBS", "HEARTS", "SPADES"} {
+		symbol, err := dec.ReadEnumWith(symbols)
+		assert(t, err, nil)
+		assert(t, symbol, expected)
+	}
+	for range []int{3, -1} {
+		_, err := dec.ReadEnumWith(symbols)
+		assertError(t, err, EnumIndexOutOfRange)
+	}
+	_, err := dec.ReadEnumWith(symbols)
+	assertError(t, err, EOF)
+	_, err = NewBinaryDecoder([]byte{0x00}).ReadEnumWith(nil)
+	assertError(t, err, EnumIndexOutOfRange)
+}
+
+func TestReadBytesInto(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteBytes([]byte{0x01, 0x02, 0x03})
+	enc.WriteBytes([]byte{0x04})
+	enc.WriteBytes([]byte{0x05, 0x06, 0x07, 0x08})
+	dec := NewBinaryDecoder(buf.Bytes())
+
+	dst := make([]byte, 0, 3)
+	value, err := dec.ReadBytesInto(dst)
+	assert(t, err, nil)
+	assert(t, value, []byte{0x01, 0x02, 0x03})
+	assert(t, &value[0], &dst[:1][0])
+
+	value, err = dec.ReadBytesInto(value)
+	assert(t, err, nil)
+	assert(t, value, []byte{0x04})
+	assert(t, &value[0], &dst[:1][0])
+
+	value, err = dec.ReadBytesInto(value)
+	assert(t, err, nil)
+	assert(t, value, []byte{0x05, 0x06, 0x07, 0x08})
+	if &value[0] == &dst[:1][0] {
+		t.Fatal("Expected a new slice to be allocated for a value exceeding the capacity")
+	}
+
+	value, err = NewBinaryDecoder([]byte{0x00}).ReadBytesInto(nil)
+	assert(t, err, nil)
+	assert(t, len(value), 0)
+
+	_, err = NewBinaryDecoder([]byte{0x08, 0xFF}).ReadBytesInto(dst)
+	assertError(t, err, UnexpectedEOF)
+	_, err = NewBinaryDecoder([]byte{0x05}).ReadBytesInto(dst)
+	assertError(t, err, NegativeBytesLength)
+}
+
+// failingWriter accepts a given number of bytes and then fails
+type failingWriter struct {
+	remaining int
+}
+
+func (this *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > this.remaining {
+		n := this.remaining
+		this.remaining = 0
+		return n, io.ErrShortWrite
+	}
+	this.remaining -= len(p)
+	return len(p), nil
+}
+
+func TestReadBytesTo(t *testing.T) {
+	blob := make([]byte, 5*1024*1024)
+	rand.New(rand.NewSource(1)).Read(blob)
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteBytes(blob)
+	enc.WriteLong(42)
+	encoded := buf.Bytes()
+
+	for _, dec := range []interface {
+		ReadBytesTo(w io.Writer) (int64, error)
+		ReadLong() (int64, error)
+	}{NewBinaryDecoder(encoded), NewStreamBinaryDecoder(bytes.NewReader(encoded))} {
+		copied := &bytes.Buffer{}
+		n, err := dec.ReadBytesTo(copied)
+		assert(t, err, nil)
+		assert(t, n, int64(len(blob)))
+		if !bytes.Equal(copied.Bytes(), blob) {
+			t.Fatal("Copied bytes differ from the written blob")
+		}
+		value, err := dec.ReadLong()
+		assert(t, err, nil)
+		assert(t, value, int64(42))
+	}
+
+	n, err := NewBinaryDecoder(encoded).ReadBytesTo(&failingWriter{remaining: 10})
+	assertError(t, err, io.ErrShortWrite)
+	assert(t, n, int64(10))
+	n, err = NewStreamBinaryDecoder(bytes.NewReader(encoded)).ReadBytesTo(&failingWriter{remaining: 10})
+	assertError(t, err, io.ErrShortWrite)
+	assert(t, n, int64(10))
+
+	_, err = NewBinaryDecoder([]byte{0x08, 0xFF}).ReadBytesTo(ioutil.Discard)
+	assertError(t, err, UnexpectedEOF)
+	_, err = NewStreamBinaryDecoder(bytes.NewReader([]byte{0x08, 0xFF})).ReadBytesTo(ioutil.Discard)
+	assertError(t, err, UnexpectedEOF)
+	_, err = NewBinaryDecoder([]byte{0x05}).ReadBytesTo(ioutil.Discard)
+	assertError(t, err, NegativeBytesLength)
+}
+
+func BenchmarkReadBytes(b *testing.B) {
+	payload := skipBenchmarkPayload()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewBinaryDecoder(payload)
+		for j := 0; j < 1000; j++ {
+			dec.ReadBytes()
+			dec.SkipString()
+		}
+	}
+}
+
+func BenchmarkReadBytesInto(b *testing.B) {
+	payload := skipBenchmarkPayload()
+	var dst []byte
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewBinaryDecoder(payload)
+		for j := 0; j < 1000; j++ {
+			dst, _ = dec.ReadBytesInto(dst)
+			dec.SkipString()
+		}
+	}
+}
+
+func TestReadStringUnsafe(t *testing.T) {
+	for value, encoded := range goodStrings {
+		if actual, err := NewBinaryDecoder(encoded).ReadStringUnsafe(); err != nil || actual != value {
+			t.Fatalf("Unexpected string: expected %v, actual %v, error %v", value, actual, err)
+		}
+	}
+	for index := 0; index < len(badStrings); index++ {
+		pair := badStrings[index]
+		expected := pair[0].(error)
+		if _, err := NewBinaryDecoder(pair[1].([]byte)).ReadStringUnsafe(); !errors.Is(err, expected) {
 			t.Fatalf("Unexpected error for string: expected %v, actual %v", expected, err)
 		}
 	}
+
+	// the string aliases the buffer, so modifying the buffer modifies the string
+	buf := []byte{0x06, 0x66, 0x6F, 0x6F}
+	value, err := NewBinaryDecoder(buf).ReadStringUnsafe()
+	assert(t, err, nil)
+	assert(t, value, "foo")
+	buf[1] = 0x62
+	assert(t, value, "boo")
+}
+
+func BenchmarkReadString(b *testing.B) {
+	payload := skipBenchmarkPayload()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewBinaryDecoder(payload)
+		for j := 0; j < 1000; j++ {
+			dec.SkipBytes()
+			dec.ReadString()
+		}
+	}
+}
+
+func BenchmarkReadStringUnsafe(b *testing.B) {
+	payload := skipBenchmarkPayload()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewBinaryDecoder(payload)
+		for j := 0; j < 1000; j++ {
+			dec.SkipBytes()
+			dec.ReadStringUnsafe()
+		}
+	}
+}
+
+func TestTruncatedInt(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x80})
+	_, err := dec.ReadInt()
+	assertError(t, err, EOF)
+
+	dec = NewBinaryDecoder([]byte{0xE2, 0xA2, 0xF3})
+	_, size, err := dec.ReadIntWithSize()
+	assertError(t, err, EOF)
+	assert(t, size, 3)
+}
+
+func TestTruncatedLong(t *testing.T) {
+	truncated := []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80}
+	dec := NewBinaryDecoder(truncated)
+	_, size, err := dec.ReadLongWithSize()
+	assertError(t, err, EOF)
+	assert(t, size, len(truncated))
+
+	_, err = NewBinaryDecoder(truncated[:1]).ReadLong()
+	assertError(t, err, EOF)
+}
+
+func TestEOFAndUnexpectedEOF(t *testing.T) {
+	_, err := NewBinaryDecoder(nil).ReadBytes()
+	assertError(t, err, EOF)
+	_, err = NewBinaryDecoder([]byte{0x06, 0x01}).ReadBytes()
+	assertError(t, err, UnexpectedEOF)
+	_, err = NewBinaryDecoder([]byte{0x80}).ReadBytes()
+	assertError(t, err, UnexpectedEOF)
+
+	_, err = NewBinaryDecoder(nil).ReadString()
+	assertError(t, err, EOF)
+	_, err = NewBinaryDecoder([]byte{0x06, 0x66, 0x6F}).ReadString()
+	assertError(t, err, UnexpectedEOF)
+
+	_, err = NewBinaryDecoder(nil).ReadFloat()
+	assertError(t, err, EOF)
+	_, err = NewBinaryDecoder([]byte{0x00, 0x00, 0x80}).ReadFloat()
+	assertError(t, err, UnexpectedEOF)
+
+	_, err = NewBinaryDecoder(nil).ReadDouble()
+	assertError(t, err, EOF)
+	_, err = NewBinaryDecoder([]byte{0x00, 0x00, 0x00, 0x00}).ReadDouble()
+	assertError(t, err, UnexpectedEOF)
+
+	dec := NewBinaryDecoder([]byte{0x00, 0x00, 0x80, 0x3F})
+	_, err = dec.ReadFloat()
+	assert(t, err, nil)
+	_, err = dec.ReadFloat()
+	assertError(t, err, EOF)
+}
+
+func TestHugeLengths(t *testing.T) {
+	lengths := []int64{math.MaxInt32 + 1, math.MaxInt64 - 1, math.MaxInt64}
+	for _, length := range lengths {
+		buf := &bytes.Buffer{}
+		enc := NewBinaryEncoder(buf)
+		enc.WriteBoolean(true)
+		enc.WriteLong(length)
+		enc.WriteRaw([]byte{0x66, 0x6F, 0x6F})
+
+		dec := NewBinaryDecoder(buf.Bytes())
+		dec.Seek(1)
+		if _, err := dec.ReadString(); !errors.Is(err, UnexpectedEOF) {
+			t.Fatalf("Unexpected error for string of length %d: expected %v, actual %v", length, UnexpectedEOF, err)
+		}
+		dec.Seek(1)
+		if _, err := dec.ReadBytes(); !errors.Is(err, UnexpectedEOF) {
+			t.Fatalf("Unexpected error for bytes of length %d: expected %v, actual %v", length, UnexpectedEOF, err)
+		}
+		dec.Seek(1)
+		if err := dec.SkipString(); !errors.Is(err, EOF) {
+			t.Fatalf("Unexpected error for skipped string of length %d: expected %v, actual %v", length, EOF, err)
+		}
+	}
+}
+
+func TestSizeLimits(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteString("limits")
+	encoded := buf.Bytes()
+
+	dec := NewBinaryDecoder(encoded)
+	dec.SetMaxAllocSize(5)
+	_, err := dec.ReadString()
+	assertError(t, err, SizeLimitExceeded)
+	dec.Seek(0)
+	_, err = dec.ReadBytes()
+	assertError(t, err, SizeLimitExceeded)
+	dec.Seek(1)
+	_, err = dec.ReadFixedAlloc(6)
+	assertError(t, err, SizeLimitExceeded)
+	dec.SetMaxAllocSize(6)
+	dec.Seek(0)
+	value, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "limits")
+
+	// a crafted length prefix fails the same way when the data is there
+	stream := NewStreamBinaryDecoder(bytes.NewReader(encoded))
+	stream.SetMaxAllocSize(5)
+	_, err = stream.ReadString()
+	assertError(t, err, SizeLimitExceeded)
+
+	buf.Reset()
+	enc.WriteArrayStart(1000)
+	enc.WriteMapStart(-1000)
+	enc.WriteLong(12)
+	for _, newDecoder := range []func() Decoder{
+		func() Decoder {
+			dec := NewBinaryDecoder(buf.Bytes())
+			dec.SetMaxArrayElements(999)
+			dec.SetMaxMapEntries(999)
+			return dec
+		},
+		func() Decoder {
+			dec := NewStreamBinaryDecoder(bytes.NewReader(buf.Bytes()))
+			dec.SetMaxArrayElements(999)
+			dec.SetMaxMapEntries(999)
+			return dec
+		},
+	} {
+		dec := newDecoder()
+		_, err = dec.ReadArrayStart()
+		assertError(t, err, SizeLimitExceeded)
+		_, err = dec.ReadMapStart()
+		assertError(t, err, SizeLimitExceeded)
+	}
+
+	dec = NewBinaryDecoder(buf.Bytes())
+	dec.SetMaxArrayElements(1000)
+	dec.SetMaxMapEntries(1000)
+	count, err := dec.ReadArrayStart()
+	assert(t, err, nil)
+	assert(t, count, int64(1000))
+	count, blockSize, err := dec.ReadMapStartWithSize()
+	assert(t, err, nil)
+	assert(t, count, int64(1000))
+	assert(t, blockSize, int64(12))
+	dec.Seek(0)
+	dec.SetMaxArrayElements(10)
+	_, _, err = dec.ReadArrayStartWithSize()
+	assertError(t, err, SizeLimitExceeded)
+
+	// a record holding a huge array fails before reading any item
+	schema := MustParseSchema(`{"type": "array", "items": "null"}`)
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	var array []interface{}
+	dec = NewBinaryDecoder([]byte{0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+	assertError(t, reader.Read(&array, dec), SizeLimitExceeded)
+}
+
+func TestItemCountWithSize(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteArrayStart(2)
+	enc.WriteLong(1)
+	enc.WriteLong(2)
+	enc.WriteLong(-3)
+	enc.WriteLong(3)
+	enc.WriteRaw([]byte{0x06, 0x08, 0x0A})
+	enc.WriteArrayNext(0)
+	enc.WriteString("after")
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	count, size, err := dec.ReadArrayStartWithSize()
+	assert(t, err, nil)
+	assert(t, count, int64(2))
+	assert(t, size, int64(-1))
+	dec.SkipLong()
+	dec.SkipLong()
+
+	count, size, err = dec.ArrayNextWithSize()
+	assert(t, err, nil)
+	assert(t, count, int64(3))
+	assert(t, size, int64(3))
+	dec.Seek(dec.Tell() + size)
+
+	count, _, err = dec.ArrayNextWithSize()
+	assert(t, err, nil)
+	assert(t, count, int64(0))
+	value, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "after")
+
+	dec = NewBinaryDecoder([]byte{0x03, 0x04, 0x02, 0x02, 0x00})
+	count, size, err = dec.ReadMapStartWithSize()
+	assert(t, err, nil)
+	assert(t, count, int64(2))
+	assert(t, size, int64(2))
+	dec.Seek(dec.Tell() + size)
+	count, size, err = dec.MapNextWithSize()
+	assert(t, err, nil)
+	assert(t, count, int64(0))
+	assert(t, size, int64(-1))
+}
+
+func TestTruncatedBlockSize(t *testing.T) {
+	if _, err := NewBinaryDecoder([]byte{0x03}).ReadArrayStart(); !errors.Is(err, EOF) {
+		t.Fatalf("Unexpected error for truncated array block: expected %v, actual %v", EOF, err)
+	}
+	if _, err := NewBinaryDecoder([]byte{0x03}).ReadMapStart(); !errors.Is(err, EOF) {
+		t.Fatalf("Unexpected error for truncated map block: expected %v, actual %v", EOF, err)
+	}
+	if _, _, err := NewBinaryDecoder([]byte{0x03}).ReadArrayStartWithSize(); !errors.Is(err, EOF) {
+		t.Fatalf("Unexpected error for truncated array block: expected %v, actual %v", EOF, err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x06, 0x66, 0x6F, 0x6F})
+	value, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "foo")
+
+	dec.Reset([]byte{0x08, 0x61, 0x76, 0x72, 0x6F})
+	assert(t, dec.Tell(), int64(0))
+	value, err = dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "avro")
+}
+
+var benchmarkMessages = [][]byte{goodLongs[987654321], goodLongs[-1], goodLongs[64]}
+
+// keeps benchmarked decoders reachable so that they are heap allocated like in real code
+var benchmarkDecoder Decoder
+
+func BenchmarkNewDecoderPerMessage(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dec := NewBinaryDecoder(benchmarkMessages[i%len(benchmarkMessages)])
+		dec.ReadLong()
+		benchmarkDecoder = dec
+	}
+}
+
+func BenchmarkResetDecoder(b *testing.B) {
+	b.ReportAllocs()
+	dec := NewBinaryDecoder(nil)
+	for i := 0; i < b.N; i++ {
+		dec.Reset(benchmarkMessages[i%len(benchmarkMessages)])
+		dec.ReadLong()
+		benchmarkDecoder = dec
+	}
+}
+
+func TestStrictFloats(t *testing.T) {
+	for value, encoded := range goodFloats {
+		if actual, err := NewBinaryDecoder(encoded).ReadFloatStrict(); err != nil || actual != value {
+			t.Fatalf("Unexpected float: expected %v, actual %v, error %v", value, actual, err)
+		}
+	}
+	for value, encoded := range goodDoubles {
+		if actual, err := NewBinaryDecoder(encoded).ReadDoubleStrict(); err != nil || actual != value {
+			t.Fatalf("Unexpected double: expected %v, actual %v, error %v", value, actual, err)
+		}
+	}
+
+	for _, float := range []float32{float32(math.NaN()), float32(math.Inf(1)), float32(math.Inf(-1))} {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteFloat(float)
+		dec := NewBinaryDecoder(buf.Bytes())
+		if _, err := dec.ReadFloatStrict(); !errors.Is(err, NonFiniteFloat) {
+			t.Fatalf("Unexpected error for float %v: expected %v, actual %v", float, NonFiniteFloat, err)
+		}
+		assert(t, dec.Tell(), int64(4))
+	}
+	for _, double := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteDouble(double)
+		if _, err := NewBinaryDecoder(buf.Bytes()).ReadDoubleStrict(); !errors.Is(err, NonFiniteFloat) {
+			t.Fatalf("Unexpected error for double %v: expected %v, actual %v", double, NonFiniteFloat, err)
+		}
+		if _, err := NewBinaryDecoder(buf.Bytes()).ReadDouble(); err != nil {
+			t.Fatalf("Unexpected error for permissive double %v: %v", double, err)
+		}
+	}
+}
+
+func TestFixedWithBounds(t *testing.T) {
+	source := []byte{0x01, 0x02, 0x03, 0x04}
+
+	dest := make([]byte, 4)
+	dec := NewBinaryDecoder(source)
+	assert(t, dec.ReadFixedWithBounds(dest, 1, 3), nil)
+	assert(t, dest, []byte{0x00, 0x01, 0x02, 0x03})
+	assert(t, dec.Tell(), int64(3))
+
+	dest = make([]byte, 4)
+	assert(t, NewBinaryDecoder(source).ReadFixedWithBounds(dest, 0, 4), nil)
+	assert(t, dest, source)
+
+	bounds := [][]int{{-1, 2}, {5, 0}, {2, 3}, {0, 5}}
+	for _, b := range bounds {
+		dec := NewBinaryDecoder(source)
+		if err := dec.ReadFixedWithBounds(make([]byte, 4), b[0], b[1]); !errors.Is(err, InvalidBounds) {
+			t.Fatalf("Unexpected error for start %d and length %d: expected %v, actual %v", b[0], b[1], InvalidBounds, err)
+		}
+		assert(t, dec.Tell(), int64(0))
+	}
+	assertError(t, NewBinaryDecoder(source).ReadFixedWithBounds(dest, 0, -1), NegativeBytesLength)
+	assertError(t, NewBinaryDecoder(source[:2]).ReadFixedWithBounds(dest, 1, 3), EOF)
+	assert(t, NewStreamBinaryDecoder(bytes.NewReader(source)).ReadFixedWithBounds(dest, -1, 2), InvalidBounds)
+}
+
+func TestUnionIndex(t *testing.T) {
+	for _, index := range []int{0, 1, 2} {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteLong(int64(index))
+		actual, err := NewBinaryDecoder(buf.Bytes()).ReadUnionIndex(3)
+		assert(t, err, nil)
+		assert(t, actual, index)
+	}
+	for _, index := range []int64{-1, 3, 1 << 40} {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteLong(index)
+		if _, err := NewBinaryDecoder(buf.Bytes()).ReadUnionIndex(3); !errors.Is(err, UnionIndexOutOfRange) {
+			t.Fatalf("Unexpected error for union index %d: expected %v, actual %v", index, UnionIndexOutOfRange, err)
+		}
+	}
+	if _, err := NewBinaryDecoder(nil).ReadUnionIndex(3); !errors.Is(err, EOF) {
+		t.Fatalf("Unexpected error for empty union index: expected %v, actual %v", EOF, err)
+	}
+}
+
+func TestSeekChecked(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x02, 0x04, 0x06})
+	assert(t, dec.SeekChecked(2), nil)
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(3))
+	assert(t, dec.SeekChecked(3), nil)
+	assert(t, dec.Tell(), int64(3))
+
+	assert(t, dec.SeekChecked(-1), InvalidSeek)
+	assert(t, dec.SeekChecked(4), InvalidSeek)
+	assert(t, dec.Tell(), int64(3))
+}
+
+func TestSeekRelative(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x02, 0x04, 0x06})
+	assert(t, dec.SeekRelative(1), nil)
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(2))
+	assert(t, dec.SeekRelative(-2), nil)
+	assert(t, dec.Tell(), int64(0))
+
+	assert(t, dec.SeekRelative(-1), InvalidSeek)
+	assert(t, dec.SeekRelative(4), InvalidSeek)
+	assert(t, dec.Tell(), int64(0))
+	assert(t, dec.SeekRelative(3), nil)
+	_, err = dec.ReadInt()
+	assertError(t, err, EOF)
+}
+
+func TestNewDataBlock(t *testing.T) {
+	block := NewDataBlock([]byte{0x02, 0x04, 0x06}, 3)
+	assert(t, block, &DataBlock{Data: []byte{0x02, 0x04, 0x06}, NumEntries: 3, BlockSize: 3, BlockRemaining: 3})
+
+	dec := NewBinaryDecoder([]byte{0x08})
+	dec.ReadInt()
+	dec.SetBlock(block)
+	assert(t, dec.Tell(), int64(0))
+	var values []int32
+	for i := int64(0); i < block.NumEntries; i++ {
+		value, err := dec.ReadInt()
+		assert(t, err, nil)
+		values = append(values, value)
+	}
+	assert(t, values, []int32{1, 2, 3})
+	assert(t, dec.AtEnd(), true)
+}
+
+func TestSkipArray(t *testing.T) {
+	itemSchema := MustParseSchema(`{"type":"record","name":"Item","fields":[
+		{"name":"id","type":"long"},
+		{"name":"tags","type":{"type":"array","items":"string"}},
+		{"name":"extra","type":["null","double"]}
+	]}`)
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	// a block with a positive count whose items are decoded and discarded
+	enc.WriteArrayStart(1)
+	enc.WriteLong(1)
+	enc.WriteArrayStart(1)
+	enc.WriteString("a")
+	enc.WriteArrayNext(0)
+	enc.WriteLong(1)
+	enc.WriteDouble(1.5)
+	// a block with a negative count and a byte size that is skipped at once
+	enc.WriteArrayNext(-2)
+	enc.WriteLong(6)
+	enc.WriteRaw([]byte{0x04, 0x00, 0x00, 0x06, 0x00, 0x00})
+	enc.WriteArrayNext(0)
+	enc.WriteInt(42)
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	assert(t, dec.SkipArray(itemSchema), nil)
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(42))
+
+	// the byte size is trusted, the items are not decoded
+	assertError(t, NewBinaryDecoder([]byte{0x01, 0x08, 0x00}).SkipArray(&LongSchema{}), EOF)
+	assertError(t, NewBinaryDecoder([]byte{0x02, 0x02, 0x04}).SkipArray(&StringSchema{}), EOF)
+	assertError(t, NewBinaryDecoder([]byte{0x02, 0x04}).SkipArray(&UnionSchema{Types: []Schema{&NullSchema{}}}), UnionIndexOutOfRange)
+}
+
+func TestSkipMap(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteMapStart(2)
+	enc.WriteString("a")
+	enc.WriteBytes([]byte{0x01})
+	enc.WriteString("b")
+	enc.WriteBytes(nil)
+	enc.WriteMapNext(-1)
+	enc.WriteLong(4)
+	enc.WriteString("c")
+	enc.WriteBytes([]byte{0x02})
+	enc.WriteMapNext(0)
+	enc.WriteMapStart(0)
+	enc.WriteInt(42)
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	assert(t, dec.SkipMap(&BytesSchema{}), nil)
+	assert(t, dec.SkipMap(&BytesSchema{}), nil)
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(42))
+
+	assertError(t, NewBinaryDecoder([]byte{0x02, 0x02, 0x61}).SkipMap(&IntSchema{}), EOF)
+}
+
+func TestStrictVarints(t *testing.T) {
+	encodings := []struct {
+		data      []byte
+		canonical bool
+	}{
+		{[]byte{0x02}, true},
+		{[]byte{0x82, 0x00}, false},
+		{[]byte{0x82, 0x80, 0x00}, false},
+	}
+	for _, encoding := range encodings {
+		for _, strict := range []bool{false, true} {
+			dec := NewBinaryDecoder(encoding.data)
+			dec.SetStrictVarints(strict)
+			intValue, err := dec.ReadInt()
+			if strict && !encoding.canonical {
+				assertError(t, err, NonCanonicalVarint)
+			} else {
+				assert(t, err, nil)
+				assert(t, intValue, int32(1))
+			}
+
+			dec = NewBinaryDecoder(encoding.data)
+			dec.SetStrictVarints(strict)
+			longValue, err := dec.ReadLong()
+			if strict && !encoding.canonical {
+				assertError(t, err, NonCanonicalVarint)
+			} else {
+				assert(t, err, nil)
+				assert(t, longValue, int64(1))
+			}
+		}
+	}
+
+	dec := NewBinaryDecoder([]byte{0x00, 0x80, 0x01, 0x80, 0x00})
+	dec.SetStrictVarints(true)
+	value, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, value, int64(0))
+	value, err = dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, value, int64(64))
+	_, err = dec.ReadLong()
+	assertError(t, err, NonCanonicalVarint)
+
+	// lengths are varints too
+	dec = NewBinaryDecoder([]byte{0x82, 0x00, 0x61})
+	dec.SetStrictVarints(true)
+	_, err = dec.ReadBytes()
+	assertError(t, err, NonCanonicalVarint)
+}
+
+func TestReadFixedAlloc(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x01, 0x02, 0x03})
+	fixed, err := dec.ReadFixedAlloc(0)
+	assert(t, err, nil)
+	assert(t, fixed, []byte{})
+	assert(t, dec.Tell(), int64(0))
+
+	fixed, err = dec.ReadFixedAlloc(2)
+	assert(t, err, nil)
+	assert(t, fixed, []byte{0x01, 0x02})
+	assert(t, dec.Tell(), int64(2))
+
+	_, err = dec.ReadFixedAlloc(2)
+	assertError(t, err, EOF)
+	assert(t, dec.Tell(), int64(2))
+	_, err = dec.ReadFixedAlloc(-1)
+	assertError(t, err, NegativeBytesLength)
+}
+
+func TestPeek(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0xC3, 0x01, 0x02, 0x03})
+	peeked, err := dec.Peek(2)
+	assert(t, err, nil)
+	assert(t, peeked, []byte{0xC3, 0x01})
+	assert(t, dec.Tell(), int64(0))
+
+	dec.Seek(2)
+	peeked, err = dec.Peek(2)
+	assert(t, err, nil)
+	assert(t, peeked, []byte{0x02, 0x03})
+	_, err = dec.Peek(3)
+	assertError(t, err, EOF)
+	assert(t, dec.Tell(), int64(2))
+
+	// the peeked bytes are read afterwards and cannot be appended to over the rest of the buffer
+	peeked, err = dec.Peek(1)
+	assert(t, err, nil)
+	_ = append(peeked, 0xFF)
+	value, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, value, int64(1))
+	assert(t, dec.Tell(), int64(3))
+
+	dec.Seek(4)
+	peeked, err = dec.Peek(0)
+	assert(t, err, nil)
+	assert(t, len(peeked), 0)
+	_, err = dec.Peek(1)
+	assertError(t, err, EOF)
+	_, err = dec.Peek(-1)
+	assertError(t, err, NegativeBytesLength)
+	dec.Seek(5)
+	_, err = dec.Peek(0)
+	assertError(t, err, EOF)
+}
+
+func TestMarkRestore(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x02, 0x04, 0x06})
+	dec.ReadInt()
+	mark := dec.Mark()
+	assert(t, mark, int64(1))
+	dec.ReadInt()
+	assert(t, dec.Restore(mark), nil)
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(2))
+
+	assertError(t, dec.Restore(4), InvalidSeek)
+	assertError(t, dec.Restore(-1), InvalidSeek)
+	assert(t, dec.Tell(), int64(2))
+}
+
+func TestWithRollback(t *testing.T) {
+	// a string followed by a truncated double
+	dec := NewBinaryDecoder([]byte{0x02, 0x61, 0x00, 0x00})
+	err := dec.WithRollback(func() error {
+		if _, err := dec.ReadString(); err != nil {
+			return err
+		}
+		_, err := dec.ReadDouble()
+		return err
+	})
+	assertError(t, err, UnexpectedEOF)
+	assert(t, dec.Tell(), int64(0))
+
+	err = dec.WithRollback(func() error {
+		_, err := dec.ReadString()
+		return err
+	})
+	assert(t, err, nil)
+	assert(t, dec.Tell(), int64(2))
+}
+
+func TestRemaining(t *testing.T) {
+	schema := MustParseSchema(genericTestSchema)
+	datumReader := NewGenericDatumReader()
+	datumReader.SetSchema(schema)
+
+	data := encodeGenericTestRecord()
+	dec := NewBinaryDecoder(data)
+	assert(t, dec.Remaining(), int64(len(data)))
+	assert(t, dec.AtEnd(), false)
+	assert(t, datumReader.Read(NewGenericRecord(schema), dec), nil)
+	assert(t, dec.Remaining(), int64(0))
+	assert(t, dec.AtEnd(), true)
+
+	dec = NewBinaryDecoder(append(data, 0x00))
+	assert(t, datumReader.Read(NewGenericRecord(schema), dec), nil)
+	assert(t, dec.Remaining(), int64(1))
+	assert(t, dec.AtEnd(), false)
+
+	dec.Seek(int64(len(data) + 5))
+	assert(t, dec.Remaining(), int64(0))
+}
+
+func TestDecodeError(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x02, 0x06, 0x61})
+	_, err := dec.ReadInt()
+	assert(t, err, nil)
+	_, err = dec.ReadString()
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected a DecodeError, actual %v", err)
+	}
+	assert(t, decodeErr.Op, "ReadString")
+	assert(t, decodeErr.Pos, int64(1))
+	assert(t, decodeErr.Err, UnexpectedEOF)
+	assert(t, errors.Is(err, UnexpectedEOF), true)
+	assert(t, errors.Is(err, InvalidStringLength), false)
+	assert(t, err.Error(), "ReadString at position 1: Unexpected end of file in the middle of a value")
+
+	// errors are reported for the called method and the start of the value
+	_, err = NewBinaryDecoder([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80}).ReadInt()
+	assert(t, err, &DecodeError{Op: "ReadInt", Pos: 0, Err: IntOverflow})
+	_, err = NewBinaryDecoder([]byte{0x03}).ReadMapStart()
+	assert(t, err, &DecodeError{Op: "ReadMapStart", Pos: 0, Err: EOF})
 }
@@ -2,6 +2,7 @@ package avro
 
 import (
 	"bytes"
+	"math"
 	"math/rand"
 	"testing"
 )
@@ -132,3 +133,81 @@ func testPrimitiveSerialization(t *testing.T, random func(int) interface{}, seri
 		}
 	}
 }
+
+func TestVarintEncodingMatchesDecoding(t *testing.T) {
+	for value, expected := range goodInts {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteInt(value)
+		assert(t, buf.Bytes(), expected)
+	}
+	for value, expected := range goodLongs {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteLong(value)
+		assert(t, buf.Bytes(), expected)
+	}
+}
+
+func TestBinaryEncoderBytesReset(t *testing.T) {
+	enc := NewBinaryEncoder(nil)
+	enc.WriteString("first")
+	enc.WriteLong(-3)
+	assert(t, enc.Bytes(), []byte{0x0A, 'f', 'i', 'r', 's', 't', 0x05})
+	assert(t, enc.Tell(), int64(7))
+
+	enc.Reset()
+	assert(t, enc.Tell(), int64(0))
+	enc.WriteBoolean(true)
+	enc.WriteString("2nd")
+	assert(t, enc.Bytes(), []byte{0x01, 0x06, '2', 'n', 'd'})
+
+	dec := NewBinaryDecoder(enc.Bytes())
+	flag, err := dec.ReadBoolean()
+	assert(t, err, nil)
+	assert(t, flag, true)
+	str, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, str, "2nd")
+
+	// a given buffer is written to as before
+	buf := &bytes.Buffer{}
+	enc = NewBinaryEncoder(buf)
+	enc.WriteInt(1)
+	assert(t, enc.Bytes(), buf.Bytes())
+	enc.Reset()
+	assert(t, buf.Len(), 0)
+}
+
+func TestBoundarySerialization(t *testing.T) {
+	ints := []int32{math.MinInt32, math.MinInt32 + 1, -64, -1, 0, 1, 63, 64, math.MaxInt32 - 1, math.MaxInt32}
+	for _, value := range ints {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteInt(value)
+		decoded, err := NewBinaryDecoder(buf.Bytes()).ReadInt()
+		assert(t, err, nil)
+		assert(t, decoded, value)
+	}
+
+	longs := []int64{math.MinInt64, math.MinInt64 + 1, math.MinInt32, -1, 0, 1, math.MaxInt32, math.MaxInt64 - 1, math.MaxInt64}
+	for _, value := range longs {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteLong(value)
+		decoded, err := NewBinaryDecoder(buf.Bytes()).ReadLong()
+		assert(t, err, nil)
+		assert(t, decoded, value)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteArrayStart(3)
+	enc.WriteArrayNext(0)
+	enc.WriteMapStart(math.MaxInt64)
+	enc.WriteMapNext(0)
+	dec := NewBinaryDecoder(buf.Bytes())
+	dec.SetMaxArrayElements(math.MaxInt64)
+	for _, expected := range []int64{3, 0, math.MaxInt64, 0} {
+		count, err := dec.ReadArrayStart()
+		assert(t, err, nil)
+		assert(t, count, expected)
+	}
+	assert(t, dec.Tell(), enc.Tell())
+}
@@ -0,0 +1,180 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Transforms a given Schema into its Parsing Canonical Form as described in
+// https://avro.apache.org/docs/current/spec.html#Parsing+Canonical+Form+for+Schemas
+// The canonical form contains no whitespace, docs, aliases, defaults or custom properties (including logical types),
+// names are replaced by fullnames and the attributes of each type are written in the order defined by the spec.
+// Schemas with the same canonical form are considered equal for reading data. Returns InvalidSchema if the schema
+// is not one of the Schema implementations of this package.
+func CanonicalForm(schema Schema) (string, error) {
+	buffer := &bytes.Buffer{}
+	if err := writeCanonicalForm(schema, "", make(map[Schema]string), false, buffer); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// Transforms a given Schema into its Parsing Canonical Form like CanonicalForm but keeps the logicalType, precision
+// and scale attributes, written after the attributes the spec defines, so that e.g. decimals of different precision
+// are told apart. The result is not the Parsing Canonical Form of the spec, so it must not be used to compute
+// fingerprints. Returns InvalidSchema if the schema is not one of the Schema implementations of this package.
+func CanonicalFormExtended(schema Schema) (string, error) {
+	buffer := &bytes.Buffer{}
+	if err := writeCanonicalForm(schema, "", make(map[Schema]string), true, buffer); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// writes the canonical form of a schema within an enclosing namespace, named types that were already written are
+// written as their fullnames. Logical type attributes are kept if extended is true.
+func writeCanonicalForm(schema Schema, namespace string, fullNames map[Schema]string, extended bool, buffer *bytes.Buffer) error {
+	switch s := schema.(type) {
+	case *NullSchema, *BooleanSchema, *IntSchema, *LongSchema, *FloatSchema, *DoubleSchema, *BytesSchema, *StringSchema:
+		if _, ok := s.Prop(schema_logicalTypeProp); ok && extended {
+			buffer.WriteString(`{"type":`)
+			writeCanonicalString(s.GetName(), buffer)
+			writeCanonicalLogicalType(s, buffer)
+			buffer.WriteString("}")
+			return nil
+		}
+		writeCanonicalString(s.GetName(), buffer)
+	case *RecordSchema:
+		fullName, written := canonicalFullName(s, s.Name, s.Namespace, namespace, fullNames)
+		if written {
+			writeCanonicalString(fullName, buffer)
+			return nil
+		}
+
+		buffer.WriteString(`{"name":`)
+		writeCanonicalString(fullName, buffer)
+		buffer.WriteString(`,"type":"record","fields":[`)
+		recordNamespace := ""
+		if i := strings.LastIndex(fullName, "."); i >= 0 {
+			recordNamespace = fullName[:i]
+		}
+		for i, field := range s.Fields {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			buffer.WriteString(`{"name":`)
+			writeCanonicalString(field.Name, buffer)
+			buffer.WriteString(`,"type":`)
+			if err := writeCanonicalForm(field.Type, recordNamespace, fullNames, extended, buffer); err != nil {
+				return err
+			}
+			buffer.WriteString("}")
+		}
+		buffer.WriteString("]}")
+	case *RecursiveSchema:
+		fullName, _ := canonicalFullName(s.Actual, s.Actual.Name, s.Actual.Namespace, namespace, fullNames)
+		writeCanonicalString(fullName, buffer)
+	case *EnumSchema:
+		fullName, written := canonicalFullName(s, s.Name, s.Namespace, namespace, fullNames)
+		if written {
+			writeCanonicalString(fullName, buffer)
+			return nil
+		}
+
+		buffer.WriteString(`{"name":`)
+		writeCanonicalString(fullName, buffer)
+		buffer.WriteString(`,"type":"enum","symbols":[`)
+		for i, symbol := range s.Symbols {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			writeCanonicalString(symbol, buffer)
+		}
+		buffer.WriteString("]}")
+	case *FixedSchema:
+		fullName, written := canonicalFullName(s, s.Name, s.Namespace, namespace, fullNames)
+		if written {
+			writeCanonicalString(fullName, buffer)
+			return nil
+		}
+
+		buffer.WriteString(`{"name":`)
+		writeCanonicalString(fullName, buffer)
+		buffer.WriteString(fmt.Sprintf(`,"type":"fixed","size":%d`, s.Size))
+		if extended {
+			writeCanonicalLogicalType(s, buffer)
+		}
+		buffer.WriteString("}")
+	case *ArraySchema:
+		buffer.WriteString(`{"type":"array","items":`)
+		if err := writeCanonicalForm(s.Items, namespace, fullNames, extended, buffer); err != nil {
+			return err
+		}
+		buffer.WriteString("}")
+	case *MapSchema:
+		buffer.WriteString(`{"type":"map","values":`)
+		if err := writeCanonicalForm(s.Values, namespace, fullNames, extended, buffer); err != nil {
+			return err
+		}
+		buffer.WriteString("}")
+	case *UnionSchema:
+		buffer.WriteString("[")
+		for i, unionType := range s.Types {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			if err := writeCanonicalForm(unionType, namespace, fullNames, extended, buffer); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("]")
+	default:
+		return InvalidSchema
+	}
+
+	return nil
+}
+
+// returns the fullname of a named type and whether it was already written, the fullname is remembered so that
+// references to the type resolve to the same name regardless of the namespace they appear in
+func canonicalFullName(schema Schema, name string, namespaceAttr string, namespace string, fullNames map[Schema]string) (string, bool) {
+	if fullName, exists := fullNames[schema]; exists {
+		return fullName, true
+	}
+
+	if namespaceAttr != "" {
+		namespace = namespaceAttr
+	}
+	fullName := getFullName(name, namespace)
+	fullNames[schema] = fullName
+	return fullName, false
+}
+
+// writes the logicalType, precision and scale attributes a schema has, numbers are written as JSON numbers
+func writeCanonicalLogicalType(schema Schema, buffer *bytes.Buffer) {
+	for _, name := range []string{schema_logicalTypeProp, "precision", "scale"} {
+		value, ok := schema.Prop(name)
+		if !ok {
+			continue
+		}
+		buffer.WriteString(",")
+		writeCanonicalString(name, buffer)
+		buffer.WriteString(":")
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil && name != schema_logicalTypeProp {
+			buffer.WriteString(value)
+		} else {
+			writeCanonicalString(value, buffer)
+		}
+	}
+}
+
+func writeCanonicalString(value string, buffer *bytes.Buffer) {
+	// marshalling a string never fails
+	encoded, _ := json.Marshal(value)
+	buffer.Write(encoded)
+}
@@ -0,0 +1,90 @@
+package avro
+
+import "testing"
+
+func TestCanonicalForm(t *testing.T) {
+	// examples from the Avro specification test suite (share/test/data/schema-tests.txt)
+	examples := []struct {
+		schema    string
+		canonical string
+	}{
+		{`"null"`, `"null"`},
+		{`{"type": "int"}`, `"int"`},
+		{`{"type": "int", "logicalType": "date"}`, `"int"`},
+		{`{"type": "fixed", "name": "Test", "size": 1}`, `{"name":"Test","type":"fixed","size":1}`},
+		{`{"type": "fixed", "name": "MyFixed", "namespace": "org.apache.hadoop.avro", "size": 1}`,
+			`{"name":"org.apache.hadoop.avro.MyFixed","type":"fixed","size":1}`},
+		{`{"type": "enum", "name": "Test", "symbols": ["A", "B"], "doc": "Enum doc"}`,
+			`{"name":"Test","type":"enum","symbols":["A","B"]}`},
+		{`{"type": "array", "items": "long"}`, `{"type":"array","items":"long"}`},
+		{`{"type": "array", "items": {"type": "enum", "name": "Test", "symbols": ["A", "B"]}}`,
+			`{"type":"array","items":{"name":"Test","type":"enum","symbols":["A","B"]}}`},
+		{`{"type": "map", "values": "long"}`, `{"type":"map","values":"long"}`},
+		{`["string", "null", "long"]`, `["string","null","long"]`},
+		{`{"type": "record", "name": "Test", "fields": [{"name": "f", "type": "long"}]}`,
+			`{"name":"Test","type":"record","fields":[{"name":"f","type":"long"}]}`},
+		{`{"type": "record", "name": "Node", "fields": [
+			{"name": "label", "type": "string"},
+			{"name": "children", "type": {"type": "array", "items": "Node"}}]}`,
+			`{"name":"Node","type":"record","fields":[{"name":"label","type":"string"},{"name":"children","type":{"type":"array","items":"Node"}}]}`},
+		{`{"type": "record", "name": "Lisp", "fields": [
+			{"name": "value", "type": ["null", "string", {"type": "record", "name": "Cons", "fields": [
+				{"name": "car", "type": "Lisp"}, {"name": "cdr", "type": "Lisp"}]}]}]}`,
+			`{"name":"Lisp","type":"record","fields":[{"name":"value","type":["null","string",{"name":"Cons","type":"record","fields":[{"name":"car","type":"Lisp"},{"name":"cdr","type":"Lisp"}]}]}]}`},
+		{`{"type": "record", "name": "HandshakeRequest", "namespace": "org.apache.avro.ipc", "fields": [
+			{"name": "clientHash", "type": {"type": "fixed", "name": "MD5", "size": 16}},
+			{"name": "clientProtocol", "type": ["null", "string"]},
+			{"name": "serverHash", "type": "MD5"},
+			{"name": "meta", "type": ["null", {"type": "map", "values": "bytes"}]}]}`,
+			`{"name":"org.apache.avro.ipc.HandshakeRequest","type":"record","fields":[{"name":"clientHash","type":{"name":"org.apache.avro.ipc.MD5","type":"fixed","size":16}},{"name":"clientProtocol","type":["null","string"]},{"name":"serverHash","type":"org.apache.avro.ipc.MD5"},{"name":"meta","type":["null",{"type":"map","values":"bytes"}]}]}`},
+	}
+
+	for _, example := range examples {
+		schema, err := ParseSchema(example.schema)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %s", example.schema, err)
+		}
+		canonical, err := CanonicalForm(schema)
+		assert(t, err, nil)
+		assert(t, canonical, example.canonical)
+	}
+}
+
+func TestCanonicalFormNamespaces(t *testing.T) {
+	// a dotted name overrides the namespace attribute and nested types inherit the namespace of the enclosing type
+	schema := MustParseSchema(`{"type": "record", "name": "a.b.Outer", "namespace": "ignored", "fields": [
+		{"name": "first", "type": {"type": "fixed", "name": "Inner", "size": 1}},
+		{"name": "second", "type": {"type": "enum", "name": "c.Other", "symbols": ["X"]}},
+		{"name": "third", "type": "Inner"}]}`)
+	canonical, err := CanonicalForm(schema)
+	assert(t, err, nil)
+	assert(t, canonical, `{"name":"a.b.Outer","type":"record","fields":[{"name":"first","type":{"name":"a.b.Inner","type":"fixed","size":1}},{"name":"second","type":{"name":"c.Other","type":"enum","symbols":["X"]}},{"name":"third","type":"a.b.Inner"}]}`)
+}
+
+type unknownSchema struct {
+	StringSchema
+}
+
+func TestCanonicalFormUnknownSchema(t *testing.T) {
+	_, err := CanonicalForm(&unknownSchema{})
+	assert(t, err, InvalidSchema)
+}
+
+func TestCanonicalFormExtended(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Price", "fields": [
+		{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}},
+		{"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 8, "logicalType": "decimal", "precision": 18}},
+		{"name": "note", "type": "string"}
+	]}`)
+
+	standard, err := CanonicalForm(schema)
+	assert(t, err, nil)
+	assert(t, standard, `{"name":"Price","type":"record","fields":[{"name":"amount","type":"bytes"},{"name":"hash","type":{"name":"Hash","type":"fixed","size":8}},{"name":"note","type":"string"}]}`)
+
+	extended, err := CanonicalFormExtended(schema)
+	assert(t, err, nil)
+	assert(t, extended, `{"name":"Price","type":"record","fields":[{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}},{"name":"hash","type":{"name":"Hash","type":"fixed","size":8,"logicalType":"decimal","precision":18}},{"name":"note","type":"string"}]}`)
+
+	_, err = CanonicalFormExtended(&unknownSchema{})
+	assert(t, err, InvalidSchema)
+}
@@ -0,0 +1,142 @@
+package avro
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+)
+
+// Codec compresses and decompresses the data of Object Container File blocks. Codecs are looked up by the
+// avro.codec metadata value of a data file and may be added with RegisterCodec.
+type Codec interface {
+	// Encode compresses the data of a block. Codecs that can only decompress data return CodecEncodeUnsupported,
+	// which makes DataFileWriter reject them.
+	Encode([]byte) ([]byte, error)
+
+	// Decode decompresses the data of a block.
+	Decode([]byte) ([]byte, error)
+}
+
+// codecs available for data files keyed by their avro.codec metadata name
+var codecs = map[string]Codec{
+	"null":    nullCodec{},
+	"deflate": deflateCodec{maxSize: max_alloc_size},
+	"snappy":  snappyCodec{},
+	"bzip2":   bzip2Codec{maxSize: max_alloc_size},
+}
+
+// returns the codec for a given avro.codec metadata value, where a missing value means no compression
+func findCodec(name string) (Codec, error) {
+	if name == "" {
+		name = "null"
+	}
+	if c, ok := codecs[name]; ok {
+		return c, nil
+	}
+	return nil, UnsupportedCodec
+}
+
+// Registers a Codec under a given avro.codec metadata name so that DataFileReader and DataFileWriter can use it,
+// replacing a codec previously registered with that name. Should be called during initialization as it is not
+// safe to call concurrently with reading or writing data files.
+func RegisterCodec(name string, codec Codec) {
+	codecs[name] = codec
+}
+
+// nullCodec leaves block data uncompressed.
+type nullCodec struct{}
+
+func (nullCodec) Encode(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (nullCodec) Decode(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// deflateCodec compresses block data with raw deflate as described in RFC 1951. Decompressing more than maxSize bytes
+// returns SizeLimitExceeded, so that small blocks cannot expand to exhaust memory.
+type deflateCodec struct {
+	maxSize int64
+}
+
+func (deflateCodec) Encode(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer, err := flate.NewWriter(buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (this deflateCodec) Decode(data []byte) ([]byte, error) {
+	reader := flate.NewReader(bytes.NewReader(data))
+	defer reader.Close()
+	// reads one byte more than allowed to tell whether the data exceeds the limit
+	decoded, err := ioutil.ReadAll(io.LimitReader(reader, this.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decoded)) > this.maxSize {
+		return nil, SizeLimitExceeded
+	}
+	return decoded, nil
+}
+
+// snappyCodec compresses block data with snappy and appends a big-endian CRC32 checksum of the uncompressed data.
+type snappyCodec struct{}
+
+func (snappyCodec) Encode(data []byte) ([]byte, error) {
+	encoded := snappyEncode(data)
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(data))
+	return append(encoded, checksum...), nil
+}
+
+func (snappyCodec) Decode(data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, InvalidSnappyData
+	}
+	decoded, err := snappyDecode(data[:len(data)-4])
+	if err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(decoded) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return nil, SnappyCRCMismatch
+	}
+	return decoded, nil
+}
+
+// bzip2Codec decompresses block data compressed with bzip2, e.g. in data files written by older Hadoop tools. The
+// standard library only implements bzip2 decompression, so the codec can read such files but not write them.
+// Decompressing more than maxSize bytes returns SizeLimitExceeded, so that small blocks cannot expand to exhaust
+// memory.
+type bzip2Codec struct {
+	maxSize int64
+}
+
+func (bzip2Codec) Encode(data []byte) ([]byte, error) {
+	return nil, CodecEncodeUnsupported
+}
+
+func (this bzip2Codec) Decode(data []byte) ([]byte, error) {
+	// reads one byte more than allowed to tell whether the data exceeds the limit
+	decoded, err := ioutil.ReadAll(io.LimitReader(bzip2.NewReader(bytes.NewReader(data)), this.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decoded)) > this.maxSize {
+		return nil, SizeLimitExceeded
+	}
+	return decoded, nil
+}
@@ -0,0 +1,180 @@
+package avro
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func codecTestPayloads() [][]byte {
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+	repeated := bytes.Repeat([]byte("avro data file block "), 5000)
+	return [][]byte{{}, []byte("a"), []byte("abcdabcdabcd"), random, repeated, append(random[:70000], random[:70000]...)}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for name, codec := range codecs {
+		for _, payload := range codecTestPayloads() {
+			encoded, err := codec.Encode(payload)
+			if err == CodecEncodeUnsupported {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error encoding with %s codec: %v", name, err)
+			}
+			decoded, err := codec.Decode(encoded)
+			if err != nil {
+				t.Fatalf("Unexpected error decoding with %s codec: %v", name, err)
+			}
+			if !bytes.Equal(decoded, payload) {
+				t.Fatalf("Unexpected data decoded with %s codec for payload of length %d", name, len(payload))
+			}
+		}
+	}
+}
+
+func TestSnappyDecode(t *testing.T) {
+	// a literal "abcd" followed by copies with 1 and 2 byte offsets
+	decoded, err := snappyDecode([]byte{0x10, 0x0C, 'a', 'b', 'c', 'd', 0x11, 0x04, 0x0E, 0x08, 0x00})
+	assert(t, err, nil)
+	assert(t, string(decoded), "abcdabcdabcdabcd")
+
+	invalid := [][]byte{
+		{},
+		{0x05, 0x0C, 'a'},
+		{0x04, 0x0E, 0x01, 0x00},
+		{0x08, 0x0C, 'a', 'b', 'c', 'd', 0x0E, 0x05, 0x00},
+		{0x02, 0x0C, 'a', 'b', 'c', 'd'},
+		// claims more data than 5 bytes can expand to
+		{0x80, 0x01, 0x0C, 'a', 'b', 'c', 'd'},
+	}
+	for _, data := range invalid {
+		if _, err := snappyDecode(data); err != InvalidSnappyData {
+			t.Errorf("Unexpected error for snappy data %v: expected %v, actual %v", data, InvalidSnappyData, err)
+		}
+	}
+}
+
+func TestSnappyCRC(t *testing.T) {
+	encoded, err := snappyCodec{}.Encode([]byte("abcdabcdabcd"))
+	assert(t, err, nil)
+	encoded[len(encoded)-1] ^= 0xFF
+	_, err = snappyCodec{}.Decode(encoded)
+	assert(t, err, SnappyCRCMismatch)
+
+	_, err = snappyCodec{}.Decode([]byte{0x00, 0x00})
+	assert(t, err, InvalidSnappyData)
+}
+
+func TestDeflateCodecSizeLimit(t *testing.T) {
+	payload := bytes.Repeat([]byte{0}, 1000)
+	encoded, err := deflateCodec{}.Encode(payload)
+	assert(t, err, nil)
+
+	decoded, err := deflateCodec{maxSize: 1000}.Decode(encoded)
+	assert(t, err, nil)
+	assert(t, decoded, payload)
+	_, err = deflateCodec{maxSize: 999}.Decode(encoded)
+	assert(t, err, SizeLimitExceeded)
+}
+
+func TestBzip2Codec(t *testing.T) {
+	// blocks holding 1, 2, 3 and 4, 5 compressed with the bzip2 command line tool
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	encodeDataFileHeader(enc, "bzip2")
+	firstBlock := []byte{
+		0x42, 0x5A, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x14, 0x88, 0xB7, 0xEA, 0x00, 0x00, 0x00, 0x40, 0x00,
+		0x15, 0x00, 0x20, 0x00, 0x21, 0x98, 0x19, 0x84, 0x61, 0x77, 0x24, 0x53, 0x85, 0x09, 0x01, 0x48, 0x8B, 0x7E, 0xA0,
+	}
+	encodeDataFileBlock(enc, 3, firstBlock)
+	encodeDataFileBlock(enc, 2, []byte{
+		0x42, 0x5A, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x5A, 0x8A, 0x0E, 0x06, 0x00, 0x00, 0x00, 0x40, 0x00,
+		0x00, 0x50, 0x20, 0x00, 0x21, 0x00, 0x82, 0xB1, 0x77, 0x24, 0x53, 0x85, 0x09, 0x05, 0xA8, 0xA0, 0xE0, 0x60,
+	})
+
+	filename := writeTempDataFile(t, buf.Bytes())
+	defer os.Remove(filename)
+	assert(t, readDataFileValues(t, filename), []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5)})
+
+	_, err := codecs["bzip2"].Decode([]byte("not bzip2"))
+	if err == nil {
+		t.Fatal("Expected an error for invalid bzip2 data")
+	}
+
+	writer, err := NewDataFileWriter(&bytes.Buffer{}, MustParseSchema(dataFileTestSchema), NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.SetCodec("bzip2"), CodecEncodeUnsupported)
+
+	// the first block decompresses to 3 bytes
+	decoded, err := bzip2Codec{maxSize: 3}.Decode(firstBlock)
+	assert(t, err, nil)
+	assert(t, decoded, []byte{0x02, 0x04, 0x06})
+	_, err = bzip2Codec{maxSize: 2}.Decode(firstBlock)
+	assert(t, err, SizeLimitExceeded)
+}
+
+// decodeOnlyCodec is a codec that can only decompress data
+type decodeOnlyCodec struct {
+	xorCodec
+}
+
+func (decodeOnlyCodec) Encode(data []byte) ([]byte, error) {
+	return nil, fmt.Errorf("xor: %w", CodecEncodeUnsupported)
+}
+
+func TestRegisterDecodeOnlyCodec(t *testing.T) {
+	RegisterCodec("xor-legacy", decodeOnlyCodec{})
+	defer delete(codecs, "xor-legacy")
+
+	writer, err := NewDataFileWriter(&bytes.Buffer{}, MustParseSchema(dataFileTestSchema), NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.SetCodec("xor-legacy"), CodecEncodeUnsupported)
+}
+
+// xorCodec is a trivial codec flipping all bits of the data
+type xorCodec struct{}
+
+func (xorCodec) Encode(data []byte) ([]byte, error) {
+	encoded := make([]byte, len(data))
+	for i, b := range data {
+		encoded[i] = b ^ 0xFF
+	}
+	return encoded, nil
+}
+
+func (xorCodec) Decode(data []byte) ([]byte, error) {
+	return xorCodec{}.Encode(data)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("xor", xorCodec{})
+	defer delete(codecs, "xor")
+
+	buf := &bytes.Buffer{}
+	schema := MustParseSchema(dataFileTestSchema)
+	writer, err := NewDataFileWriter(buf, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.SetCodec("xor"), nil)
+	record := NewGenericRecord(schema)
+	record.Set("value", int64(3))
+	assert(t, writer.Append(record), nil)
+	assert(t, writer.Close(), nil)
+
+	// the block holding the single value must be stored encoded
+	data := buf.Bytes()
+	assert(t, data[len(data)-sync_size-2:len(data)-sync_size], []byte{0x02, 0x06 ^ 0xFF})
+
+	filename := writeTempDataFile(t, data)
+	defer os.Remove(filename)
+	assert(t, readDataFileValues(t, filename), []interface{}{int64(3)})
+}
@@ -0,0 +1,58 @@
+//go:build zstd
+// +build zstd
+
+package avro
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// The zstandard codec depends on github.com/klauspost/compress and is only available when building with the zstd
+// tag, e.g. go build -tags zstd.
+func init() {
+	codecs["zstandard"] = zstdCodec{}
+	codecs["zstd"] = zstdCodec{}
+}
+
+var (
+	zstdOnce       sync.Once
+	zstdEncoder    *zstd.Encoder
+	zstdDecoder    *zstd.Decoder
+	zstdEncoderErr error
+	zstdDecoderErr error
+)
+
+// creates the shared encoder and decoder on first use so that errors creating them are returned by the codec instead
+// of being lost during initialization. The decoder refuses to allocate more than max_alloc_size bytes.
+func initZstd() {
+	zstdOnce.Do(func() {
+		zstdEncoder, zstdEncoderErr = zstd.NewWriter(nil)
+		zstdDecoder, zstdDecoderErr = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(max_alloc_size))
+	})
+}
+
+// zstdCodec compresses block data with Zstandard.
+type zstdCodec struct{}
+
+func (zstdCodec) Encode(data []byte) ([]byte, error) {
+	initZstd()
+	if zstdEncoderErr != nil {
+		return nil, zstdEncoderErr
+	}
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func (zstdCodec) Decode(data []byte) ([]byte, error) {
+	initZstd()
+	if zstdDecoderErr != nil {
+		return nil, zstdDecoderErr
+	}
+	decoded, err := zstdDecoder.DecodeAll(data, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, SizeLimitExceeded
+	}
+	return decoded, err
+}
@@ -0,0 +1,58 @@
+//go:build zstd
+// +build zstd
+
+package avro
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDataFileReaderZstd(t *testing.T) {
+	// values 0 to 9 repeated 4 times compressed by the reference zstd implementation
+	block := []byte{0x28, 0xB5, 0x2F, 0xFD, 0x24, 0x28, 0x8D, 0x00, 0x00, 0x58, 0x00, 0x02, 0x04, 0x06, 0x08, 0x0A,
+		0x0C, 0x0E, 0x10, 0x12, 0x00, 0x01, 0x00, 0xBD, 0x8B, 0x17, 0xB3, 0x4C, 0xB9, 0x96}
+	var expected []interface{}
+	for i := int64(0); i < 40; i++ {
+		expected = append(expected, i%10)
+	}
+
+	for _, name := range []string{"zstandard", "zstd"} {
+		buf := &bytes.Buffer{}
+		enc := NewBinaryEncoder(buf)
+		encodeDataFileHeader(enc, name)
+		encodeDataFileBlock(enc, 40, block)
+		filename := writeTempDataFile(t, buf.Bytes())
+		defer os.Remove(filename)
+
+		assert(t, readDataFileValues(t, filename), expected)
+	}
+}
+
+func TestDataFileWriterZstd(t *testing.T) {
+	file, err := ioutil.TempFile("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	schema := MustParseSchema(dataFileTestSchema)
+	writer, err := NewDataFileWriter(file, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.SetCodec("zstandard"), nil)
+	writer.SetBlockSize(100)
+	var expected []interface{}
+	for i := int64(0); i < 1000; i++ {
+		record := NewGenericRecord(schema)
+		record.Set("value", i*7)
+		assert(t, writer.Append(record), nil)
+		expected = append(expected, i*7)
+	}
+	assert(t, writer.Close(), nil)
+
+	assert(t, readDataFileValues(t, file.Name()), expected)
+}
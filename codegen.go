@@ -173,7 +173,16 @@ func (this *CodeGenerator) writeStruct(info *recordSchemaInfo) error {
 
 	this.writeSchemaGetter(info, buffer)
 
-	return nil
+	buffer.WriteString("\n\n")
+
+	err = this.writeStructWriter(info, buffer)
+	if err != nil {
+		return err
+	}
+
+	buffer.WriteString("\n\n")
+
+	return this.writeStructReader(info, buffer)
 }
 
 func (this *CodeGenerator) writeEnum(info *enumSchemaInfo) error {
@@ -332,7 +341,7 @@ func (this *CodeGenerator) writeStructFieldType(schema Schema, buffer *bytes.Buf
 	case Recursive:
 		{
 			buffer.WriteString("*")
-			buffer.WriteString(schema.(*RecursiveSchema).GetName())
+			buffer.WriteString(exportedName(schema.(*RecursiveSchema).GetName()))
 		}
 	}
 
@@ -340,14 +349,7 @@ func (this *CodeGenerator) writeStructFieldType(schema Schema, buffer *bytes.Buf
 }
 
 func (this *CodeGenerator) writeStructUnionType(schema *UnionSchema, buffer *bytes.Buffer) error {
-	var unionType Schema
-	if schema.Types[0].Type() == Null {
-		unionType = schema.Types[1]
-	} else if schema.Types[1].Type() == Null {
-		unionType = schema.Types[0]
-	}
-
-	if unionType != nil && this.isNullable(unionType) {
+	if unionType, _ := this.nullableUnionType(schema); unionType != nil {
 		return this.writeStructFieldType(unionType, buffer)
 	}
 
@@ -355,6 +357,24 @@ func (this *CodeGenerator) writeStructUnionType(schema *UnionSchema, buffer *byt
 	return nil
 }
 
+// returns the non-null type and the null index of a union of null and a single nullable type or nil otherwise
+func (this *CodeGenerator) nullableUnionType(schema *UnionSchema) (Schema, int) {
+	if len(schema.Types) != 2 {
+		return nil, -1
+	}
+
+	for index, unionType := range schema.Types {
+		if unionType.Type() == Null {
+			other := schema.Types[1-index]
+			if other.Type() != Null && this.isNullable(other) {
+				return other, index
+			}
+		}
+	}
+
+	return nil, -1
+}
+
 func (this *CodeGenerator) isNullable(schema Schema) bool {
 	switch schema.(type) {
 	case *BooleanSchema, *IntSchema, *LongSchema, *FloatSchema, *DoubleSchema, *StringSchema:
@@ -517,3 +537,275 @@ func (this *CodeGenerator) writeSchemaGetter(info *recordSchemaInfo, buffer *byt
 	buffer.WriteString(fmt.Sprintf("if %s != nil {\n\t\tpanic(%s)\n\t}\n\t", info.schemaErrName, info.schemaErrName))
 	buffer.WriteString(fmt.Sprintf("return %s\n}", info.schemaVarName))
 }
+
+func (this *CodeGenerator) writeStructWriter(info *recordSchemaInfo, buffer *bytes.Buffer) error {
+	buffer.WriteString(fmt.Sprintf("func (this *%s) Write(enc avro.Encoder) error {\n", info.typeName))
+
+	vars := 0
+	for _, field := range info.schema.Fields {
+		err := this.writeEncodeValue(field.Type, "this."+exportedName(field.Name), &vars, buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	buffer.WriteString("return nil\n}")
+	return nil
+}
+
+func (this *CodeGenerator) writeStructReader(info *recordSchemaInfo, buffer *bytes.Buffer) error {
+	buffer.WriteString(fmt.Sprintf("func (this *%s) Read(dec avro.Decoder) error {\n", info.typeName))
+
+	if len(info.schema.Fields) > 0 {
+		buffer.WriteString("var err error\n")
+	}
+
+	vars := 0
+	for _, field := range info.schema.Fields {
+		err := this.writeDecodeValue(field.Type, "this."+exportedName(field.Name), &vars, buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	buffer.WriteString("return nil\n}")
+	return nil
+}
+
+// writes statements encoding a Go expression of given schema type with enc
+func (this *CodeGenerator) writeEncodeValue(schema Schema, value string, vars *int, buffer *bytes.Buffer) error {
+	switch schema.Type() {
+	case Null:
+		buffer.WriteString("enc.WriteNull(nil)\n")
+	case Boolean:
+		buffer.WriteString(fmt.Sprintf("enc.WriteBoolean(%s)\n", value))
+	case String:
+		buffer.WriteString(fmt.Sprintf("enc.WriteString(%s)\n", value))
+	case Int:
+		buffer.WriteString(fmt.Sprintf("enc.WriteInt(%s)\n", value))
+	case Long:
+		buffer.WriteString(fmt.Sprintf("enc.WriteLong(%s)\n", value))
+	case Float:
+		buffer.WriteString(fmt.Sprintf("enc.WriteFloat(%s)\n", value))
+	case Double:
+		buffer.WriteString(fmt.Sprintf("enc.WriteDouble(%s)\n", value))
+	case Bytes:
+		buffer.WriteString(fmt.Sprintf("enc.WriteBytes(%s)\n", value))
+	case Array:
+		{
+			item := nextVar("item", vars)
+			buffer.WriteString(fmt.Sprintf("if len(%s) > 0 {\nenc.WriteArrayStart(int64(len(%s)))\n", value, value))
+			buffer.WriteString(fmt.Sprintf("for _, %s := range %s {\n", item, value))
+			err := this.writeEncodeValue(schema.(*ArraySchema).Items, item, vars, buffer)
+			if err != nil {
+				return err
+			}
+			buffer.WriteString("}\n}\nenc.WriteArrayNext(0)\n")
+		}
+	case Map:
+		{
+			key := nextVar("key", vars)
+			mapValue := nextVar("value", vars)
+			buffer.WriteString(fmt.Sprintf("if len(%s) > 0 {\nenc.WriteMapStart(int64(len(%s)))\n", value, value))
+			buffer.WriteString(fmt.Sprintf("for %s, %s := range %s {\nenc.WriteString(%s)\n", key, mapValue, value, key))
+			err := this.writeEncodeValue(schema.(*MapSchema).Values, mapValue, vars, buffer)
+			if err != nil {
+				return err
+			}
+			buffer.WriteString("}\n}\nenc.WriteMapNext(0)\n")
+		}
+	case Enum:
+		buffer.WriteString(fmt.Sprintf("enc.WriteInt(%s.GetIndex())\n", value))
+	case Union:
+		return this.writeEncodeUnion(schema.(*UnionSchema), value, vars, buffer)
+	case Fixed:
+		{
+			buffer.WriteString(fmt.Sprintf("if len(%s) != %d {\nreturn avro.InvalidFixedSize\n}\n", value, schema.(*FixedSchema).Size))
+			buffer.WriteString(fmt.Sprintf("enc.WriteRaw(%s)\n", value))
+		}
+	case Record, Recursive:
+		buffer.WriteString(fmt.Sprintf("if err := %s.Write(enc); err != nil {\nreturn err\n}\n", value))
+	}
+
+	return nil
+}
+
+func (this *CodeGenerator) writeEncodeUnion(schema *UnionSchema, value string, vars *int, buffer *bytes.Buffer) error {
+	if unionType, nullIndex := this.nullableUnionType(schema); unionType != nil {
+		buffer.WriteString(fmt.Sprintf("if %s == nil {\nenc.WriteLong(%d)\n} else {\n", value, nullIndex))
+		buffer.WriteString(fmt.Sprintf("enc.WriteLong(%d)\n", 1-nullIndex))
+		err := this.writeEncodeValue(unionType, value, vars, buffer)
+		if err != nil {
+			return err
+		}
+		buffer.WriteString("}\n")
+		return nil
+	}
+
+	unionValue := nextVar("union", vars)
+	cases := &bytes.Buffer{}
+	goTypes := make(map[string]bool)
+	for index, unionType := range schema.Types {
+		goType := "nil"
+		if unionType.Type() != Null {
+			typeBuffer := &bytes.Buffer{}
+			err := this.writeStructFieldType(unionType, typeBuffer)
+			if err != nil {
+				return err
+			}
+			goType = typeBuffer.String()
+		}
+		// the first union type wins if several of them are represented by the same Go type
+		if goTypes[goType] {
+			continue
+		}
+		goTypes[goType] = true
+
+		cases.WriteString(fmt.Sprintf("case %s:\nenc.WriteLong(%d)\n", goType, index))
+		if unionType.Type() != Null {
+			err := this.writeEncodeValue(unionType, unionValue, vars, cases)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(goTypes) > 1 || !goTypes["nil"] {
+		buffer.WriteString(fmt.Sprintf("switch %s := %s.(type) {\n", unionValue, value))
+	} else {
+		buffer.WriteString(fmt.Sprintf("switch %s.(type) {\n", value))
+	}
+	buffer.Write(cases.Bytes())
+	buffer.WriteString("default:\nreturn avro.InvalidUnionValue\n}\n")
+	return nil
+}
+
+// writes statements decoding a value of given schema type from dec and assigning it to a Go expression
+func (this *CodeGenerator) writeDecodeValue(schema Schema, target string, vars *int, buffer *bytes.Buffer) error {
+	switch schema.Type() {
+	case Null:
+		buffer.WriteString(fmt.Sprintf("if %s, err = dec.ReadNull(); err != nil {\nreturn err\n}\n", target))
+	case Boolean:
+		this.writeDecodeCall(target, "ReadBoolean", buffer)
+	case String:
+		this.writeDecodeCall(target, "ReadString", buffer)
+	case Int:
+		this.writeDecodeCall(target, "ReadInt", buffer)
+	case Long:
+		this.writeDecodeCall(target, "ReadLong", buffer)
+	case Float:
+		this.writeDecodeCall(target, "ReadFloat", buffer)
+	case Double:
+		this.writeDecodeCall(target, "ReadDouble", buffer)
+	case Bytes:
+		this.writeDecodeCall(target, "ReadBytes", buffer)
+	case Array:
+		{
+			items := schema.(*ArraySchema).Items
+			itemType, err := this.goType(items)
+			if err != nil {
+				return err
+			}
+			count, array, index, item := nextVar("count", vars), nextVar("array", vars), nextVar("i", vars), nextVar("item", vars)
+			buffer.WriteString(fmt.Sprintf("var %s int64\nif %s, err = dec.ReadArrayStart(); err != nil {\nreturn err\n}\n", count, count))
+			buffer.WriteString(fmt.Sprintf("%s := make([]%s, 0)\nfor %s > 0 {\n", array, itemType, count))
+			buffer.WriteString(fmt.Sprintf("for %s := int64(0); %s < %s; %s++ {\nvar %s %s\n", index, index, count, index, item, itemType))
+			err = this.writeDecodeValue(items, item, vars, buffer)
+			if err != nil {
+				return err
+			}
+			buffer.WriteString(fmt.Sprintf("%s = append(%s, %s)\n}\n", array, array, item))
+			buffer.WriteString(fmt.Sprintf("if %s, err = dec.ArrayNext(); err != nil {\nreturn err\n}\n}\n", count))
+			buffer.WriteString(fmt.Sprintf("%s = %s\n", target, array))
+		}
+	case Map:
+		{
+			values := schema.(*MapSchema).Values
+			valueType, err := this.goType(values)
+			if err != nil {
+				return err
+			}
+			count, mapVar, index, key, value := nextVar("count", vars), nextVar("map", vars), nextVar("i", vars), nextVar("key", vars), nextVar("value", vars)
+			buffer.WriteString(fmt.Sprintf("var %s int64\nif %s, err = dec.ReadMapStart(); err != nil {\nreturn err\n}\n", count, count))
+			buffer.WriteString(fmt.Sprintf("%s := make(map[string]%s)\nfor %s > 0 {\n", mapVar, valueType, count))
+			buffer.WriteString(fmt.Sprintf("for %s := int64(0); %s < %s; %s++ {\n", index, index, count, index))
+			buffer.WriteString(fmt.Sprintf("var %s string\nif %s, err = dec.ReadString(); err != nil {\nreturn err\n}\n", key, key))
+			buffer.WriteString(fmt.Sprintf("var %s %s\n", value, valueType))
+			err = this.writeDecodeValue(values, value, vars, buffer)
+			if err != nil {
+				return err
+			}
+			buffer.WriteString(fmt.Sprintf("%s[%s] = %s\n}\n", mapVar, key, value))
+			buffer.WriteString(fmt.Sprintf("if %s, err = dec.MapNext(); err != nil {\nreturn err\n}\n}\n", count))
+			buffer.WriteString(fmt.Sprintf("%s = %s\n", target, mapVar))
+		}
+	case Enum:
+		{
+			symbols := make([]string, len(schema.(*EnumSchema).Symbols))
+			for i, symbol := range schema.(*EnumSchema).Symbols {
+				symbols[i] = fmt.Sprintf("%q", symbol)
+			}
+			index, enum := nextVar("index", vars), nextVar("enum", vars)
+			buffer.WriteString(fmt.Sprintf("var %s int32\nif %s, err = dec.ReadEnum(); err != nil {\nreturn err\n}\n", index, index))
+			buffer.WriteString(fmt.Sprintf("%s := avro.NewGenericEnum([]string{%s})\n", enum, strings.Join(symbols, ", ")))
+			buffer.WriteString(fmt.Sprintf("%s.SetIndex(%s)\n%s = %s\n", enum, index, target, enum))
+		}
+	case Union:
+		{
+			index := nextVar("index", vars)
+			buffer.WriteString(fmt.Sprintf("var %s int64\nif %s, err = dec.ReadLong(); err != nil {\nreturn err\n}\n", index, index))
+			buffer.WriteString(fmt.Sprintf("switch %s {\n", index))
+			for i, unionType := range schema.(*UnionSchema).Types {
+				buffer.WriteString(fmt.Sprintf("case %d:\n", i))
+				if unionType.Type() == Null {
+					buffer.WriteString(fmt.Sprintf("%s = nil\n", target))
+					continue
+				}
+				err := this.writeDecodeValue(unionType, target, vars, buffer)
+				if err != nil {
+					return err
+				}
+			}
+			buffer.WriteString("default:\nreturn avro.UnionIndexOutOfRange\n}\n")
+		}
+	case Fixed:
+		{
+			fixed := nextVar("fixed", vars)
+			buffer.WriteString(fmt.Sprintf("%s := make([]byte, %d)\n", fixed, schema.(*FixedSchema).Size))
+			buffer.WriteString(fmt.Sprintf("if err = dec.ReadFixed(%s); err != nil {\nreturn err\n}\n%s = %s\n", fixed, target, fixed))
+		}
+	case Record, Recursive:
+		{
+			record := nextVar("record", vars)
+			buffer.WriteString(fmt.Sprintf("%s := New%s()\n", record, exportedName(schema.GetName())))
+			buffer.WriteString(fmt.Sprintf("if err = %s.Read(dec); err != nil {\nreturn err\n}\n%s = %s\n", record, target, record))
+		}
+	}
+
+	return nil
+}
+
+func (this *CodeGenerator) writeDecodeCall(target string, method string, buffer *bytes.Buffer) {
+	buffer.WriteString(fmt.Sprintf("if %s, err = dec.%s(); err != nil {\nreturn err\n}\n", target, method))
+}
+
+func (this *CodeGenerator) goType(schema Schema) (string, error) {
+	buffer := &bytes.Buffer{}
+	err := this.writeStructFieldType(schema, buffer)
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// returns a new generated code variable name that is unique within a generated function
+func nextVar(prefix string, vars *int) string {
+	name := fmt.Sprintf("%s%d", prefix, *vars)
+	*vars++
+	return name
+}
+
+func exportedName(name string) string {
+	return fmt.Sprintf("%s%s", strings.ToUpper(name[:1]), name[1:])
+}
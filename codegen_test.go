@@ -0,0 +1,36 @@
+package avro
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "Update golden files in test/codegen")
+
+func TestCodeGeneratorGolden(t *testing.T) {
+	schema, err := ioutil.ReadFile("test/codegen/sample.avsc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := NewCodeGenerator([]string{string(schema)}).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := "test/codegen/sample.go.golden"
+	if *updateGolden {
+		if err := ioutil.WriteFile(golden, []byte(code), 0664); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != string(expected) {
+		t.Errorf("Generated code does not match %s:\n%s", golden, code)
+	}
+}
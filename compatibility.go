@@ -0,0 +1,119 @@
+package avro
+
+import "fmt"
+
+// Tells whether data written with a given writer schema can be read with a given reader schema following the
+// schema resolution rules of the spec (https://avro.apache.org/docs/current/spec.html#Schema+Resolution), the same
+// rules ResolvingDatumReader applies. Returns false with a list of reasons when the schemas are incompatible, e.g.
+// reader fields missing in the writer schema without a default, mismatching types or writer enum symbols the reader
+// cannot resolve. Backward compatibility of a new schema means Compatible(new, old), forward compatibility means
+// Compatible(old, new) and full compatibility means both.
+func Compatible(reader Schema, writer Schema) (bool, []string) {
+	if reader == nil || writer == nil {
+		return false, []string{SchemaNotSet.Error()}
+	}
+	checker := &compatibilityChecker{records: make(map[[2]Schema]bool)}
+	checker.check(writer, reader, reader.GetName())
+	return len(checker.reasons) == 0, checker.reasons
+}
+
+// Tells whether two schemas are equal for reading data, meaning they have the same Parsing Canonical Form.
+// Schemas that cannot be transformed into canonical form are never equal.
+func SchemaEqual(a Schema, b Schema) bool {
+	canonicalA, err := CanonicalForm(a)
+	if err != nil {
+		return false
+	}
+	canonicalB, err := CanonicalForm(b)
+	if err != nil {
+		return false
+	}
+	return canonicalA == canonicalB
+}
+
+type compatibilityChecker struct {
+	// record pairs that were already checked so that recursive schemas are checked only once
+	records map[[2]Schema]bool
+	reasons []string
+}
+
+func (this *compatibilityChecker) check(writer Schema, reader Schema, path string) {
+	writer, reader = actualSchema(writer), actualSchema(reader)
+
+	if writerUnion, ok := writer.(*UnionSchema); ok {
+		// every branch the writer may have written must be readable
+		for _, branch := range writerUnion.Types {
+			this.check(branch, reader, path)
+		}
+		return
+	}
+
+	if readerUnion, ok := reader.(*UnionSchema); ok {
+		for _, matches := range []func(Schema, Schema) bool{schemasMatch, isPromotable} {
+			for _, branch := range readerUnion.Types {
+				if matches(writer, actualSchema(branch)) {
+					this.check(writer, branch, path)
+					return
+				}
+			}
+		}
+		this.fail(path, "reader union has no branch for %s written by the writer", writer.GetName())
+		return
+	}
+
+	if isPromotable(writer, reader) {
+		return
+	}
+	if writerFixed, ok := writer.(*FixedSchema); ok && reader.Type() == Fixed && writerFixed.Size != reader.(*FixedSchema).Size {
+		this.fail(path, "fixed size %d does not match the writer size %d", reader.(*FixedSchema).Size, writerFixed.Size)
+		return
+	}
+	if !schemasMatch(writer, reader) {
+		this.fail(path, "type mismatch, cannot read %s written as %s", reader.GetName(), writer.GetName())
+		return
+	}
+
+	switch r := reader.(type) {
+	case *RecordSchema:
+		this.checkRecord(writer.(*RecordSchema), r, path)
+	case *ArraySchema:
+		this.check(writer.(*ArraySchema).Items, r.Items, path+"[]")
+	case *MapSchema:
+		this.check(writer.(*MapSchema).Values, r.Values, path+"{}")
+	case *EnumSchema:
+		for _, symbol := range writer.(*EnumSchema).Symbols {
+			if enumIndex(r, symbol, enumIndex(r, r.Default, -1)) < 0 {
+				this.fail(path, "enum symbol %s is missing in the reader schema which has no default", symbol)
+			}
+		}
+	}
+}
+
+func (this *compatibilityChecker) checkRecord(writer *RecordSchema, reader *RecordSchema, path string) {
+	key := [2]Schema{writer, reader}
+	if this.records[key] {
+		return
+	}
+	this.records[key] = true
+
+	matched := make(map[*SchemaField]bool)
+	for _, writerField := range writer.Fields {
+		if readerField := findReaderField(reader, writerField.Name); readerField != nil {
+			this.check(writerField.Type, readerField.Type, path+"."+readerField.Name)
+			matched[readerField] = true
+		}
+	}
+
+	for _, readerField := range reader.Fields {
+		if matched[readerField] {
+			continue
+		}
+		if _, err := fieldDefault(readerField); err != nil {
+			this.fail(path+"."+readerField.Name, "missing in the writer schema: %v", err)
+		}
+	}
+}
+
+func (this *compatibilityChecker) fail(path string, format string, args ...interface{}) {
+	this.reasons = append(this.reasons, path+": "+fmt.Sprintf(format, args...))
+}
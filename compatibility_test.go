@@ -0,0 +1,117 @@
+package avro
+
+import "testing"
+
+const compatibilityV1 = `{"type":"record","name":"User","fields":[
+	{"name":"name","type":"string"},
+	{"name":"age","type":"int"}
+]}`
+
+// adds a field with a default and widens age
+const compatibilityV2 = `{"type":"record","name":"User","fields":[
+	{"name":"name","type":"string"},
+	{"name":"age","type":"long"},
+	{"name":"email","type":["null","string"],"default":null}
+]}`
+
+// adds a field without a default
+const compatibilityV3 = `{"type":"record","name":"User","fields":[
+	{"name":"name","type":"string"},
+	{"name":"age","type":"int"},
+	{"name":"country","type":"string"}
+]}`
+
+// adds an optional field
+const compatibilityV4 = `{"type":"record","name":"User","fields":[
+	{"name":"name","type":"string"},
+	{"name":"age","type":"int"},
+	{"name":"nickname","type":"string","default":""}
+]}`
+
+func TestCompatibleBackward(t *testing.T) {
+	// a new reader schema reads data written with the old one
+	ok, reasons := Compatible(MustParseSchema(compatibilityV2), MustParseSchema(compatibilityV1))
+	assert(t, ok, true)
+	assert(t, len(reasons), 0)
+
+	ok, reasons = Compatible(MustParseSchema(compatibilityV3), MustParseSchema(compatibilityV1))
+	assert(t, ok, false)
+	assert(t, reasons, []string{"User.country: missing in the writer schema: No default value for field country"})
+}
+
+func TestCompatibleForward(t *testing.T) {
+	// an old reader schema reads data written with the new one
+	ok, _ := Compatible(MustParseSchema(compatibilityV1), MustParseSchema(compatibilityV3))
+	assert(t, ok, true)
+
+	ok, reasons := Compatible(MustParseSchema(compatibilityV1), MustParseSchema(compatibilityV2))
+	assert(t, ok, false)
+	assert(t, reasons, []string{"User.age: type mismatch, cannot read int written as long"})
+}
+
+func TestCompatibleFull(t *testing.T) {
+	v1, v4 := MustParseSchema(compatibilityV1), MustParseSchema(compatibilityV4)
+	backward, _ := Compatible(v4, v1)
+	forward, _ := Compatible(v1, v4)
+	assert(t, backward, true)
+	assert(t, forward, true)
+
+	v2 := MustParseSchema(compatibilityV2)
+	backward, _ = Compatible(v2, v1)
+	forward, _ = Compatible(v1, v2)
+	assert(t, backward, true)
+	assert(t, forward, false)
+}
+
+func TestCompatibleReasons(t *testing.T) {
+	reader := MustParseSchema(`{"type":"record","name":"Event","fields":[
+		{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}},
+		{"name":"tags","type":{"type":"array","items":"int"}},
+		{"name":"values","type":{"type":"map","values":["null","long"]}},
+		{"name":"hash","type":{"type":"fixed","name":"hash","size":4}},
+		{"name":"id","type":"string"}
+	]}`)
+	writer := MustParseSchema(`{"type":"record","name":"Event","fields":[
+		{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS","CLUBS"]}},
+		{"name":"tags","type":{"type":"array","items":"string"}},
+		{"name":"values","type":{"type":"map","values":["int","boolean"]}},
+		{"name":"hash","type":{"type":"fixed","name":"hash","size":8}},
+		{"name":"id","type":"bytes"}
+	]}`)
+
+	ok, reasons := Compatible(reader, writer)
+	assert(t, ok, false)
+	assert(t, reasons, []string{
+		"Event.suit: enum symbol CLUBS is missing in the reader schema which has no default",
+		"Event.tags[]: type mismatch, cannot read int written as string",
+		"Event.values{}: reader union has no branch for boolean written by the writer",
+		"Event.hash: fixed size 4 does not match the writer size 8",
+	})
+
+	// an enum default resolves unknown symbols
+	withDefault := MustParseSchema(`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"],"default":"SPADES"}`)
+	ok, _ = Compatible(withDefault, MustParseSchema(`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS","CLUBS"]}`))
+	assert(t, ok, true)
+
+	ok, reasons = Compatible(nil, withDefault)
+	assert(t, ok, false)
+	assert(t, reasons, []string{SchemaNotSet.Error()})
+}
+
+func TestCompatibleRecursive(t *testing.T) {
+	schema := `{"type":"record","name":"Node","fields":[
+		{"name":"value","type":"int"},
+		{"name":"next","type":["null","Node"]}
+	]}`
+	ok, reasons := Compatible(MustParseSchema(schema), MustParseSchema(schema))
+	assert(t, ok, true)
+	assert(t, len(reasons), 0)
+}
+
+func TestSchemaEqual(t *testing.T) {
+	a := MustParseSchema(`{"type":"record","name":"User","namespace":"example","doc":"a user","fields":[{"name":"name","type":"string"}]}`)
+	b := MustParseSchema(`{"name":"example.User","type":"record","fields":[{"name":"name","type":{"type":"string"},"default":"x"}]}`)
+	assert(t, SchemaEqual(a, b), true)
+	assert(t, SchemaEqual(a, MustParseSchema(compatibilityV1)), false)
+	assert(t, SchemaEqual(a, &unknownSchema{}), false)
+}
@@ -0,0 +1,35 @@
+package avro
+
+import "encoding/binary"
+
+// Magic byte every message in Confluent Schema Registry wire format starts with.
+const confluent_magic byte = 0x00
+
+const confluent_header_size = 5
+
+// Reads the header of a message in Confluent Schema Registry wire format, i.e. the magic byte followed by the
+// 4 byte big-endian schema registry ID of the writer schema, leaving this decoder positioned at the start of the
+// encoded value. Returns the schema ID, InvalidConfluentMagic if the message does not start with the magic byte or
+// an error if it occurs.
+func (this *BinaryDecoder) ReadConfluentHeader() (_ int32, err error) {
+	defer this.wrapError("ReadConfluentHeader", this.pos, &err)
+	if err := checkEOF(this.buf, this.pos, confluent_header_size); err != nil {
+		return 0, err
+	}
+	if this.buf[this.pos] != confluent_magic {
+		return 0, InvalidConfluentMagic
+	}
+
+	schemaID := int32(binary.BigEndian.Uint32(this.buf[this.pos+1:]))
+	this.pos += confluent_header_size
+	return schemaID, nil
+}
+
+// Writes the header of a message in Confluent Schema Registry wire format, i.e. the magic byte followed by
+// the 4 byte big-endian schema registry ID. The encoded value should be written right after.
+func (this *BinaryEncoder) WriteConfluentHeader(schemaID int32) {
+	header := make([]byte, confluent_header_size)
+	header[0] = confluent_magic
+	binary.BigEndian.PutUint32(header[1:], uint32(schemaID))
+	this.WriteRaw(header)
+}
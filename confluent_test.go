@@ -0,0 +1,33 @@
+package avro
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConfluentWireFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteConfluentHeader(258)
+	enc.WriteString("a")
+	assert(t, buf.Bytes(), []byte{0x00, 0x00, 0x00, 0x01, 0x02, 0x02, 0x61})
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	schemaID, err := dec.ReadConfluentHeader()
+	assert(t, err, nil)
+	assert(t, schemaID, int32(258))
+	value, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "a")
+}
+
+func TestConfluentInvalidHeader(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x01, 0x00, 0x00, 0x00, 0x01, 0x02, 0x61})
+	_, err := dec.ReadConfluentHeader()
+	assertError(t, err, InvalidConfluentMagic)
+	assert(t, dec.Tell(), int64(0))
+
+	dec = NewBinaryDecoder([]byte{0x00, 0x00, 0x01})
+	_, err = dec.ReadConfluentHeader()
+	assertError(t, err, EOF)
+}
@@ -2,23 +2,26 @@ package avro
 
 import (
 	"bytes"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
+	"strings"
 )
 
 const (
-	version    byte = 1
-	sync_size       = 16
-	schema_key      = "avro.schema"
-	codec_key       = "avro.codec"
+	version              byte = 1
+	sync_size                 = 16
+	schema_key                = "avro.schema"
+	codec_key                 = "avro.codec"
+	reserved_meta_prefix      = "avro."
+	default_block_size        = 64000
 )
 
 var magic []byte = []byte{'O', 'b', 'j', version}
 
-var syncBuffer = make([]byte, sync_size)
-
 // DataFileReader is a reader for Avro Object Container Files. More here: https://avro.apache.org/docs/current/spec.html#Object+Container+Files
 type DataFileReader struct {
 	data         []byte
@@ -27,6 +30,10 @@ type DataFileReader struct {
 	dec          Decoder
 	blockDecoder Decoder
 	datum        DatumReader
+	codec        Codec
+	syncBuffer   []byte
+	records      int64
+	progress     func(bytesRead int64, recordsDecoded int64)
 }
 
 type header struct {
@@ -59,38 +66,16 @@ func NewDataFileReader(filename string, datumReader DatumReader) (*DataFileReade
 			dec:          dec,
 			blockDecoder: blockDecoder,
 			datum:        datumReader,
+			syncBuffer:   make([]byte, sync_size),
 		}
 		reader.Seek(4) //skip the magic bytes
 
-		reader.header = newHeader()
-		if metaLength, err := dec.ReadMapStart(); err != nil {
+		if reader.header, err = readHeader(dec); err != nil {
+			return nil, err
+		}
+		if reader.codec, err = findCodec(string(reader.header.meta[codec_key])); err != nil {
 			return nil, err
-		} else {
-			for {
-				var i int64 = 0
-				for i < metaLength {
-					key, err := dec.ReadString()
-					if err != nil {
-						return nil, err
-					}
-
-					value, err := dec.ReadBytes()
-					if err != nil {
-						return nil, err
-					}
-					reader.header.meta[key] = value
-					i++
-				}
-				metaLength, err = dec.MapNext()
-				if err != nil {
-					return nil, err
-				} else if metaLength == 0 {
-					break
-				}
-			}
 		}
-		dec.ReadFixed(reader.header.sync)
-		//TODO codec?
 
 		schema, err := ParseSchema(string(reader.header.meta[schema_key]))
 		if err != nil {
@@ -109,12 +94,64 @@ func NewDataFileReader(filename string, datumReader DatumReader) (*DataFileReade
 	}
 }
 
+// reads the metadata and sync marker of a file header following the magic bytes
+func readHeader(dec Decoder) (*header, error) {
+	header := newHeader()
+	count, err := dec.ReadMapStart()
+	for ; count > 0 && err == nil; count, err = dec.MapNext() {
+		for i := int64(0); i < count; i++ {
+			key, err := dec.ReadString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := dec.ReadBytes()
+			if err != nil {
+				return nil, err
+			}
+			header.meta[key] = value
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.ReadFixed(header.sync); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// Returns the sync marker from the file header that every block of this DataFileReader must be followed by.
+func (this *DataFileReader) Sync() []byte {
+	sync := make([]byte, sync_size)
+	copy(sync, this.header.sync)
+	return sync
+}
+
+// Returns the metadata from the file header of this DataFileReader, including avro.schema, avro.codec and any custom
+// keys. Values are returned as raw bytes for the caller to interpret. The returned map is a copy.
+func (this *DataFileReader) Metadata() map[string][]byte {
+	meta := make(map[string][]byte, len(this.header.meta))
+	for key, value := range this.header.meta {
+		meta[key] = append([]byte(nil), value...)
+	}
+	return meta
+}
+
+// Sets a function this DataFileReader calls every time it finishes reading the values of a block, e.g. to report the
+// progress of reading a large file. The function is given the number of bytes of the file read so far and the number
+// of values decoded so far, which both only grow.
+func (this *DataFileReader) SetProgress(fn func(bytesRead int64, recordsDecoded int64)) {
+	this.progress = fn
+}
+
 // Switches the reading position in this DataFileReader to a provided value.
 func (this *DataFileReader) Seek(pos int64) {
 	this.dec.Seek(pos)
 }
 
-func (this *DataFileReader) hasNext() (bool, error) {
+// Tells whether there are more values to read from this DataFileReader, moving to the next block when the current
+// one is exhausted. Returns (false, nil) when no more data left to read and an error if the next block is malformed.
+func (this *DataFileReader) HasNext() (bool, error) {
 	if this.block.BlockRemaining == 0 {
 		if int64(this.block.BlockSize) != this.blockDecoder.Tell() {
 			return false, BlockNotFinished
@@ -139,7 +176,7 @@ func (this *DataFileReader) hasNextBlock() bool {
 // Second return value indicates whether there was an error while reading data.
 // Returns (false, nil) when no more data left to read.
 func (this *DataFileReader) Next(v interface{}) (bool, error) {
-	if hasNext, err := this.hasNext(); err != nil {
+	if hasNext, err := this.HasNext(); err != nil {
 		return false, err
 	} else {
 		if hasNext {
@@ -147,7 +184,7 @@ func (this *DataFileReader) Next(v interface{}) (bool, error) {
 			if err != nil {
 				return false, err
 			}
-			this.block.BlockRemaining--
+			this.valueRead()
 			return true, nil
 		} else {
 			return false, nil
@@ -156,6 +193,46 @@ func (this *DataFileReader) Next(v interface{}) (bool, error) {
 	return false, nil
 }
 
+// Reads all unread values of the current block, moving to the next block first if the current one is exhausted.
+// Exactly as many values are read as the block count says, each into a new pointer returned by newValue, so
+// trailing bytes after the last value of a block are ignored instead of being taken for another value.
+// Returns the read values, nil values if no more blocks left to read and an error if a block is malformed.
+func (this *DataFileReader) ReadBlock(newValue func() interface{}) ([]interface{}, error) {
+	if this.block.BlockRemaining == 0 {
+		if !this.hasNextBlock() {
+			return nil, nil
+		}
+		if err := this.NextBlock(); err != nil {
+			return nil, err
+		}
+	}
+
+	// a corrupted count must not preallocate more values than the data can hold
+	prealloc := this.block.BlockRemaining
+	if prealloc > max_prealloc_items {
+		prealloc = max_prealloc_items
+	}
+	values := make([]interface{}, 0, prealloc)
+	for this.block.BlockRemaining > 0 {
+		value := newValue()
+		if err := this.datum.Read(value, this.blockDecoder); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		this.valueRead()
+	}
+	return values, nil
+}
+
+// counts a value read from the current block and reports progress once the block is finished
+func (this *DataFileReader) valueRead() {
+	this.block.BlockRemaining--
+	this.records++
+	if this.block.BlockRemaining == 0 && this.progress != nil {
+		this.progress(this.dec.Tell(), this.records)
+	}
+}
+
 // Tells this DataFileReader to skip current block and move to next one.
 // May return an error if the block is malformed or no more blocks left to read.
 func (this *DataFileReader) NextBlock() error {
@@ -168,21 +245,180 @@ func (this *DataFileReader) NextBlock() error {
 			if blockSize > math.MaxInt32 || blockSize < 0 {
 				return errors.New(fmt.Sprintf("Block size invalid or too large: %d", blockSize))
 			}
+			if blockCount < 0 {
+				return errors.New(fmt.Sprintf("Block count invalid: %d", blockCount))
+			}
 
 			block := this.block
-			if block.Data == nil || int64(len(block.Data)) < blockSize {
+			if int64(cap(block.Data)) < blockSize {
 				block.Data = make([]byte, blockSize)
 			}
+			block.Data = block.Data[:blockSize]
 			block.BlockRemaining = blockCount
 			block.NumEntries = blockCount
 			block.BlockSize = int(blockSize)
-			this.dec.ReadFixedWithBounds(block.Data, 0, int(block.BlockSize))
-			this.dec.ReadFixed(syncBuffer)
-			if !bytes.Equal(syncBuffer, this.header.sync) {
-				return InvalidSync
+			if err := this.dec.ReadFixedWithBounds(block.Data, 0, int(block.BlockSize)); err != nil {
+				return err
+			}
+			if err := this.dec.ReadFixed(this.syncBuffer); err != nil {
+				return err
+			}
+			if !bytes.Equal(this.syncBuffer, this.header.sync) {
+				return SyncMismatch
 			}
+			if block.Data, err = this.codec.Decode(block.Data); err != nil {
+				return err
+			}
+			block.BlockSize = len(block.Data)
 			this.blockDecoder.SetBlock(this.block)
 		}
 	}
 	return nil
 }
+
+// DataFileWriter is a writer for Avro Object Container Files. It accumulates written values in memory and writes
+// them to the underlying io.Writer in blocks once the configured block size is reached or Flush is called.
+// More here: https://avro.apache.org/docs/current/spec.html#Object+Container+Files
+type DataFileWriter struct {
+	output        io.Writer
+	header        *header
+	headerWritten bool
+	datum         DatumWriter
+	codec         Codec
+	buffer        *bytes.Buffer
+	blockBuf      *bytes.Buffer
+	blockEnc      *BinaryEncoder
+	blockCount    int64
+	blockSize     int
+}
+
+// Creates a new DataFileWriter that writes values of a given schema to a given io.Writer using the given
+// DatumWriter. The file header with a random sync marker is written together with the first block.
+// May return an error if the sync marker cannot be generated.
+func NewDataFileWriter(output io.Writer, schema Schema, datumWriter DatumWriter) (*DataFileWriter, error) {
+	writer := &DataFileWriter{
+		output:    output,
+		header:    newHeader(),
+		datum:     datumWriter,
+		codec:     nullCodec{},
+		buffer:    &bytes.Buffer{},
+		blockBuf:  &bytes.Buffer{},
+		blockSize: default_block_size,
+	}
+	writer.blockEnc = NewBinaryEncoder(writer.blockBuf)
+	writer.datum.SetSchema(schema)
+
+	if _, err := rand.Read(writer.header.sync); err != nil {
+		return nil, err
+	}
+	writer.header.meta[schema_key] = []byte(schema.String())
+	writer.header.meta[codec_key] = []byte("null")
+
+	return writer, nil
+}
+
+// Sets the number of bytes after which the values accumulated in the current block are written out.
+// Defaults to 64000.
+func (this *DataFileWriter) SetBlockSize(size int) {
+	this.blockSize = size
+}
+
+// Sets the codec used to compress blocks, e.g. "null" or "deflate". Must be called before anything is written.
+// Returns UnsupportedCodec if there is no codec with a given name or CodecEncodeUnsupported if the codec can only
+// read data files, like bzip2, which is told by the codec returning CodecEncodeUnsupported when encoding no data.
+// Returns HeaderAlreadyWritten if the file header has been written by Flush, as the codec could not be changed anymore.
+func (this *DataFileWriter) SetCodec(name string) error {
+	if this.headerWritten {
+		return HeaderAlreadyWritten
+	}
+	codec, err := findCodec(name)
+	if err != nil {
+		return err
+	}
+	if _, err := codec.Encode(nil); errors.Is(err, CodecEncodeUnsupported) {
+		return CodecEncodeUnsupported
+	}
+	this.codec = codec
+	this.header.meta[codec_key] = []byte(name)
+	return nil
+}
+
+// Sets a custom metadata entry written to the file header of this DataFileWriter next to avro.schema and avro.codec.
+// Must be called before anything is written. Setting a key again replaces its value. Returns ReservedMetadataKey
+// for keys starting with "avro." as those are reserved for the spec, use SetCodec to set the codec instead.
+// Returns HeaderAlreadyWritten if the file header has been written by Flush, as the entry could not be stored anymore.
+func (this *DataFileWriter) SetMeta(key string, value []byte) error {
+	if strings.HasPrefix(key, reserved_meta_prefix) {
+		return ReservedMetadataKey
+	}
+	if this.headerWritten {
+		return HeaderAlreadyWritten
+	}
+	this.header.meta[key] = value
+	return nil
+}
+
+// Appends a value to the current block of this DataFileWriter, writing the block out if it has reached the block
+// size. May return an error indicating a write failure. A value that fails to be written is dropped from the block
+// so that the values appended before and after it are still written correctly.
+func (this *DataFileWriter) Append(datum interface{}) error {
+	mark := this.blockBuf.Len()
+	if err := this.datum.Write(datum, this.blockEnc); err != nil {
+		this.blockBuf.Truncate(mark)
+		return err
+	}
+	this.blockCount++
+	if this.blockBuf.Len() >= this.blockSize {
+		return this.Flush()
+	}
+	return nil
+}
+
+// Writes out the file header if it was not written yet and the values accumulated in the current block, if any,
+// followed by the sync marker. May return an error indicating a write failure.
+func (this *DataFileWriter) Flush() error {
+	enc := NewBinaryEncoder(this.buffer)
+	if !this.headerWritten {
+		this.writeHeader(enc)
+	}
+
+	if this.blockCount > 0 {
+		data, err := this.codec.Encode(this.blockBuf.Bytes())
+		if err != nil {
+			return err
+		}
+		enc.WriteLong(this.blockCount)
+		enc.WriteBytes(data)
+		enc.WriteRaw(this.header.sync)
+		this.blockBuf.Reset()
+		this.blockCount = 0
+	}
+
+	_, err := this.output.Write(this.buffer.Bytes())
+	this.buffer.Reset()
+	return err
+}
+
+// Flushes the current block and closes the underlying io.Writer if it is an io.Closer.
+// May return an error indicating a write failure.
+func (this *DataFileWriter) Close() error {
+	if err := this.Flush(); err != nil {
+		return err
+	}
+	if closer, ok := this.output.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (this *DataFileWriter) writeHeader(enc *BinaryEncoder) {
+	enc.WriteRaw(magic)
+	enc.WriteMapStart(int64(len(this.header.meta)))
+	for key, value := range this.header.meta {
+		enc.WriteString(key)
+		enc.WriteBytes(value)
+	}
+	enc.WriteMapNext(0)
+	enc.WriteRaw(this.header.sync)
+	this.headerWritten = true
+}
@@ -0,0 +1,188 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// DataFileBlockReader reads the blocks of an Avro Object Container File from an io.ReaderAt in any order. The offsets
+// of all blocks are recorded when it is created, so that blocks can be read later by their offset, e.g. to process
+// them in parallel. Reading blocks is safe for concurrent use as long as the DatumReader is.
+type DataFileBlockReader struct {
+	reader   io.ReaderAt
+	size     int64
+	header   *header
+	codec    Codec
+	datum    DatumReader
+	decoders *DecoderPool
+	offsets  []int64
+}
+
+// Creates a new DataFileBlockReader for a data file of a given size in bytes readable from a given io.ReaderAt and
+// using the given DatumReader to read values from blocks. Reads the file header and the count and size of every
+// block to record the block offsets, skipping the block data itself.
+// May return an error if the file contains invalid data or ends in the middle of a block.
+func NewDataFileBlockReader(r io.ReaderAt, size int64, datumReader DatumReader) (*DataFileBlockReader, error) {
+	fileMagic := make([]byte, len(magic))
+	if _, err := r.ReadAt(fileMagic, 0); err != nil || !bytes.Equal(magic, fileMagic) {
+		return nil, NotAvroFile
+	}
+
+	dec := NewStreamBinaryDecoder(io.NewSectionReader(r, int64(len(magic)), size-int64(len(magic))))
+	header, err := readHeader(dec)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := findCodec(string(header.meta[codec_key]))
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ParseSchema(string(header.meta[schema_key]))
+	if err != nil {
+		return nil, err
+	}
+	datumReader.SetSchema(schema)
+
+	reader := &DataFileBlockReader{
+		reader:   r,
+		size:     size,
+		header:   header,
+		codec:    codec,
+		datum:    datumReader,
+		decoders: NewDecoderPool(),
+	}
+	for offset := int64(len(magic)) + dec.Tell(); offset < size; {
+		_, dataOffset, blockSize, err := reader.readBlockHeader(offset)
+		if err != nil {
+			return nil, err
+		}
+		reader.offsets = append(reader.offsets, offset)
+		if offset = dataOffset + blockSize + sync_size; offset > size {
+			return nil, UnexpectedEOF
+		}
+	}
+
+	return reader, nil
+}
+
+// Returns the offsets of all blocks of this DataFileBlockReader in the order they appear in the file.
+func (this *DataFileBlockReader) BlockOffsets() []int64 {
+	offsets := make([]int64, len(this.offsets))
+	copy(offsets, this.offsets)
+	return offsets
+}
+
+// Returns the metadata from the file header of this DataFileBlockReader. The returned map is a copy.
+func (this *DataFileBlockReader) Metadata() map[string][]byte {
+	meta := make(map[string][]byte, len(this.header.meta))
+	for key, value := range this.header.meta {
+		meta[key] = append([]byte(nil), value...)
+	}
+	return meta
+}
+
+// Reads all values of the block starting at a given offset, which should be one of BlockOffsets, each into a new
+// pointer returned by newValue. Returns the read values and an error if the block is malformed, e.g. SyncMismatch
+// when the offset is not the start of a block.
+func (this *DataFileBlockReader) ReadBlockAt(offset int64, newValue func() interface{}) ([]interface{}, error) {
+	count, dataOffset, blockSize, err := this.readBlockHeader(offset)
+	if err != nil {
+		return nil, err
+	}
+	if dataOffset+blockSize+sync_size > this.size {
+		return nil, UnexpectedEOF
+	}
+
+	buf := make([]byte, blockSize+sync_size)
+	if _, err := this.reader.ReadAt(buf, dataOffset); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(buf[blockSize:], this.header.sync) {
+		return nil, SyncMismatch
+	}
+	data, err := this.codec.Decode(buf[:blockSize])
+	if err != nil {
+		return nil, err
+	}
+
+	dec := this.decoders.Get(data)
+	defer this.decoders.Put(dec)
+	// a corrupted count must not preallocate more values than the data can hold
+	prealloc := count
+	if prealloc > max_prealloc_items {
+		prealloc = max_prealloc_items
+	}
+	values := make([]interface{}, 0, prealloc)
+	for i := int64(0); i < count; i++ {
+		value := newValue()
+		if err := this.datum.Read(value, dec); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// reads the value count and data size of the block starting at a given offset and returns them with the offset of
+// the block data
+func (this *DataFileBlockReader) readBlockHeader(offset int64) (count int64, dataOffset int64, blockSize int64, err error) {
+	if offset < 0 || offset >= this.size {
+		return 0, 0, 0, InvalidSeek
+	}
+	dec := NewStreamBinaryDecoder(io.NewSectionReader(this.reader, offset, this.size-offset))
+	if count, err = dec.ReadLong(); err != nil {
+		return
+	}
+	if blockSize, err = dec.ReadLong(); err != nil {
+		return
+	}
+	if count < 0 {
+		return 0, 0, 0, errors.New(fmt.Sprintf("Block count invalid: %d", count))
+	}
+	if blockSize > math.MaxInt32 || blockSize < 0 {
+		return 0, 0, 0, errors.New(fmt.Sprintf("Block size invalid or too large: %d", blockSize))
+	}
+	return count, offset + dec.Tell(), blockSize, nil
+}
+
+// Walks the blocks of an Avro Object Container File body read from a given io.Reader positioned right after the file
+// header, e.g. to split a file into blocks without decoding any values. Calls a given function with the raw, still
+// compressed data of each block and its number of entries, in file order. Returning an error from the function stops
+// scanning.
+// Returns nil at the end of the body, SyncMismatch if a block is not followed by the given sync marker,
+// UnexpectedEOF if the body ends in the middle of a block or the error of the function.
+func ScanBlocks(r io.Reader, sync [16]byte, fn func(blockBytes []byte, count int64) error) error {
+	dec := NewStreamBinaryDecoder(r)
+	blockSync := make([]byte, sync_size)
+	for {
+		count, err := dec.ReadLong()
+		if err == EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := dec.ReadBytes()
+		if err == EOF {
+			return UnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if err := dec.ReadFixed(blockSync); err != nil {
+			if err == EOF {
+				return UnexpectedEOF
+			}
+			return err
+		}
+		if !bytes.Equal(blockSync, sync[:]) {
+			return SyncMismatch
+		}
+		if err := fn(data, count); err != nil {
+			return err
+		}
+	}
+}
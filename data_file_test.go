@@ -0,0 +1,739 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+const dataFileTestSchema = `{"type":"record","name":"Entry","fields":[{"name":"value","type":"long"}]}`
+
+var dataFileTestSync = []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+
+// encodes a data file with the given codec and one block per given slice of values
+func encodeDataFile(codec string, blocks ...[]int64) []byte {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	encodeDataFileHeader(enc, codec)
+	for _, values := range blocks {
+		blockBuf := &bytes.Buffer{}
+		blockEnc := NewBinaryEncoder(blockBuf)
+		for _, value := range values {
+			blockEnc.WriteLong(value)
+		}
+		encodeDataFileBlock(enc, int64(len(values)), blockBuf.Bytes())
+	}
+	return buf.Bytes()
+}
+
+func encodeDataFileHeader(enc *BinaryEncoder, codec string) {
+	enc.WriteRaw(magic)
+	enc.WriteMapStart(2)
+	enc.WriteString(schema_key)
+	enc.WriteBytes([]byte(dataFileTestSchema))
+	enc.WriteString(codec_key)
+	enc.WriteBytes([]byte(codec))
+	enc.WriteMapNext(0)
+	enc.WriteRaw(dataFileTestSync)
+}
+
+func encodeDataFileBlock(enc *BinaryEncoder, count int64, data []byte) {
+	enc.WriteLong(count)
+	enc.WriteBytes(data)
+	enc.WriteRaw(dataFileTestSync)
+}
+
+// reads all values of a data file written with dataFileTestSchema
+func readDataFileValues(t *testing.T, filename string) []interface{} {
+	reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := MustParseSchema(dataFileTestSchema)
+	var values []interface{}
+	for {
+		record := NewGenericRecord(schema)
+		ok, err := reader.Next(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return values
+		}
+		values = append(values, record.Get("value"))
+	}
+}
+
+func writeTempDataFile(t *testing.T, data []byte) string {
+	file, err := ioutil.TempFile("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func TestDataFileReaderBlocks(t *testing.T) {
+	filename := writeTempDataFile(t, encodeDataFile("null", []int64{1, 2}, []int64{3}, []int64{4, 5, 6}))
+	defer os.Remove(filename)
+
+	reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := MustParseSchema(dataFileTestSchema)
+	var values []interface{}
+	for {
+		hasNext, err := reader.HasNext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hasNext {
+			break
+		}
+		record := NewGenericRecord(schema)
+		ok, err := reader.Next(record)
+		assert(t, err, nil)
+		assert(t, ok, true)
+		values = append(values, record.Get("value"))
+	}
+	assert(t, values, []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5), int64(6)})
+
+	ok, err := reader.Next(NewGenericRecord(schema))
+	assert(t, err, nil)
+	assert(t, ok, false)
+}
+
+func TestDataFileReaderReadBlock(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	encodeDataFileHeader(enc, "null")
+	// a block with two values followed by padding
+	encodeDataFileBlock(enc, 2, []byte{0x02, 0x04, 0x00, 0x00})
+	encodeDataFileBlock(enc, 0, []byte{})
+	encodeDataFileBlock(enc, 1, []byte{0x06})
+	filename := writeTempDataFile(t, buf.Bytes())
+	defer os.Remove(filename)
+
+	reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := MustParseSchema(dataFileTestSchema)
+	newRecord := func() interface{} {
+		return NewGenericRecord(schema)
+	}
+	var blocks [][]interface{}
+	for {
+		values, err := reader.ReadBlock(newRecord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values == nil {
+			break
+		}
+		var block []interface{}
+		for _, value := range values {
+			block = append(block, value.(*GenericRecord).Get("value"))
+		}
+		blocks = append(blocks, block)
+	}
+	assert(t, blocks, [][]interface{}{{int64(1), int64(2)}, nil, {int64(3)}})
+
+	// reading value by value relies on the block being fully consumed
+	reader, err = NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		ok, err := reader.Next(NewGenericRecord(schema))
+		assert(t, err, nil)
+		assert(t, ok, true)
+	}
+	_, err = reader.Next(NewGenericRecord(schema))
+	assertError(t, err, BlockNotFinished)
+}
+
+func TestDataFileReaderInvalidSync(t *testing.T) {
+	data := encodeDataFile("null", []int64{1}, []int64{2})
+	data[len(data)-1] ^= 0xFF
+	filename := writeTempDataFile(t, data)
+	defer os.Remove(filename)
+
+	reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := MustParseSchema(dataFileTestSchema)
+	ok, err := reader.Next(NewGenericRecord(schema))
+	assert(t, err, nil)
+	assert(t, ok, true)
+	_, err = reader.HasNext()
+	assertError(t, err, SyncMismatch)
+	assertError(t, err, InvalidSync)
+}
+
+func TestDataFileReaderSyncMismatch(t *testing.T) {
+	data := encodeDataFile("null", []int64{1, 2}, []int64{3}, []int64{4})
+	// corrupt the sync marker following the second block
+	secondSync := len(data) - 2*sync_size - 3
+	assert(t, data[secondSync:secondSync+sync_size], dataFileTestSync)
+	data[secondSync+sync_size/2] ^= 0xFF
+	filename := writeTempDataFile(t, data)
+	defer os.Remove(filename)
+
+	reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, reader.Sync(), dataFileTestSync)
+	schema := MustParseSchema(dataFileTestSchema)
+	for i := 0; i < 2; i++ {
+		ok, err := reader.Next(NewGenericRecord(schema))
+		assert(t, err, nil)
+		assert(t, ok, true)
+	}
+	_, err = reader.Next(NewGenericRecord(schema))
+	assertError(t, err, SyncMismatch)
+
+	// the returned marker is a copy that does not affect validation
+	reader.Sync()[0] ^= 0xFF
+	assert(t, reader.Sync(), dataFileTestSync)
+}
+
+func TestDataFileReaderProgress(t *testing.T) {
+	data := encodeDataFile("null", []int64{1, 2}, []int64{3}, []int64{4, 5, 6})
+	filename := writeTempDataFile(t, data)
+	defer os.Remove(filename)
+
+	for _, readBlocks := range []bool{false, true} {
+		reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var bytesRead, records []int64
+		reader.SetProgress(func(read int64, decoded int64) {
+			bytesRead = append(bytesRead, read)
+			records = append(records, decoded)
+		})
+
+		schema := MustParseSchema(dataFileTestSchema)
+		if readBlocks {
+			for values := []interface{}{nil}; len(values) > 0; {
+				values, err = reader.ReadBlock(func() interface{} { return NewGenericRecord(schema) })
+				assert(t, err, nil)
+			}
+		} else {
+			for ok := true; ok; {
+				ok, err = reader.Next(NewGenericRecord(schema))
+				assert(t, err, nil)
+			}
+		}
+
+		assert(t, records, []int64{2, 3, 6})
+		assert(t, len(bytesRead), 3)
+		for i := 1; i < len(bytesRead); i++ {
+			if bytesRead[i] <= bytesRead[i-1] {
+				t.Fatalf("Expected increasing bytes read, actual %v", bytesRead)
+			}
+		}
+		assert(t, bytesRead[2], int64(len(data)))
+	}
+}
+
+func TestDataFileReaderMetadata(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteRaw(magic)
+	enc.WriteMapStart(3)
+	enc.WriteString(schema_key)
+	enc.WriteBytes([]byte(dataFileTestSchema))
+	enc.WriteString(codec_key)
+	enc.WriteBytes([]byte("null"))
+	enc.WriteString("writer.version")
+	enc.WriteBytes([]byte{0x01, 0x02})
+	enc.WriteMapNext(0)
+	enc.WriteRaw(dataFileTestSync)
+	encodeDataFileBlock(enc, 1, []byte{0x02})
+	filename := writeTempDataFile(t, buf.Bytes())
+	defer os.Remove(filename)
+
+	reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := reader.Metadata()
+	assert(t, len(meta), 3)
+	assert(t, meta["writer.version"], []byte{0x01, 0x02})
+	assert(t, meta[codec_key], []byte("null"))
+	assert(t, meta[schema_key], []byte(dataFileTestSchema))
+
+	// the returned map is a copy that does not affect the reader
+	meta["writer.version"][0] = 0xFF
+	delete(meta, codec_key)
+	assert(t, reader.Metadata()["writer.version"], []byte{0x01, 0x02})
+	assert(t, len(reader.Metadata()), 3)
+}
+
+func TestScanBlocks(t *testing.T) {
+	data := encodeDataFile("null", []int64{1, 2}, []int64{3}, []int64{4, 5, 6})
+	var sync [16]byte
+	copy(sync[:], dataFileTestSync)
+
+	// returns a reader of the body of the data file following its header
+	body := func(data []byte) *bytes.Reader {
+		r := bytes.NewReader(data[len(magic):])
+		if _, err := readHeader(NewStreamBinaryDecoder(r)); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	var blocks [][]byte
+	var counts []int64
+	err := ScanBlocks(body(data), sync, func(blockBytes []byte, count int64) error {
+		blocks = append(blocks, blockBytes)
+		counts = append(counts, count)
+		return nil
+	})
+	assert(t, err, nil)
+	assert(t, counts, []int64{2, 1, 3})
+	assert(t, blocks, [][]byte{{0x02, 0x04}, {0x06}, {0x08, 0x0A, 0x0C}})
+
+	stop := errors.New("stop")
+	scanned := 0
+	err = ScanBlocks(body(data), sync, func(blockBytes []byte, count int64) error {
+		scanned++
+		return stop
+	})
+	assert(t, err, stop)
+	assert(t, scanned, 1)
+
+	noop := func(blockBytes []byte, count int64) error { return nil }
+	assert(t, ScanBlocks(body(data[:len(data)-1]), sync, noop), UnexpectedEOF)
+	assert(t, ScanBlocks(body(data[:len(data)-sync_size-2]), sync, noop), UnexpectedEOF)
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-1] ^= 0xFF
+	assert(t, ScanBlocks(body(corrupted), sync, noop), SyncMismatch)
+}
+
+func TestDataFileBlockReader(t *testing.T) {
+	buf := &bytes.Buffer{}
+	schema := MustParseSchema(dataFileTestSchema)
+	writer, err := NewDataFileWriter(buf, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.SetCodec("deflate"), nil)
+	writer.SetBlockSize(4)
+	for i := int64(0); i < 20; i++ {
+		record := NewGenericRecord(schema)
+		record.Set("value", i)
+		assert(t, writer.Append(record), nil)
+	}
+	assert(t, writer.Close(), nil)
+
+	data := buf.Bytes()
+	reader, err := NewDataFileBlockReader(bytes.NewReader(data), int64(len(data)), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, reader.Metadata()[codec_key], []byte("deflate"))
+	offsets := reader.BlockOffsets()
+	if len(offsets) < 2 {
+		t.Fatalf("Expected several blocks, actual %d", len(offsets))
+	}
+
+	newRecord := func() interface{} {
+		return NewGenericRecord(schema)
+	}
+	blocks := make([][]interface{}, len(offsets))
+	for i := len(offsets) - 1; i >= 0; i-- {
+		values, err := reader.ReadBlockAt(offsets[i], newRecord)
+		assert(t, err, nil)
+		for _, value := range values {
+			blocks[i] = append(blocks[i], value.(*GenericRecord).Get("value"))
+		}
+	}
+	var values []interface{}
+	for _, block := range blocks {
+		values = append(values, block...)
+	}
+	var expected []interface{}
+	for i := int64(0); i < 20; i++ {
+		expected = append(expected, i)
+	}
+	assert(t, values, expected)
+
+	_, err = reader.ReadBlockAt(offsets[1]+1, newRecord)
+	assert(t, err != nil, true)
+	_, err = reader.ReadBlockAt(int64(len(data)), newRecord)
+	assert(t, err, InvalidSeek)
+
+	_, err = NewDataFileBlockReader(bytes.NewReader(data), int64(len(data)-1), NewGenericDatumReader())
+	assertError(t, err, UnexpectedEOF)
+	_, err = NewDataFileBlockReader(bytes.NewReader([]byte("Obj")), 3, NewGenericDatumReader())
+	assert(t, err, NotAvroFile)
+}
+
+func TestDataFileBlockReaderHostileCount(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	encodeDataFileHeader(enc, "null")
+	blockBuf := &bytes.Buffer{}
+	NewBinaryEncoder(blockBuf).WriteLong(1)
+	encodeDataFileBlock(enc, 1<<61, blockBuf.Bytes())
+	data := buf.Bytes()
+
+	reader, err := NewDataFileBlockReader(bytes.NewReader(data), int64(len(data)), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := MustParseSchema(dataFileTestSchema)
+	_, err = reader.ReadBlockAt(reader.BlockOffsets()[0], func() interface{} {
+		return NewGenericRecord(schema)
+	})
+	assertError(t, err, EOF)
+}
+
+func TestDataFileReaderTruncated(t *testing.T) {
+	data := encodeDataFile("null", []int64{1, 2, 3})
+	filename := writeTempDataFile(t, data[:len(data)-sync_size-1])
+	defer os.Remove(filename)
+
+	_, err := NewDataFileReader(filename, NewGenericDatumReader())
+	assertError(t, err, EOF)
+}
+
+func TestDataFileReaderCorruptCount(t *testing.T) {
+	schema := MustParseSchema(dataFileTestSchema)
+	newRecord := func() interface{} {
+		return NewGenericRecord(schema)
+	}
+	// returns a data file with a single block of a given count holding one value
+	encodeWithCount := func(count int64) []byte {
+		buf := &bytes.Buffer{}
+		enc := NewBinaryEncoder(buf)
+		encodeDataFileHeader(enc, "null")
+		blockBuf := &bytes.Buffer{}
+		NewBinaryEncoder(blockBuf).WriteLong(1)
+		encodeDataFileBlock(enc, count, blockBuf.Bytes())
+		return buf.Bytes()
+	}
+
+	filename := writeTempDataFile(t, encodeWithCount(-1))
+	defer os.Remove(filename)
+	reader, err := NewDataFileReader(filename, NewGenericDatumReader())
+	if err == nil {
+		_, err = reader.ReadBlock(newRecord)
+	}
+	if err == nil {
+		t.Fatal("Expected an error for a negative block count")
+	}
+
+	filename = writeTempDataFile(t, encodeWithCount(1<<40))
+	defer os.Remove(filename)
+	reader, err = NewDataFileReader(filename, NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = reader.ReadBlock(newRecord)
+	assertError(t, err, EOF)
+}
+
+func TestDataFileReaderUnsupportedCodec(t *testing.T) {
+	filename := writeTempDataFile(t, encodeDataFile("lzma", []int64{1}))
+	defer os.Remove(filename)
+
+	_, err := NewDataFileReader(filename, NewGenericDatumReader())
+	assertError(t, err, UnsupportedCodec)
+	assertError(t, err, UnknownCodec)
+}
+
+func TestDataFileReaderNoCodec(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteRaw(magic)
+	enc.WriteMapStart(1)
+	enc.WriteString(schema_key)
+	enc.WriteBytes([]byte(dataFileTestSchema))
+	enc.WriteMapNext(0)
+	enc.WriteRaw(dataFileTestSync)
+	encodeDataFileBlock(enc, 2, []byte{0x02, 0x04})
+	filename := writeTempDataFile(t, buf.Bytes())
+	defer os.Remove(filename)
+
+	assert(t, readDataFileValues(t, filename), []interface{}{int64(1), int64(2)})
+	data := buf.Bytes()
+	reader, err := NewDataFileBlockReader(bytes.NewReader(data), int64(len(data)), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, hasCodec := reader.Metadata()[codec_key]
+	assert(t, hasCodec, false)
+
+	// the writer always names the codec, null unless set otherwise
+	buf = &bytes.Buffer{}
+	writer, err := NewDataFileWriter(buf, MustParseSchema(dataFileTestSchema), NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.Close(), nil)
+	data = buf.Bytes()
+	reader, err = NewDataFileBlockReader(bytes.NewReader(data), int64(len(data)), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, reader.Metadata()[codec_key], []byte("null"))
+}
+
+func TestDataFileWriterRoundTrip(t *testing.T) {
+	file, err := ioutil.TempFile("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	schema := MustParseSchema(dataFileTestSchema)
+	writer, err := NewDataFileWriter(file, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.SetBlockSize(4)
+	for i := int64(0); i < 100; i++ {
+		record := NewGenericRecord(schema)
+		record.Set("value", i*i)
+		assert(t, writer.Append(record), nil)
+	}
+	assert(t, writer.Close(), nil)
+
+	reader, err := NewDataFileReader(file.Name(), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, reader.header.meta[codec_key], []byte("null"))
+	for i := int64(0); i < 100; i++ {
+		record := NewGenericRecord(schema)
+		ok, err := reader.Next(record)
+		assert(t, err, nil)
+		assert(t, ok, true)
+		assert(t, record.Get("value"), i*i)
+		if reader.block.NumEntries == 100 {
+			t.Fatal("Expected values to be split into several blocks")
+		}
+	}
+	ok, err := reader.Next(NewGenericRecord(schema))
+	assert(t, err, nil)
+	assert(t, ok, false)
+}
+
+func TestDataFileWriterLogicalTypes(t *testing.T) {
+	file, err := ioutil.TempFile("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	schema := MustParseSchema(`{"type": "record", "name": "Logical", "fields": [
+		{"name": "day", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "price", "type": {"type": "fixed", "name": "Price", "size": 2, "logicalType": "decimal", "precision": 4, "scale": 1}}
+	]}`)
+	writer, err := NewDataFileWriter(file, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := NewGenericRecord(schema)
+	record.Set("day", time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC))
+	record.Set("price", big.NewRat(-20, 1))
+	assert(t, writer.Append(record), nil)
+	assert(t, writer.Close(), nil)
+
+	reader, err := NewDataFileReader(file.Name(), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, err := ParseSchema(string(reader.Metadata()[schema_key]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	price := written.(*RecordSchema).Fields[1].Type
+	precision, _ := price.Prop("precision")
+	assert(t, precision, "4")
+	scale, _ := price.Prop("scale")
+	assert(t, scale, "1")
+
+	decoded := NewGenericRecord(written)
+	ok, err := reader.Next(decoded)
+	assert(t, err, nil)
+	assert(t, ok, true)
+	assert(t, decoded.Get("day"), time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert(t, decoded.Get("price").(*big.Rat).RatString(), "-20")
+}
+
+func TestDataFileWriterEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewDataFileWriter(buf, MustParseSchema(dataFileTestSchema), NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.Flush(), nil)
+	headerSize := buf.Len()
+	assert(t, writer.Close(), nil)
+	assert(t, buf.Len(), headerSize)
+	assert(t, buf.Bytes()[:4], magic)
+}
+
+func TestDataFileWriterAppendInvalid(t *testing.T) {
+	file, err := ioutil.TempFile("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	schema := MustParseSchema(`{"type": "record", "name": "Card", "fields": [
+		{"name": "value", "type": "long"},
+		{"name": "suit", "type": {"type": "enum", "name": "Suit", "symbols": ["SPADES", "HEARTS"]}}
+	]}`)
+	writer, err := NewDataFileWriter(file, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the value field is written before the invalid symbol is found, it must not stay in the block
+	invalid := NewGenericRecord(schema)
+	invalid.Set("value", int64(1))
+	invalid.Set("suit", "CLUBS")
+	if writer.Append(invalid) == nil {
+		t.Fatal("Expected an error appending an invalid enum symbol")
+	}
+	valid := NewGenericRecord(schema)
+	valid.Set("value", int64(2))
+	valid.Set("suit", "HEARTS")
+	assert(t, writer.Append(valid), nil)
+	assert(t, writer.Close(), nil)
+
+	reader, err := NewDataFileReader(file.Name(), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := NewGenericRecord(schema)
+	ok, err := reader.Next(decoded)
+	assert(t, err, nil)
+	assert(t, ok, true)
+	assert(t, decoded.Get("value"), int64(2))
+	assert(t, decoded.Get("suit"), "HEARTS")
+	ok, err = reader.Next(NewGenericRecord(schema))
+	assert(t, err, nil)
+	assert(t, ok, false)
+}
+
+func TestDataFileWriterMetadata(t *testing.T) {
+	file, err := ioutil.TempFile("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	schema := MustParseSchema(dataFileTestSchema)
+	writer, err := NewDataFileWriter(file, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.SetMeta("writer.version", []byte("1.0")), nil)
+	assert(t, writer.SetMeta("empty", []byte{}), nil)
+	assert(t, writer.SetMeta(codec_key, []byte("deflate")), ReservedMetadataKey)
+	assert(t, writer.SetMeta("avro.custom", []byte("value")), ReservedMetadataKey)
+	record := NewGenericRecord(schema)
+	record.Set("value", int64(5))
+	assert(t, writer.Append(record), nil)
+	// the header is written with the first block, entries set later are rejected
+	assert(t, writer.SetMeta("before.flush", []byte("kept")), nil)
+	assert(t, writer.Flush(), nil)
+	assert(t, writer.SetMeta("after.flush", []byte("dropped")), HeaderAlreadyWritten)
+	assert(t, writer.Close(), nil)
+
+	reader, err := NewDataFileReader(file.Name(), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := reader.Metadata()
+	assert(t, len(meta), 5)
+	assert(t, meta["writer.version"], []byte("1.0"))
+	assert(t, meta["before.flush"], []byte("kept"))
+	empty, ok := meta["empty"]
+	assert(t, ok, true)
+	assert(t, len(empty), 0)
+	assert(t, meta[codec_key], []byte("null"))
+	assert(t, readDataFileValues(t, file.Name()), []interface{}{int64(5)})
+}
+
+func TestDataFileReaderDeflate(t *testing.T) {
+	// values 1, 2 and 3 compressed with raw deflate
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	encodeDataFileHeader(enc, "deflate")
+	encodeDataFileBlock(enc, 3, []byte{0x63, 0x62, 0x61, 0x03, 0x00})
+	filename := writeTempDataFile(t, buf.Bytes())
+	defer os.Remove(filename)
+
+	assert(t, readDataFileValues(t, filename), []interface{}{int64(1), int64(2), int64(3)})
+}
+
+func TestDataFileWriterDeflate(t *testing.T) {
+	file, err := ioutil.TempFile("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	schema := MustParseSchema(dataFileTestSchema)
+	writer, err := NewDataFileWriter(file, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, writer.SetCodec("lzma"), UnsupportedCodec)
+	assert(t, writer.SetCodec("deflate"), nil)
+	writer.SetBlockSize(100)
+	var expected []interface{}
+	for i := int64(0); i < 1000; i++ {
+		record := NewGenericRecord(schema)
+		record.Set("value", i%10)
+		assert(t, writer.Append(record), nil)
+		expected = append(expected, i%10)
+	}
+	// the blocks written so far are compressed with deflate, the codec can't be changed anymore
+	assert(t, writer.SetCodec("null"), HeaderAlreadyWritten)
+	assert(t, writer.Close(), nil)
+
+	assert(t, readDataFileValues(t, file.Name()), expected)
+}
+
+func TestDataFileReaderSnappy(t *testing.T) {
+	// values 1, 2 and 3 compressed with snappy followed by their CRC32
+	block := []byte{0x03, 0x08, 0x02, 0x04, 0x06, 0x71, 0xCA, 0x6D, 0x4D}
+	for _, corrupted := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+		enc := NewBinaryEncoder(buf)
+		encodeDataFileHeader(enc, "snappy")
+		encodeDataFileBlock(enc, 3, block)
+		data := buf.Bytes()
+		if corrupted {
+			data[len(data)-sync_size-1] ^= 0xFF
+		}
+		filename := writeTempDataFile(t, data)
+		defer os.Remove(filename)
+
+		if corrupted {
+			_, err := NewDataFileReader(filename, NewGenericDatumReader())
+			assertError(t, err, SnappyCRCMismatch)
+		} else {
+			assert(t, readDataFileValues(t, filename), []interface{}{int64(1), int64(2), int64(3)})
+		}
+	}
+}
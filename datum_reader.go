@@ -70,15 +70,20 @@ func (this *GenericEnum) Set(symbol string) {
 	}
 }
 
+// default limit for how deeply records, arrays and maps may be nested in values read by datum readers and skipped
+// or validated by BinaryDecoder
+const max_depth = 256
+
 // SpecificDatumReader implements DatumReader and is used for filling Go structs with data.
 // Each value passed to Read is expected to be a pointer.
 type SpecificDatumReader struct {
-	schema Schema
+	schema   Schema
+	maxDepth int
 }
 
 // Creates a new SpecificDatumReader.
 func NewSpecificDatumReader() *SpecificDatumReader {
-	return &SpecificDatumReader{}
+	return &SpecificDatumReader{maxDepth: max_depth}
 }
 
 // Sets the schema for this SpecificDatumReader to know the data structure.
@@ -87,6 +92,12 @@ func (this *SpecificDatumReader) SetSchema(schema Schema) {
 	this.schema = schema
 }
 
+// Sets how deeply records, arrays and maps may be nested in values this SpecificDatumReader reads, like
+// GenericDatumReader.SetMaxDepth does. Reading a value nested deeper returns MaxDepthExceeded. Defaults to 256.
+func (this *SpecificDatumReader) SetMaxDepth(depth int) {
+	this.maxDepth = depth
+}
+
 // Reads a single structured entry using this SpecificDatumReader.
 // Accepts a Go struct with exported fields to fill with data and a Decoder to read from. Given value MUST be of
 // pointer type. Field names should match field names in Avro schema but be exported (e.g. "some_value" in Avro
@@ -110,19 +121,21 @@ func (this *SpecificDatumReader) Read(v interface{}, dec Decoder) error {
 	sch := this.schema.(*RecordSchema)
 	for i := 0; i < len(sch.Fields); i++ {
 		field := sch.Fields[i]
-		this.findAndSet(v, field, dec)
+		if err := this.findAndSet(v, field, dec, 1); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (this *SpecificDatumReader) findAndSet(v interface{}, field *SchemaField, dec Decoder) error {
+func (this *SpecificDatumReader) findAndSet(v interface{}, field *SchemaField, dec Decoder, depth int) error {
 	structField, err := findField(reflect.ValueOf(v), field.Name)
 	if err != nil {
 		return err
 	}
 
-	value, err := this.readValue(field.Type, structField, dec)
+	value, err := this.readValue(field.Type, structField, dec, depth)
 	if err != nil {
 		return err
 	}
@@ -132,7 +145,15 @@ func (this *SpecificDatumReader) findAndSet(v interface{}, field *SchemaField, d
 	return nil
 }
 
-func (this *SpecificDatumReader) readValue(field Schema, reflectField reflect.Value, dec Decoder) (reflect.Value, error) {
+// reads a value nested in a given number of records, arrays and maps
+func (this *SpecificDatumReader) readValue(field Schema, reflectField reflect.Value, dec Decoder, depth int) (reflect.Value, error) {
+	switch field.Type() {
+	case Array, Map, Record, Recursive:
+		if depth >= this.maxDepth {
+			return reflect.ValueOf(nil), MaxDepthExceeded
+		}
+	}
+
 	switch field.Type() {
 	case Null:
 		return reflect.ValueOf(nil), nil
@@ -151,19 +172,19 @@ func (this *SpecificDatumReader) readValue(field Schema, reflectField reflect.Va
 	case String:
 		return this.mapPrimitive(func() (interface{}, error) { return dec.ReadString() })
 	case Array:
-		return this.mapArray(field, reflectField, dec)
+		return this.mapArray(field, reflectField, dec, depth+1)
 	case Enum:
 		return this.mapEnum(field, dec)
 	case Map:
-		return this.mapMap(field, reflectField, dec)
+		return this.mapMap(field, reflectField, dec, depth+1)
 	case Union:
-		return this.mapUnion(field, reflectField, dec)
+		return this.mapUnion(field, reflectField, dec, depth)
 	case Fixed:
 		return this.mapFixed(field, dec)
 	case Record:
-		return this.mapRecord(field, reflectField, dec)
+		return this.mapRecord(field, reflectField, dec, depth+1)
 	case Recursive:
-		return this.mapRecord(field.(*RecursiveSchema).Actual, reflectField, dec)
+		return this.mapRecord(field.(*RecursiveSchema).Actual, reflectField, dec, depth+1)
 	}
 
 	return reflect.ValueOf(nil), fmt.Errorf("Unknown field type: %s", field.Type())
@@ -172,6 +193,10 @@ func (this *SpecificDatumReader) readValue(field Schema, reflectField reflect.Va
 func (this *SpecificDatumReader) setValue(field *SchemaField, where reflect.Value, what reflect.Value) {
 	zero := reflect.Value{}
 	if zero != what {
+		// records are read as pointers but may be held by struct fields directly
+		if what.Kind() == reflect.Ptr && where.Kind() != reflect.Ptr && what.Type().Elem() == where.Type() {
+			what = what.Elem()
+		}
 		where.Set(what)
 	}
 }
@@ -184,81 +209,62 @@ func (this *SpecificDatumReader) mapPrimitive(reader func() (interface{}, error)
 	}
 }
 
-func (this *SpecificDatumReader) mapArray(field Schema, reflectField reflect.Value, dec Decoder) (reflect.Value, error) {
-	if arrayLength, err := dec.ReadArrayStart(); err != nil {
-		return reflect.ValueOf(arrayLength), err
-	} else {
-		array := reflect.MakeSlice(reflectField.Type(), 0, 0)
-		for {
-			arrayPart := reflect.MakeSlice(reflectField.Type(), int(arrayLength), int(arrayLength))
-			var i int64 = 0
-			for ; i < arrayLength; i++ {
-				val, err := this.readValue(field.(*ArraySchema).Items, arrayPart.Index(int(i)), dec)
-				if err != nil {
-					return reflect.ValueOf(arrayLength), err
-				}
-
-				pointer := reflectField.Type().Elem().Kind() == reflect.Ptr
-
-				if pointer && val.Kind() != reflect.Ptr {
-					val = val.Addr()
-				} else if !pointer && val.Kind() == reflect.Ptr {
-					val = val.Elem()
-				}
-				arrayPart.Index(int(i)).Set(val)
-			}
-			//concatenate arrays
-			concatArray := reflect.MakeSlice(reflectField.Type(), array.Len()+int(arrayLength), array.Cap()+int(arrayLength))
-			reflect.Copy(concatArray, array)
-			reflect.Copy(concatArray, arrayPart)
-			array = concatArray
-			arrayLength, err = dec.ArrayNext()
+func (this *SpecificDatumReader) mapArray(field Schema, reflectField reflect.Value, dec Decoder, depth int) (reflect.Value, error) {
+	array := reflect.MakeSlice(reflectField.Type(), 0, 0)
+	pointer := reflectField.Type().Elem().Kind() == reflect.Ptr
+	count, err := dec.ReadArrayStart()
+	for ; count > 0 && err == nil; count, err = dec.ArrayNext() {
+		arrayPart := reflect.MakeSlice(reflectField.Type(), int(count), int(count))
+		for i := 0; i < int(count); i++ {
+			val, err := this.readValue(field.(*ArraySchema).Items, arrayPart.Index(i), dec, depth)
 			if err != nil {
-				return reflect.ValueOf(arrayLength), err
-			} else if arrayLength == 0 {
-				break
+				return reflect.ValueOf(count), err
 			}
+
+			if pointer && val.Kind() != reflect.Ptr {
+				val = val.Addr()
+			} else if !pointer && val.Kind() == reflect.Ptr {
+				val = val.Elem()
+			}
+			arrayPart.Index(i).Set(val)
 		}
-		return array, nil
+		array = reflect.AppendSlice(array, arrayPart)
+	}
+	if err != nil {
+		return reflect.ValueOf(count), err
 	}
+	return array, nil
 }
 
-func (this *SpecificDatumReader) mapMap(field Schema, reflectField reflect.Value, dec Decoder) (reflect.Value, error) {
-	if mapLength, err := dec.ReadMapStart(); err != nil {
-		return reflect.ValueOf(mapLength), err
-	} else {
-		resultMap := reflect.MakeMap(reflectField.Type())
-		for {
-			var i int64 = 0
-			for ; i < mapLength; i++ {
-				key, err := this.readValue(&StringSchema{}, reflectField, dec)
-				if err != nil {
-					return reflect.ValueOf(mapLength), err
-				}
-				val, err := this.readValue(field.(*MapSchema).Values, reflectField, dec)
-				if err != nil {
-					return reflect.ValueOf(mapLength), nil
-				}
-				if val.Kind() == reflect.Ptr {
-					resultMap.SetMapIndex(key, val.Elem())
-				} else {
-					resultMap.SetMapIndex(key, val)
-				}
+func (this *SpecificDatumReader) mapMap(field Schema, reflectField reflect.Value, dec Decoder, depth int) (reflect.Value, error) {
+	resultMap := reflect.MakeMap(reflectField.Type())
+	index := int64(0)
+	count, err := dec.ReadMapStart()
+	for ; count > 0 && err == nil; count, err = dec.MapNext() {
+		for i := int64(0); i < count; i, index = i+1, index+1 {
+			key, err := this.readValue(&StringSchema{}, reflectField, dec, depth)
+			if err != nil {
+				return reflect.ValueOf(count), &MapKeyDecodeError{Index: index, Err: err}
 			}
-
-			mapLength, err = dec.MapNext()
+			val, err := this.readValue(field.(*MapSchema).Values, reflectField, dec, depth)
 			if err != nil {
-				return reflect.ValueOf(mapLength), err
-			} else if mapLength == 0 {
-				break
+				return reflect.ValueOf(count), err
+			}
+			if val.Kind() == reflect.Ptr {
+				resultMap.SetMapIndex(key, val.Elem())
+			} else {
+				resultMap.SetMapIndex(key, val)
 			}
 		}
-		return resultMap, nil
 	}
+	if err != nil {
+		return reflect.ValueOf(count), err
+	}
+	return resultMap, nil
 }
 
 func (this *SpecificDatumReader) mapEnum(field Schema, dec Decoder) (reflect.Value, error) {
-	if enumIndex, err := dec.ReadEnum(); err != nil {
+	if enumIndex, err := readEnumIndex(field.(*EnumSchema).Symbols, dec); err != nil {
 		return reflect.ValueOf(enumIndex), err
 	} else {
 		enum := NewGenericEnum(field.(*EnumSchema).Symbols)
@@ -267,24 +273,41 @@ func (this *SpecificDatumReader) mapEnum(field Schema, dec Decoder) (reflect.Val
 	}
 }
 
-func (this *SpecificDatumReader) mapUnion(field Schema, reflectField reflect.Value, dec Decoder) (reflect.Value, error) {
+func (this *SpecificDatumReader) mapUnion(field Schema, reflectField reflect.Value, dec Decoder, depth int) (reflect.Value, error) {
 	if unionType, err := dec.ReadInt(); err != nil {
 		return reflect.ValueOf(unionType), err
 	} else {
-		union := field.(*UnionSchema).Types[unionType]
-		return this.readValue(union, reflectField, dec)
+		types := field.(*UnionSchema).Types
+		if unionType < 0 || int(unionType) >= len(types) {
+			return reflect.ValueOf(unionType), UnionIndexOutOfRange
+		}
+		if types[unionType].Type() == Null {
+			switch reflectField.Kind() {
+			case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+				return reflect.Zero(reflectField.Type()), nil
+			}
+		}
+
+		value, err := this.readValue(types[unionType], reflectField, dec, depth)
+		if err != nil {
+			return value, err
+		}
+		// nullable unions may be mapped to pointer fields, e.g. ["null", "string"] to *string
+		if reflectField.Kind() == reflect.Ptr && value.IsValid() && value.Kind() != reflect.Ptr {
+			pointer := reflect.New(value.Type())
+			pointer.Elem().Set(value)
+			return pointer, nil
+		}
+		return value, nil
 	}
 }
 
 func (this *SpecificDatumReader) mapFixed(field Schema, dec Decoder) (reflect.Value, error) {
-	fixed := make([]byte, field.(*FixedSchema).Size)
-	if err := dec.ReadFixed(fixed); err != nil {
-		return reflect.ValueOf(fixed), err
-	}
-	return reflect.ValueOf(fixed), nil
+	fixed, err := readFixed(field.(*FixedSchema), dec)
+	return reflect.ValueOf(fixed), err
 }
 
-func (this *SpecificDatumReader) mapRecord(field Schema, reflectField reflect.Value, dec Decoder) (reflect.Value, error) {
+func (this *SpecificDatumReader) mapRecord(field Schema, reflectField reflect.Value, dec Decoder, depth int) (reflect.Value, error) {
 	var t reflect.Type
 	switch reflectField.Kind() {
 	case reflect.Ptr, reflect.Array, reflect.Map, reflect.Slice, reflect.Chan:
@@ -296,7 +319,9 @@ func (this *SpecificDatumReader) mapRecord(field Schema, reflectField reflect.Va
 
 	recordSchema := field.(*RecordSchema)
 	for i := 0; i < len(recordSchema.Fields); i++ {
-		this.findAndSet(record, recordSchema.Fields[i], dec)
+		if err := this.findAndSet(record, recordSchema.Fields[i], dec, depth); err != nil {
+			return reflect.ValueOf(record), err
+		}
 	}
 
 	return reflect.ValueOf(record), nil
@@ -307,12 +332,17 @@ func (this *SpecificDatumReader) mapRecord(field Schema, reflectField reflect.Va
 // and any values, GenericEnums) with data.
 // Each value passed to Read is expected to be a pointer.
 type GenericDatumReader struct {
-	schema Schema
+	schema          Schema
+	rawFields       map[string]bool
+	strictMaps      bool
+	rawLogicalTypes bool
+	hooks           map[string]func(dec Decoder) (interface{}, error)
+	maxDepth        int
 }
 
 // Creates a new GenericDatumReader.
 func NewGenericDatumReader() *GenericDatumReader {
-	return &GenericDatumReader{}
+	return &GenericDatumReader{maxDepth: max_depth}
 }
 
 // Sets the schema for this GenericDatumReader to know the data structure.
@@ -321,6 +351,46 @@ func (this *GenericDatumReader) SetSchema(schema Schema) {
 	this.schema = schema
 }
 
+// Sets whether this GenericDatumReader rejects maps containing a key more than once with DuplicateMapKey instead of
+// keeping the last value of that key. Defaults to false.
+func (this *GenericDatumReader) SetStrictMaps(strict bool) {
+	this.strictMaps = strict
+}
+
+// Sets whether this GenericDatumReader reads values of schemas annotated with a logical type as native Go types:
+// decimal as *big.Rat, date, timestamp-millis and timestamp-micros as time.Time, time-millis and time-micros as
+// time.Duration, uuid as [16]byte and duration as Duration. When disabled values are read as their underlying
+// primitive type instead. Defaults to true.
+func (this *GenericDatumReader) SetLogicalTypes(enabled bool) {
+	this.rawLogicalTypes = !enabled
+}
+
+// Sets how deeply records, arrays and maps may be nested in values this GenericDatumReader reads, so that data
+// nested deeply over a recursive schema, e.g. a very long linked list, cannot exhaust the stack. Reading a value
+// nested deeper returns MaxDepthExceeded. Defaults to 256.
+func (this *GenericDatumReader) SetMaxDepth(depth int) {
+	this.maxDepth = depth
+}
+
+// Registers a function this GenericDatumReader calls to read values of a named record, enum or fixed type instead of
+// reading them itself, e.g. to map a fixed type to a custom Go type. The type is given by its name or full name and
+// the function must read exactly the encoded value from the given Decoder. Registering a type again replaces its hook.
+func (this *GenericDatumReader) RegisterHook(typeName string, fn func(dec Decoder) (interface{}, error)) {
+	if this.hooks == nil {
+		this.hooks = make(map[string]func(dec Decoder) (interface{}, error))
+	}
+	this.hooks[typeName] = fn
+}
+
+// Sets the names of record fields this GenericDatumReader reads as RawDatum values without decoding them, so that
+// they can be written again untouched. Applies to fields of nested records as well and requires a BinaryDecoder.
+func (this *GenericDatumReader) SetRawFields(names ...string) {
+	this.rawFields = make(map[string]bool)
+	for _, name := range names {
+		this.rawFields[name] = true
+	}
+}
+
 // Reads a single entry using this GenericDatumReader.
 // Accepts a value to fill with data and a Decoder to read from. Given value MUST be of  pointer type.
 // May return an error indicating a read failure.
@@ -345,6 +415,10 @@ func (this *GenericDatumReader) Read(v interface{}, dec Decoder) error {
 	if newValue.Kind() == reflect.Ptr {
 		newValue = newValue.Elem()
 	}
+	// null values are read as the zero value
+	if !newValue.IsValid() {
+		newValue = reflect.Zero(rv.Type())
+	}
 
 	//set the new value
 	rv.Set(newValue)
@@ -352,8 +426,21 @@ func (this *GenericDatumReader) Read(v interface{}, dec Decoder) error {
 	return nil
 }
 
-func (this *GenericDatumReader) findAndSet(record *GenericRecord, field *SchemaField, dec Decoder) error {
-	value, err := this.readValue(field.Type, dec)
+func (this *GenericDatumReader) findAndSet(record *GenericRecord, field *SchemaField, dec Decoder, depth int) error {
+	if this.rawFields[field.Name] {
+		binaryDecoder, ok := dec.(*BinaryDecoder)
+		if !ok {
+			return RawDecoderRequired
+		}
+		raw, err := binaryDecoder.ReadRaw(field.Type)
+		if err != nil {
+			return err
+		}
+		record.Set(field.Name, raw)
+		return nil
+	}
+
+	value, err := this.readValueAt(field.Type, dec, depth)
 	if err != nil {
 		return err
 	}
@@ -373,6 +460,42 @@ func (this *GenericDatumReader) findAndSet(record *GenericRecord, field *SchemaF
 }
 
 func (this *GenericDatumReader) readValue(field Schema, dec Decoder) (interface{}, error) {
+	return this.readValueAt(field, dec, 0)
+}
+
+// reads a value nested in a given number of records, arrays and maps
+func (this *GenericDatumReader) readValueAt(field Schema, dec Decoder, depth int) (interface{}, error) {
+	if hook := this.findHook(field); hook != nil {
+		return hook(dec)
+	}
+
+	value, err := this.readBaseValue(field, dec, depth)
+	if err != nil || this.rawLogicalTypes {
+		return value, err
+	}
+	return logicalValue(field, value), nil
+}
+
+// returns the hook registered for the name or full name of a given named schema or nil if there is none
+func (this *GenericDatumReader) findHook(field Schema) func(dec Decoder) (interface{}, error) {
+	fullName := schemaFullName(field)
+	if this.hooks == nil || fullName == "" {
+		return nil
+	}
+	if hook, ok := this.hooks[fullName]; ok {
+		return hook
+	}
+	return this.hooks[field.GetName()]
+}
+
+func (this *GenericDatumReader) readBaseValue(field Schema, dec Decoder, depth int) (interface{}, error) {
+	switch field.Type() {
+	case Array, Map, Record, Recursive:
+		if depth >= this.maxDepth {
+			return nil, MaxDepthExceeded
+		}
+	}
+
 	switch field.Type() {
 	case Null:
 		return nil, nil
@@ -391,118 +514,213 @@ func (this *GenericDatumReader) readValue(field Schema, dec Decoder) (interface{
 	case String:
 		return dec.ReadString()
 	case Array:
-		return this.mapArray(field, dec)
+		return this.readArray(field.(*ArraySchema).Items, dec, depth+1)
 	case Enum:
 		return this.mapEnum(field, dec)
 	case Map:
-		return this.mapMap(field, dec)
+		return this.readMap(field.(*MapSchema).Values, dec, depth+1)
 	case Union:
-		return this.mapUnion(field, dec)
+		return this.mapUnion(field, dec, depth)
 	case Fixed:
 		return this.mapFixed(field, dec)
 	case Record:
-		return this.mapRecord(field, dec)
+		return this.mapRecord(field, dec, depth+1)
 	case Recursive:
-		return this.mapRecord(field.(*RecursiveSchema).Actual, dec)
+		return this.mapRecord(field.(*RecursiveSchema).Actual, dec, depth+1)
 	}
 
-	return nil, fmt.Errorf("Unknown field type: %s", field.Type())
+	return nil, fmt.Errorf("Unknown field type: %d", field.Type())
 }
 
-func (this *GenericDatumReader) mapArray(field Schema, dec Decoder) ([]interface{}, error) {
-	if arrayLength, err := dec.ReadArrayStart(); err != nil {
-		return nil, err
-	} else {
-		array := make([]interface{}, 0)
-		for {
-			arrayPart := make([]interface{}, arrayLength, arrayLength)
-			var i int64 = 0
-			for ; i < arrayLength; i++ {
-				val, err := this.readValue(field.(*ArraySchema).Items, dec)
-				if err != nil {
-					return nil, err
-				}
-				arrayPart[i] = val
-			}
-			//concatenate arrays
-			concatArray := make([]interface{}, len(array)+int(arrayLength), cap(array)+int(arrayLength))
-			copy(concatArray, array)
-			copy(concatArray, arrayPart)
-			array = concatArray
-			arrayLength, err = dec.ArrayNext()
+// Skips a value of a given schema using this GenericDatumReader. Values of any schema are skipped by dispatching to
+// the Skip methods of BinaryDecoder, recursing into records, arrays and maps and skipping only the written branch of
+// unions, so that nothing is decoded. Decoders other than BinaryDecoder cannot skip, so the value is read and
+// discarded instead. Returns an error if it occurs.
+func (this *GenericDatumReader) Skip(schema Schema, dec Decoder) error {
+	if binaryDecoder, ok := dec.(*BinaryDecoder); ok {
+		return binaryDecoder.skipValue(schema)
+	}
+	_, err := this.readBaseValue(schema, dec, 0)
+	return err
+}
+
+// Reads an array with items of a given schema using this GenericDatumReader. Reads array blocks until the
+// terminating block with no items, so an empty array is a single zero count. Blocks with a negative count followed
+// by their size in bytes are read the same way as others. Returns a decoded array and an error if it occurs.
+func (this *GenericDatumReader) ReadArray(itemSchema Schema, dec Decoder) ([]interface{}, error) {
+	return this.readArray(itemSchema, dec, 1)
+}
+
+// reads an array with items nested in a given number of records, arrays and maps including the array itself
+func (this *GenericDatumReader) readArray(itemSchema Schema, dec Decoder, depth int) ([]interface{}, error) {
+	array := make([]interface{}, 0)
+	count, err := dec.ReadArrayStart()
+	for ; count > 0 && err == nil; count, err = dec.ArrayNext() {
+		// a corrupted count must not preallocate more items than the data can hold
+		prealloc := count
+		if prealloc > max_prealloc_items {
+			prealloc = max_prealloc_items
+		}
+		if needed := len(array) + int(prealloc); needed > cap(array) {
+			grown := make([]interface{}, len(array), needed)
+			copy(grown, array)
+			array = grown
+		}
+		for i := int64(0); i < count; i++ {
+			value, err := this.readValueAt(itemSchema, dec, depth)
 			if err != nil {
 				return nil, err
-			} else if arrayLength == 0 {
-				break
 			}
+			array = append(array, value)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return array, nil
+}
+
+// Reads an array with items of a given schema using this GenericDatumReader like ReadArray but passes each item to a
+// given function as soon as it is read instead of collecting them. Returning an error from the function stops reading
+// right after the item passed to it. Returns the error of the function or an error if reading fails.
+func (this *GenericDatumReader) ForEachArrayItem(itemSchema Schema, dec Decoder, fn func(index int64, v interface{}) error) error {
+	var index int64
+	count, err := dec.ReadArrayStart()
+	for ; count > 0 && err == nil; count, err = dec.ArrayNext() {
+		for i := int64(0); i < count; i++ {
+			value, err := this.readValueAt(itemSchema, dec, 1)
+			if err != nil {
+				return err
+			}
+			if err := fn(index, value); err != nil {
+				return err
+			}
+			index++
 		}
-		return array, nil
 	}
+	return err
 }
 
 func (this *GenericDatumReader) mapEnum(field Schema, dec Decoder) (*GenericEnum, error) {
-	if enumIndex, err := dec.ReadEnum(); err != nil {
+	enumSchema := field.(*EnumSchema)
+	if enumIndex, err := readEnumIndex(enumSchema.Symbols, dec); err != nil {
 		return nil, err
 	} else {
-		enum := NewGenericEnum(field.(*EnumSchema).Symbols)
+		enum := NewGenericEnum(enumSchema.Symbols)
 		enum.SetIndex(enumIndex)
 		return enum, nil
 	}
 }
 
-func (this *GenericDatumReader) mapMap(field Schema, dec Decoder) (map[string]interface{}, error) {
-	if mapLength, err := dec.ReadMapStart(); err != nil {
+// Reads an enum value of a given schema using this GenericDatumReader. Returns the decoded symbol,
+// EnumIndexOutOfRange if the decoded index does not refer to any of the enum symbols or an error if it occurs.
+func (this *GenericDatumReader) ReadEnumSymbol(enum *EnumSchema, dec Decoder) (string, error) {
+	index, err := readEnumIndex(enum.Symbols, dec)
+	if err != nil {
+		return "", err
+	}
+	return enum.Symbols[index], nil
+}
+
+// reads an enum index and checks that it refers to one of given enum symbols
+func readEnumIndex(symbols []string, dec Decoder) (int32, error) {
+	index, err := dec.ReadEnum()
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || int(index) >= len(symbols) {
+		return 0, EnumIndexOutOfRange
+	}
+	return index, nil
+}
+
+// Reads a map with values of a given schema using this GenericDatumReader. Reads map blocks until the terminating
+// block with no items, so an empty map is a single zero count. If a key occurs more than once the last value wins
+// unless strict maps are enabled with SetStrictMaps. Returns a decoded map and an error if it occurs.
+func (this *GenericDatumReader) ReadMap(valueSchema Schema, dec Decoder) (map[string]interface{}, error) {
+	return this.readMap(valueSchema, dec, 1)
+}
+
+// reads a map with values nested in a given number of records, arrays and maps including the map itself
+func (this *GenericDatumReader) readMap(valueSchema Schema, dec Decoder, depth int) (map[string]interface{}, error) {
+	resultMap := make(map[string]interface{})
+	err := this.forEachMapEntry(valueSchema, dec, depth, func(key string, value interface{}) error {
+		if _, exists := resultMap[key]; exists && this.strictMaps {
+			return DuplicateMapKey
+		}
+		resultMap[key] = value
+		return nil
+	})
+	if err != nil {
 		return nil, err
-	} else {
-		resultMap := make(map[string]interface{})
-		for {
-			var i int64 = 0
-			for ; i < mapLength; i++ {
-				key, err := this.readValue(&StringSchema{}, dec)
-				if err != nil {
-					return nil, err
-				}
-				val, err := this.readValue(field.(*MapSchema).Values, dec)
-				if err != nil {
-					return nil, nil
-				}
-				resultMap[key.(string)] = val
-			}
+	}
+	return resultMap, nil
+}
+
+// Reads a map with values of a given schema using this GenericDatumReader like ReadMap but passes each entry to a
+// given function as soon as it is read instead of collecting them. Returning an error from the function stops reading
+// right after the entry passed to it. Returns the error of the function or an error if reading fails.
+func (this *GenericDatumReader) ForEachMapEntry(valueSchema Schema, dec Decoder, fn func(key string, v interface{}) error) error {
+	return this.forEachMapEntry(valueSchema, dec, 1, fn)
+}
 
-			mapLength, err = dec.MapNext()
+func (this *GenericDatumReader) forEachMapEntry(valueSchema Schema, dec Decoder, depth int, fn func(key string, v interface{}) error) error {
+	index := int64(0)
+	count, err := dec.ReadMapStart()
+	for ; count > 0 && err == nil; count, err = dec.MapNext() {
+		for i := int64(0); i < count; i, index = i+1, index+1 {
+			key, err := dec.ReadString()
 			if err != nil {
-				return nil, err
-			} else if mapLength == 0 {
-				break
+				return &MapKeyDecodeError{Index: index, Err: err}
+			}
+			value, err := this.readValueAt(valueSchema, dec, depth)
+			if err != nil {
+				return err
+			}
+			if err := fn(key, value); err != nil {
+				return err
 			}
 		}
-		return resultMap, nil
 	}
+	return err
 }
 
-func (this *GenericDatumReader) mapUnion(field Schema, dec Decoder) (interface{}, error) {
+func (this *GenericDatumReader) mapUnion(field Schema, dec Decoder, depth int) (interface{}, error) {
 	if unionType, err := dec.ReadInt(); err != nil {
 		return nil, err
 	} else {
-		union := field.(*UnionSchema).Types[unionType]
-		return this.readValue(union, dec)
+		types := field.(*UnionSchema).Types
+		if unionType < 0 || int(unionType) >= len(types) {
+			return nil, UnionIndexOutOfRange
+		}
+		return this.readValueAt(types[unionType], dec, depth)
 	}
 }
 
 func (this *GenericDatumReader) mapFixed(field Schema, dec Decoder) ([]byte, error) {
-	fixed := make([]byte, field.(*FixedSchema).Size)
-	if err := dec.ReadFixed(fixed); err != nil {
+	return readFixed(field.(*FixedSchema), dec)
+}
+
+// reads a fixed value of the size given by its schema, with ReadFixedAlloc if the decoder is a BinaryDecoder
+func readFixed(fixed *FixedSchema, dec Decoder) ([]byte, error) {
+	if binaryDecoder, ok := dec.(*BinaryDecoder); ok {
+		return binaryDecoder.ReadFixedAlloc(fixed.Size)
+	}
+	value := make([]byte, fixed.Size)
+	if err := dec.ReadFixed(value); err != nil {
 		return nil, err
 	}
-	return fixed, nil
+	return value, nil
 }
 
-func (this *GenericDatumReader) mapRecord(field Schema, dec Decoder) (*GenericRecord, error) {
+func (this *GenericDatumReader) mapRecord(field Schema, dec Decoder, depth int) (*GenericRecord, error) {
 	record := NewGenericRecord(field)
 
 	recordSchema := field.(*RecordSchema)
 	for i := 0; i < len(recordSchema.Fields); i++ {
-		this.findAndSet(record, recordSchema.Fields[i], dec)
+		if err := this.findAndSet(record, recordSchema.Fields[i], dec, depth); err != nil {
+			return nil, err
+		}
 	}
 
 	return record, nil
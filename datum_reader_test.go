@@ -1,6 +1,8 @@
 package avro
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -266,3 +268,658 @@ func TestComplexOfComplexBinding(t *testing.T) {
 		}
 	}
 }
+
+const genericTestSchema = `{"type":"record","name":"Generic","fields":[
+	{"name":"booleanField","type":"boolean"},
+	{"name":"intField","type":"int"},
+	{"name":"longField","type":"long"},
+	{"name":"floatField","type":"float"},
+	{"name":"doubleField","type":"double"},
+	{"name":"bytesField","type":"bytes"},
+	{"name":"stringField","type":"string"},
+	{"name":"nullField","type":"null"},
+	{"name":"arrayField","type":{"type":"array","items":"string"}},
+	{"name":"mapField","type":{"type":"map","values":"long"}},
+	{"name":"enumField","type":{"type":"enum","name":"foo","symbols":["A","B","C"]}},
+	{"name":"unionField","type":["null","string"]},
+	{"name":"fixedField","type":{"type":"fixed","name":"four","size":4}},
+	{"name":"recordField","type":{"type":"record","name":"Nested","fields":[{"name":"x","type":"int"}]}}
+]}`
+
+func encodeGenericTestRecord() []byte {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteBoolean(true)
+	enc.WriteInt(-123)
+	enc.WriteLong(1234567890123)
+	enc.WriteFloat(1.5)
+	enc.WriteDouble(-2.25)
+	enc.WriteBytes([]byte{0x01, 0x02})
+	enc.WriteString("hello")
+	enc.WriteArrayStart(2)
+	enc.WriteString("a")
+	enc.WriteString("b")
+	enc.WriteArrayNext(1)
+	enc.WriteString("c")
+	enc.WriteArrayNext(0)
+	enc.WriteMapStart(1)
+	enc.WriteString("key")
+	enc.WriteLong(42)
+	enc.WriteMapNext(0)
+	enc.WriteInt(2)
+	enc.WriteLong(1)
+	enc.WriteString("union value")
+	enc.WriteRaw([]byte{0x0A, 0x0B, 0x0C, 0x0D})
+	enc.WriteInt(7)
+	return buf.Bytes()
+}
+
+func TestGenericDatumReaderRecord(t *testing.T) {
+	schema := MustParseSchema(genericTestSchema)
+	datumReader := NewGenericDatumReader()
+	datumReader.SetSchema(schema)
+
+	data := encodeGenericTestRecord()
+	dec := NewBinaryDecoder(data)
+	record := NewGenericRecord(schema)
+	assert(t, datumReader.Read(record, dec), nil)
+	assert(t, dec.Tell(), int64(len(data)))
+
+	assert(t, record.Get("booleanField"), true)
+	assert(t, record.Get("intField"), int32(-123))
+	assert(t, record.Get("longField"), int64(1234567890123))
+	assert(t, record.Get("floatField"), float32(1.5))
+	assert(t, record.Get("doubleField"), float64(-2.25))
+	assert(t, record.Get("bytesField"), []byte{0x01, 0x02})
+	assert(t, record.Get("stringField"), "hello")
+	assert(t, record.Get("nullField"), nil)
+	assert(t, record.Get("arrayField"), []interface{}{"a", "b", "c"})
+	assert(t, record.Get("mapField"), map[string]interface{}{"key": int64(42)})
+	assert(t, record.Get("enumField"), "C")
+	assert(t, record.Get("unionField"), "union value")
+	assert(t, record.Get("fixedField"), []byte{0x0A, 0x0B, 0x0C, 0x0D})
+	nested := record.Get("recordField").(*GenericRecord)
+	assert(t, nested.Get("x"), int32(7))
+}
+
+func TestGenericDatumReaderReadMap(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	// two blocks, the second one with a negative count followed by its size in bytes and a duplicate key
+	enc.WriteMapStart(2)
+	enc.WriteString("a")
+	enc.WriteInt(1)
+	enc.WriteString("b")
+	enc.WriteInt(2)
+	enc.WriteMapNext(-1)
+	enc.WriteLong(3)
+	enc.WriteString("a")
+	enc.WriteInt(3)
+	enc.WriteMapNext(0)
+	// an empty map followed by another value
+	enc.WriteMapStart(0)
+	enc.WriteInt(4)
+
+	datumReader := NewGenericDatumReader()
+	dec := NewBinaryDecoder(buf.Bytes())
+	value, err := datumReader.ReadMap(&IntSchema{}, dec)
+	assert(t, err, nil)
+	assert(t, value, map[string]interface{}{"a": int32(3), "b": int32(2)})
+
+	value, err = datumReader.ReadMap(&IntSchema{}, dec)
+	assert(t, err, nil)
+	assert(t, value, map[string]interface{}{})
+	next, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, next, int32(4))
+
+	_, err = datumReader.ReadMap(&IntSchema{}, NewBinaryDecoder([]byte{0x02, 0x02, 0x61}))
+	assertError(t, err, EOF)
+}
+
+func TestGenericDatumReaderReadArray(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	// a single block
+	enc.WriteArrayStart(2)
+	enc.WriteLong(1)
+	enc.WriteLong(2)
+	enc.WriteArrayNext(0)
+	// two blocks, the second one with a negative count followed by its size in bytes
+	enc.WriteArrayStart(1)
+	enc.WriteLong(3)
+	enc.WriteArrayNext(-2)
+	enc.WriteLong(2)
+	enc.WriteLong(4)
+	enc.WriteLong(5)
+	enc.WriteArrayNext(0)
+	// an empty array followed by another value
+	enc.WriteArrayStart(0)
+	enc.WriteLong(6)
+
+	datumReader := NewGenericDatumReader()
+	dec := NewBinaryDecoder(buf.Bytes())
+	value, err := datumReader.ReadArray(&LongSchema{}, dec)
+	assert(t, err, nil)
+	assert(t, value, []interface{}{int64(1), int64(2)})
+
+	value, err = datumReader.ReadArray(&LongSchema{}, dec)
+	assert(t, err, nil)
+	assert(t, value, []interface{}{int64(3), int64(4), int64(5)})
+
+	value, err = datumReader.ReadArray(&LongSchema{}, dec)
+	assert(t, err, nil)
+	assert(t, value, []interface{}{})
+	next, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, next, int64(6))
+
+	_, err = datumReader.ReadArray(&LongSchema{}, NewBinaryDecoder([]byte{0x04, 0x02}))
+	assertError(t, err, EOF)
+}
+
+func TestGenericDatumReaderStrictMaps(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteMapStart(3)
+	enc.WriteString("a")
+	enc.WriteInt(1)
+	enc.WriteString("b")
+	enc.WriteInt(2)
+	enc.WriteString("a")
+	enc.WriteInt(3)
+	enc.WriteMapNext(0)
+
+	datumReader := NewGenericDatumReader()
+	value, err := datumReader.ReadMap(&IntSchema{}, NewBinaryDecoder(buf.Bytes()))
+	assert(t, err, nil)
+	assert(t, value, map[string]interface{}{"a": int32(3), "b": int32(2)})
+
+	datumReader.SetStrictMaps(true)
+	_, err = datumReader.ReadMap(&IntSchema{}, NewBinaryDecoder(buf.Bytes()))
+	assert(t, err, DuplicateMapKey)
+
+	schema := MustParseSchema(`{"type":"map","values":"int"}`)
+	datumReader.SetSchema(schema)
+	var decoded map[string]interface{}
+	assert(t, datumReader.Read(&decoded, NewBinaryDecoder(buf.Bytes())), DuplicateMapKey)
+}
+
+func TestMapKeyDecodeError(t *testing.T) {
+	// a block of two entries, the length prefix of the second key is negative
+	data := []byte{0x04, 0x02, 'a', 0x02, 0x03, 'b', 0x04, 0x00}
+
+	_, err := NewGenericDatumReader().ReadMap(&IntSchema{}, NewBinaryDecoder(data))
+	var keyErr *MapKeyDecodeError
+	if !errors.As(err, &keyErr) {
+		t.Fatalf("Expected a MapKeyDecodeError, actual %v", err)
+	}
+	assert(t, keyErr.Index, int64(1))
+	assertError(t, err, InvalidStringLength)
+
+	// the entry index keeps counting over blocks
+	data = []byte{0x02, 0x02, 'a', 0x02, 0x02, 0x02, 'b', 0x04, 0x02, 0x03}
+	_, err = NewGenericDatumReader().ReadMap(&IntSchema{}, NewBinaryDecoder(data))
+	if !errors.As(err, &keyErr) {
+		t.Fatalf("Expected a MapKeyDecodeError, actual %v", err)
+	}
+	assert(t, keyErr.Index, int64(2))
+
+	var decoded struct {
+		Values map[string]int32
+	}
+	reader := NewSpecificDatumReader()
+	reader.SetSchema(MustParseSchema(`{"type": "record", "name": "Holder", "fields": [
+		{"name": "values", "type": {"type": "map", "values": "int"}}
+	]}`))
+	err = reader.Read(&decoded, NewBinaryDecoder([]byte{0x04, 0x02, 'a', 0x02, 0x03, 'b', 0x04, 0x00}))
+	if !errors.As(err, &keyErr) {
+		t.Fatalf("Expected a MapKeyDecodeError, actual %v", err)
+	}
+	assert(t, keyErr.Index, int64(1))
+}
+
+func TestGenericDatumReaderSkip(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Outer", "fields": [
+		{"name": "inner", "type": {"type": "record", "name": "Inner", "fields": [
+			{"name": "id", "type": "long"},
+			{"name": "tags", "type": {"type": "array", "items": "string"}},
+			{"name": "scores", "type": {"type": "map", "values": "double"}}
+		]}},
+		{"name": "choice", "type": ["null", "string", "Inner"]}
+	]}`)
+	union := schema.(*RecordSchema).Fields[1].Type
+
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteLong(7)
+	enc.WriteArrayStart(2)
+	enc.WriteString("a")
+	enc.WriteString("b")
+	enc.WriteArrayNext(0)
+	enc.WriteMapStart(1)
+	enc.WriteString("x")
+	enc.WriteDouble(1.5)
+	enc.WriteMapNext(0)
+	// the union holds the nested record branch
+	enc.WriteLong(2)
+	enc.WriteLong(8)
+	enc.WriteArrayStart(0)
+	enc.WriteMapStart(0)
+	recordEnd := buf.Len()
+	// a union holding the string branch
+	enc.WriteLong(1)
+	enc.WriteString("branch")
+	unionEnd := buf.Len()
+	enc.WriteLong(42)
+
+	datumReader := NewGenericDatumReader()
+	dec := NewBinaryDecoder(buf.Bytes())
+	assert(t, datumReader.Skip(schema, dec), nil)
+	assert(t, dec.Tell(), int64(recordEnd))
+	assert(t, datumReader.Skip(union, dec), nil)
+	assert(t, dec.Tell(), int64(unionEnd))
+	value, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, value, int64(42))
+
+	streamDec := NewStreamBinaryDecoder(bytes.NewReader(buf.Bytes()))
+	assert(t, datumReader.Skip(schema, streamDec), nil)
+	assert(t, datumReader.Skip(union, streamDec), nil)
+	value, err = streamDec.ReadLong()
+	assert(t, err, nil)
+	assert(t, value, int64(42))
+
+	assertError(t, datumReader.Skip(schema, NewBinaryDecoder(buf.Bytes()[:recordEnd-1])), EOF)
+}
+
+func TestGenericDatumReaderHooks(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Host", "namespace": "net", "fields": [
+		{"name": "address", "type": {"type": "fixed", "name": "IPv4", "namespace": "net", "size": 4}},
+		{"name": "previous", "type": {"type": "array", "items": "IPv4"}},
+		{"name": "mask", "type": {"type": "fixed", "name": "Mask", "size": 4}}
+	]}`)
+
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteRaw([]byte{10, 0, 0, 1})
+	enc.WriteArrayStart(1)
+	enc.WriteRaw([]byte{192, 168, 0, 1})
+	enc.WriteArrayNext(0)
+	enc.WriteRaw([]byte{255, 255, 255, 0})
+
+	readIPv4 := func(dec Decoder) (interface{}, error) {
+		ip := make([]byte, 4)
+		if err := dec.ReadFixed(ip); err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3]), nil
+	}
+	for _, name := range []string{"IPv4", "net.IPv4"} {
+		datumReader := NewGenericDatumReader()
+		datumReader.RegisterHook(name, readIPv4)
+		datumReader.SetSchema(schema)
+		record := NewGenericRecord(schema)
+		assert(t, datumReader.Read(record, NewBinaryDecoder(buf.Bytes())), nil)
+		assert(t, record.Get("address"), "10.0.0.1")
+		assert(t, record.Get("previous"), []interface{}{"192.168.0.1"})
+		assert(t, record.Get("mask"), []byte{255, 255, 255, 0})
+	}
+
+	// hooks apply to named types only
+	datumReader := NewGenericDatumReader()
+	datumReader.RegisterHook("bytes", readIPv4)
+	value, err := datumReader.readValue(&BytesSchema{}, NewBinaryDecoder([]byte{0x02, 0x01}))
+	assert(t, err, nil)
+	assert(t, value, []byte{0x01})
+
+	failure := errors.New("failure")
+	datumReader.RegisterHook("Mask", func(dec Decoder) (interface{}, error) {
+		return nil, failure
+	})
+	datumReader.SetSchema(schema)
+	assert(t, datumReader.Read(NewGenericRecord(schema), NewBinaryDecoder(buf.Bytes())), failure)
+}
+
+func TestGenericDatumReaderForEachArrayItem(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteArrayStart(2)
+	enc.WriteLong(10)
+	enc.WriteLong(20)
+	enc.WriteArrayNext(2)
+	enc.WriteLong(30)
+	enc.WriteLong(40)
+	enc.WriteArrayNext(0)
+	data := buf.Bytes()
+
+	datumReader := NewGenericDatumReader()
+	var items []interface{}
+	var indexes []int64
+	err := datumReader.ForEachArrayItem(&LongSchema{}, NewBinaryDecoder(data), func(index int64, v interface{}) error {
+		indexes = append(indexes, index)
+		items = append(items, v)
+		return nil
+	})
+	assert(t, err, nil)
+	assert(t, indexes, []int64{0, 1, 2, 3})
+	assert(t, items, []interface{}{int64(10), int64(20), int64(30), int64(40)})
+
+	// aborting at the third item stops right after it
+	stop := errors.New("stop")
+	dec := NewBinaryDecoder(data)
+	items = nil
+	err = datumReader.ForEachArrayItem(&LongSchema{}, dec, func(index int64, v interface{}) error {
+		items = append(items, v)
+		if index == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert(t, err, stop)
+	assert(t, items, []interface{}{int64(10), int64(20), int64(30)})
+	assert(t, dec.Tell(), int64(5))
+	next, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, next, int64(40))
+}
+
+func TestGenericDatumReaderForEachMapEntry(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteMapStart(1)
+	enc.WriteString("a")
+	enc.WriteInt(1)
+	enc.WriteMapNext(2)
+	enc.WriteString("b")
+	enc.WriteInt(2)
+	enc.WriteString("c")
+	enc.WriteInt(3)
+	enc.WriteMapNext(0)
+	data := buf.Bytes()
+
+	datumReader := NewGenericDatumReader()
+	var keys []string
+	err := datumReader.ForEachMapEntry(&IntSchema{}, NewBinaryDecoder(data), func(key string, v interface{}) error {
+		keys = append(keys, key)
+		assert(t, v, int32(len(keys)))
+		return nil
+	})
+	assert(t, err, nil)
+	assert(t, keys, []string{"a", "b", "c"})
+
+	stop := errors.New("stop")
+	dec := NewBinaryDecoder(data)
+	keys = nil
+	err = datumReader.ForEachMapEntry(&IntSchema{}, dec, func(key string, v interface{}) error {
+		keys = append(keys, key)
+		if key == "b" {
+			return stop
+		}
+		return nil
+	})
+	assert(t, err, stop)
+	assert(t, keys, []string{"a", "b"})
+	assert(t, dec.Tell(), int64(8))
+	next, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, next, "c")
+}
+
+func TestEmptyArrayRoundTrip(t *testing.T) {
+	schema := MustParseSchema(`{"type":"record","name":"Arrays","fields":[
+		{"name":"empty","type":{"type":"array","items":"string"}},
+		{"name":"value","type":"int"}
+	]}`)
+	record := NewGenericRecord(schema)
+	record.Set("empty", []interface{}{})
+	record.Set("value", int32(5))
+	buf := &bytes.Buffer{}
+	genericWriter := NewGenericDatumWriter()
+	genericWriter.SetSchema(schema)
+	assert(t, genericWriter.Write(record, NewBinaryEncoder(buf)), nil)
+	assert(t, buf.Bytes(), []byte{0x00, 0x0A})
+
+	for _, datumReader := range []DatumReader{NewGenericDatumReader(), NewResolvingDatumReader(schema)} {
+		datumReader.SetSchema(schema)
+		decoded := NewGenericRecord(schema)
+		assert(t, datumReader.Read(decoded, NewBinaryDecoder(buf.Bytes())), nil)
+		assert(t, decoded.Get("empty"), []interface{}{})
+		assert(t, decoded.Get("value"), int32(5))
+	}
+
+	type arrays struct {
+		Empty []string
+		Value int32
+	}
+	buf.Reset()
+	specificWriter := NewSpecificDatumWriter()
+	specificWriter.SetSchema(schema)
+	assert(t, specificWriter.Write(&arrays{Empty: []string{}, Value: 5}, NewBinaryEncoder(buf)), nil)
+	assert(t, buf.Bytes(), []byte{0x00, 0x0A})
+	specificReader := NewSpecificDatumReader()
+	specificReader.SetSchema(schema)
+	decoded := &arrays{}
+	assert(t, specificReader.Read(decoded, NewBinaryDecoder(buf.Bytes())), nil)
+	assert(t, decoded, &arrays{Empty: []string{}, Value: 5})
+}
+
+func TestEmptyMapRoundTrip(t *testing.T) {
+	schema := MustParseSchema(`{"type":"record","name":"Maps","fields":[
+		{"name":"empty","type":{"type":"map","values":"int"}},
+		{"name":"value","type":"int"}
+	]}`)
+	record := NewGenericRecord(schema)
+	record.Set("empty", map[string]interface{}{})
+	record.Set("value", int32(5))
+	buf := &bytes.Buffer{}
+	genericWriter := NewGenericDatumWriter()
+	genericWriter.SetSchema(schema)
+	assert(t, genericWriter.Write(record, NewBinaryEncoder(buf)), nil)
+	assert(t, buf.Bytes(), []byte{0x00, 0x0A})
+
+	for _, datumReader := range []DatumReader{NewGenericDatumReader(), NewResolvingDatumReader(schema)} {
+		datumReader.SetSchema(schema)
+		decoded := NewGenericRecord(schema)
+		assert(t, datumReader.Read(decoded, NewBinaryDecoder(buf.Bytes())), nil)
+		assert(t, decoded.Get("empty"), map[string]interface{}{})
+		assert(t, decoded.Get("value"), int32(5))
+	}
+
+	type maps struct {
+		Empty map[string]int32
+		Value int32
+	}
+	buf.Reset()
+	specificWriter := NewSpecificDatumWriter()
+	specificWriter.SetSchema(schema)
+	assert(t, specificWriter.Write(&maps{Empty: map[string]int32{}, Value: 5}, NewBinaryEncoder(buf)), nil)
+	assert(t, buf.Bytes(), []byte{0x00, 0x0A})
+	specificReader := NewSpecificDatumReader()
+	specificReader.SetSchema(schema)
+	decoded := &maps{}
+	assert(t, specificReader.Read(decoded, NewBinaryDecoder(buf.Bytes())), nil)
+	assert(t, decoded, &maps{Empty: map[string]int32{}, Value: 5})
+}
+
+func TestGenericDatumReaderTruncated(t *testing.T) {
+	schema := MustParseSchema(genericTestSchema)
+	datumReader := NewGenericDatumReader()
+	datumReader.SetSchema(schema)
+
+	data := encodeGenericTestRecord()
+	for _, length := range []int{0, 10, len(data) - 5, len(data) - 1} {
+		record := NewGenericRecord(schema)
+		if err := datumReader.Read(record, NewBinaryDecoder(data[:length])); err == nil {
+			t.Errorf("Expected an error reading a record truncated to %d bytes", length)
+		}
+	}
+}
+
+func TestUnionIndexOutOfRange(t *testing.T) {
+	schema := MustParseSchema(`{"type":"record","name":"Nullable","fields":[{"name":"unionField","type":["null","string"]}]}`)
+
+	genericReader := NewGenericDatumReader()
+	genericReader.SetSchema(schema)
+	err := genericReader.Read(NewGenericRecord(schema), NewBinaryDecoder([]byte{0x04, 0x02, 0x61}))
+	assert(t, err, UnionIndexOutOfRange)
+
+	type nullable struct {
+		UnionField interface{}
+	}
+	specificReader := NewSpecificDatumReader()
+	specificReader.SetSchema(schema)
+	err = specificReader.Read(&nullable{}, NewBinaryDecoder([]byte{0x04, 0x02, 0x61}))
+	assert(t, err, UnionIndexOutOfRange)
+}
+
+func TestEnumIndexOutOfRange(t *testing.T) {
+	enum := MustParseSchema(`{"type":"enum","name":"Suit","symbols":["HEARTS","SPADES"]}`).(*EnumSchema)
+	genericReader := NewGenericDatumReader()
+	for index, symbol := range enum.Symbols {
+		actual, err := genericReader.ReadEnumSymbol(enum, NewBinaryDecoder([]byte{byte(index * 2)}))
+		assert(t, err, nil)
+		assert(t, actual, symbol)
+	}
+	for _, index := range []byte{0x04, 0x01, 0x7F} {
+		_, err := genericReader.ReadEnumSymbol(enum, NewBinaryDecoder([]byte{index}))
+		assert(t, err, EnumIndexOutOfRange)
+	}
+	_, err := genericReader.ReadEnumSymbol(enum, NewBinaryDecoder([]byte{}))
+	assertError(t, err, EOF)
+
+	schema := MustParseSchema(`{"type":"record","name":"Card","fields":[{"name":"suit","type":` + enum.String() + `}]}`)
+	genericReader.SetSchema(schema)
+	err = genericReader.Read(NewGenericRecord(schema), NewBinaryDecoder([]byte{0x04}))
+	assert(t, err, EnumIndexOutOfRange)
+
+	type card struct {
+		Suit *GenericEnum
+	}
+	specificReader := NewSpecificDatumReader()
+	specificReader.SetSchema(schema)
+	err = specificReader.Read(&card{}, NewBinaryDecoder([]byte{0x04}))
+	assert(t, err, EnumIndexOutOfRange)
+}
+
+const linkedListSchema = `{"type":"record","name":"Node","fields":[
+	{"name":"value","type":"int"},
+	{"name":"next","type":["null","Node"]}
+]}`
+
+// encodes a linked list of nodes holding the given values
+func encodeLinkedList(values ...int32) []byte {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	for i, value := range values {
+		enc.WriteInt(value)
+		if i < len(values)-1 {
+			enc.WriteInt(1)
+		} else {
+			enc.WriteInt(0)
+		}
+	}
+	return buf.Bytes()
+}
+
+type linkedListNode struct {
+	Value int32
+	Next  *linkedListNode
+}
+
+func TestRecursiveSchemaDecoding(t *testing.T) {
+	schema := MustParseSchema(linkedListSchema)
+	assert(t, schema.(*RecordSchema).Fields[1].Type.(*UnionSchema).Types[1].(*RecursiveSchema).Actual, schema)
+	if len(schema.String()) == 0 {
+		t.Fatal("Expected a JSON representation of a recursive schema")
+	}
+	data := encodeLinkedList(1, 2, 3)
+
+	genericReader := NewGenericDatumReader()
+	genericReader.SetSchema(schema)
+	record := NewGenericRecord(schema)
+	assert(t, genericReader.Read(record, NewBinaryDecoder(data)), nil)
+	var values []interface{}
+	for node := record; ; {
+		values = append(values, node.Get("value"))
+		next, ok := node.Get("next").(*GenericRecord)
+		if !ok {
+			assert(t, node.Get("next"), nil)
+			break
+		}
+		node = next
+	}
+	assert(t, values, []interface{}{int32(1), int32(2), int32(3)})
+
+	specificReader := NewSpecificDatumReader()
+	specificReader.SetSchema(schema)
+	head := &linkedListNode{}
+	assert(t, specificReader.Read(head, NewBinaryDecoder(data)), nil)
+	assert(t, head, &linkedListNode{1, &linkedListNode{2, &linkedListNode{3, nil}}})
+}
+
+func TestGenericDatumReaderMaxDepth(t *testing.T) {
+	schema := MustParseSchema(linkedListSchema)
+	// returns a linked list of a given length
+	linkedList := func(length int) []byte {
+		return encodeLinkedList(make([]int32, length)...)
+	}
+
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	assert(t, reader.Read(NewGenericRecord(schema), NewBinaryDecoder(linkedList(256))), nil)
+	assert(t, reader.Read(NewGenericRecord(schema), NewBinaryDecoder(linkedList(257))), MaxDepthExceeded)
+	assert(t, reader.Read(NewGenericRecord(schema), NewBinaryDecoder(linkedList(1000000))), MaxDepthExceeded)
+
+	reader.SetMaxDepth(1000)
+	assert(t, reader.Read(NewGenericRecord(schema), NewBinaryDecoder(linkedList(1000))), nil)
+	assert(t, reader.Read(NewGenericRecord(schema), NewBinaryDecoder(linkedList(1001))), MaxDepthExceeded)
+
+	// arrays and maps count as well
+	nested := MustParseSchema(`{"type": "array", "items": {"type": "map", "values": "long"}}`)
+	reader.SetSchema(nested)
+	reader.SetMaxDepth(1)
+	var array []interface{}
+	assert(t, reader.Read(&array, NewBinaryDecoder([]byte{0x00})), nil)
+	assert(t, reader.Read(&array, NewBinaryDecoder([]byte{0x02, 0x00, 0x00})), MaxDepthExceeded)
+	reader.SetMaxDepth(2)
+	assert(t, reader.Read(&array, NewBinaryDecoder([]byte{0x02, 0x00, 0x00})), nil)
+}
+
+func TestMaxDepthDeepLinkedList(t *testing.T) {
+	schema := MustParseSchema(linkedListSchema)
+	deep := encodeLinkedList(make([]int32, 1000000)...)
+	shallow := encodeLinkedList(make([]int32, 256)...)
+
+	specificReader := NewSpecificDatumReader()
+	specificReader.SetSchema(schema)
+	assert(t, specificReader.Read(&linkedListNode{}, NewBinaryDecoder(shallow)), nil)
+	assert(t, specificReader.Read(&linkedListNode{}, NewBinaryDecoder(deep)), MaxDepthExceeded)
+	specificReader.SetMaxDepth(10)
+	assert(t, specificReader.Read(&linkedListNode{}, NewBinaryDecoder(shallow)), MaxDepthExceeded)
+
+	resolvingReader := NewResolvingDatumReader(schema)
+	resolvingReader.SetSchema(schema)
+	var value interface{}
+	assert(t, resolvingReader.Read(&value, NewBinaryDecoder(shallow)), nil)
+	assert(t, resolvingReader.Read(&value, NewBinaryDecoder(deep)), MaxDepthExceeded)
+	resolvingReader.SetMaxDepth(10)
+	assert(t, resolvingReader.Read(&value, NewBinaryDecoder(shallow)), MaxDepthExceeded)
+
+	// the rest of the list is skipped as the reader schema has no next field
+	skippingReader := NewResolvingDatumReader(MustParseSchema(`{"type":"record","name":"Node","fields":[
+		{"name":"value","type":"int"}
+	]}`))
+	skippingReader.SetSchema(schema)
+	assert(t, skippingReader.Read(&value, NewBinaryDecoder(shallow)), nil)
+	assertError(t, skippingReader.Read(&value, NewBinaryDecoder(deep)), MaxDepthExceeded)
+
+	genericReader := NewGenericDatumReader()
+	assert(t, genericReader.Skip(schema, NewBinaryDecoder(shallow)), nil)
+	assertError(t, genericReader.Skip(schema, NewBinaryDecoder(deep)), MaxDepthExceeded)
+	dec := NewBinaryDecoder(shallow)
+	dec.SetMaxDepth(10)
+	assertError(t, genericReader.Skip(schema, dec), MaxDepthExceeded)
+	_, err := NewBinaryDecoder(deep).ReadRaw(schema)
+	assertError(t, err, MaxDepthExceeded)
+}
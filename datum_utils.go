@@ -26,7 +26,8 @@ func fieldByTag(where reflect.Value, name string) reflect.Value {
 
 	for i := 0; i < where.NumField(); i++ {
 		field := where.Field(i)
-		if elemType.Field(i).Tag.Get("avro") == name {
+		// options following the name, e.g. `avro:"name,omitempty"`, do not affect the mapping
+		if strings.Split(elemType.Field(i).Tag.Get("avro"), ",")[0] == name {
 			return field
 		}
 	}
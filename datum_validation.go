@@ -0,0 +1,242 @@
+package avro
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Checks that a given value can be written with a given schema by GenericDatumWriter, i.e. that every value has the
+// Go type GenericDatumReader would read for its schema, enum symbols belong to the enum, fixed values have the
+// right size and every union value matches one of the union branches. Record fields without a value are valid if
+// the field has a default or may be null. Returns an error wrapping InvalidDatum that names the offending value.
+func ValidateDatum(schema Schema, v interface{}) error {
+	if schema == nil {
+		return SchemaNotSet
+	}
+	return validateDatum(schema, v, schema.GetName())
+}
+
+// Validates a given value against a given schema with ValidateDatum and writes it with GenericDatumWriter only if
+// it is valid, so that nothing is written for an invalid value. Returns an error if validation or writing fails.
+func (this *BinaryEncoder) WriteDatum(schema Schema, v interface{}) error {
+	if err := ValidateDatum(schema, v); err != nil {
+		return err
+	}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	return writer.Write(v, this)
+}
+
+// Checks that given data holds exactly one value of a given schema in Avro binary encoding. The value is walked
+// without building Go values for it, checking that nothing is truncated, booleans, enum indexes and union indexes are
+// valid and no bytes follow the value. Returns an error describing the first mismatch, wrapping TrailingBytes if
+// the data continues after the value, or MaxDepthExceeded if records, arrays and maps are nested more than 256 deep.
+func Validate(schema Schema, data []byte) error {
+	if schema == nil {
+		return SchemaNotSet
+	}
+	dec := NewBinaryDecoder(data)
+	if err := dec.validateValue(schema); err != nil {
+		return err
+	}
+	if remaining := dec.Remaining(); remaining > 0 {
+		return fmt.Errorf("%w: %d bytes after the value ending at position %d", TrailingBytes, remaining, dec.Tell())
+	}
+	return nil
+}
+
+// reads a value of a given schema like skipValue but checks the values skipValue does not look at
+func (this *BinaryDecoder) validateValue(schema Schema) error {
+	switch schema.(type) {
+	case *ArraySchema, *MapSchema, *RecordSchema:
+		if err := this.enterNested(); err != nil {
+			return err
+		}
+		defer this.leaveNested()
+	}
+
+	switch s := schema.(type) {
+	case *BooleanSchema:
+		_, err := this.ReadBoolean()
+		return err
+	case *EnumSchema:
+		_, err := readEnumIndex(s.Symbols, this)
+		return err
+	case *ArraySchema:
+		count, err := this.ReadArrayStart()
+		for ; count > 0 && err == nil; count, err = this.ArrayNext() {
+			for i := int64(0); i < count; i++ {
+				if err := this.validateValue(s.Items); err != nil {
+					return err
+				}
+			}
+		}
+		return err
+	case *MapSchema:
+		count, err := this.ReadMapStart()
+		for ; count > 0 && err == nil; count, err = this.MapNext() {
+			for i := int64(0); i < count; i++ {
+				if err := this.SkipString(); err != nil {
+					return err
+				}
+				if err := this.validateValue(s.Values); err != nil {
+					return err
+				}
+			}
+		}
+		return err
+	case *UnionSchema:
+		index, err := this.ReadUnionIndex(len(s.Types))
+		if err != nil {
+			return err
+		}
+		return this.validateValue(s.Types[index])
+	case *RecordSchema:
+		for _, field := range s.Fields {
+			if err := this.validateValue(field.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *RecursiveSchema:
+		return this.validateValue(s.Actual)
+	}
+	return this.skipValue(schema)
+}
+
+func validateDatum(schema Schema, v interface{}, path string) error {
+	if _, ok := v.(RawDatum); ok {
+		return nil
+	}
+	v = primitiveValue(schema, v)
+
+	ok := false
+	switch s := schema.(type) {
+	case *NullSchema:
+		ok = v == nil
+	case *BooleanSchema:
+		_, ok = v.(bool)
+	case *IntSchema:
+		_, ok = v.(int32)
+	case *LongSchema:
+		_, ok = v.(int64)
+	case *FloatSchema:
+		_, ok = v.(float32)
+	case *DoubleSchema:
+		_, ok = v.(float64)
+	case *BytesSchema:
+		_, ok = v.([]byte)
+	case *StringSchema:
+		_, ok = v.(string)
+	case *EnumSchema:
+		symbol, isString := v.(string)
+		if enum, isEnum := v.(*GenericEnum); isEnum {
+			symbol, isString = enum.Get(), true
+		}
+		if isString && enumIndex(s, symbol, -1) < 0 {
+			return invalidDatum(path, "%q is not a symbol of enum %s", symbol, s.Name)
+		}
+		ok = isString
+	case *FixedSchema:
+		if fixed, isBytes := v.([]byte); isBytes && len(fixed) != s.Size {
+			return invalidDatum(path, "fixed %s needs %d bytes, got %d", s.Name, s.Size, len(fixed))
+		}
+		_, ok = v.([]byte)
+	case *ArraySchema:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			break
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := validateDatum(s.Items, rv.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *MapSchema:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		for _, key := range rv.MapKeys() {
+			if err := validateDatum(s.Values, rv.MapIndex(key).Interface(), fmt.Sprintf("%s[%q]", path, key.String())); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *UnionSchema:
+		if unionBranch(s, v) < 0 {
+			return invalidDatum(path, "%v (%T) matches no union branch", v, v)
+		}
+		return nil
+	case *RecordSchema:
+		record, isRecord := v.(*GenericRecord)
+		if !isRecord {
+			break
+		}
+		for _, field := range s.Fields {
+			value := record.Get(field.Name)
+			if value == nil && field.Default != nil {
+				if _, err := defaultValue(field.Type, field.Default); err != nil {
+					return invalidDatum(path+"."+field.Name, "%v", err)
+				}
+				continue
+			}
+			if err := validateDatum(field.Type, value, path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *RecursiveSchema:
+		return validateDatum(s.Actual, v, path)
+	default:
+		return invalidDatum(path, "unknown schema type %d", schema.Type())
+	}
+
+	if !ok {
+		return invalidDatum(path, "%v (%T) is not a valid %s value", v, v, schema.GetName())
+	}
+	return nil
+}
+
+// returns the index of the first branch of a union a given value is valid for or -1 if there is none. A UnionValue
+// selects the branch named by it, provided its value is valid for that branch.
+func unionBranch(union *UnionSchema, v interface{}) int {
+	if hinted, ok := v.(UnionValue); ok {
+		for i, branch := range union.Types {
+			if isBranchNamed(branch, hinted.Type) && validateDatum(branch, hinted.Value, "") == nil {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for i, branch := range union.Types {
+		if validateDatum(branch, v, "") == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// tells whether a given name is the type name of a given union branch or the full name of a named branch
+func isBranchNamed(branch Schema, name string) bool {
+	return branch.GetName() == name || schemaFullName(branch) == name
+}
+
+// returns the full name of a record, enum or fixed schema or an empty string for other schemas
+func schemaFullName(schema Schema) string {
+	switch s := actualSchema(schema).(type) {
+	case *RecordSchema:
+		return getFullName(s.Name, s.Namespace)
+	case *EnumSchema:
+		return getFullName(s.Name, s.Namespace)
+	case *FixedSchema:
+		return getFullName(s.Name, s.Namespace)
+	}
+	return ""
+}
+
+func invalidDatum(path string, format string, args ...interface{}) error {
+	return fmt.Errorf("%w at %s: %s", InvalidDatum, path, fmt.Sprintf(format, args...))
+}
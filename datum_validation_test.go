@@ -0,0 +1,117 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const validationTestSchema = `{"type":"record","name":"Order","fields":[
+	{"name":"id","type":"long"},
+	{"name":"status","type":{"type":"enum","name":"Status","symbols":["NEW","DONE"]}},
+	{"name":"hash","type":{"type":"fixed","name":"Hash","size":4}},
+	{"name":"items","type":{"type":"array","items":"string"}},
+	{"name":"prices","type":{"type":"map","values":"double"}},
+	{"name":"note","type":["null","string"]},
+	{"name":"priority","type":"int","default":3}
+]}`
+
+func newValidationTestRecord() *GenericRecord {
+	record := NewGenericRecord(MustParseSchema(validationTestSchema))
+	record.Set("id", int64(1))
+	record.Set("status", "NEW")
+	record.Set("hash", []byte{0x01, 0x02, 0x03, 0x04})
+	record.Set("items", []interface{}{"apple"})
+	record.Set("prices", map[string]interface{}{"apple": 1.5})
+	record.Set("note", "fragile")
+	return record
+}
+
+func TestWriteDatum(t *testing.T) {
+	schema := MustParseSchema(validationTestSchema)
+	record := newValidationTestRecord()
+	buf := &bytes.Buffer{}
+	assert(t, NewBinaryEncoder(buf).WriteDatum(schema, record), nil)
+
+	datumReader := NewGenericDatumReader()
+	datumReader.SetSchema(schema)
+	decoded := NewGenericRecord(schema)
+	assert(t, datumReader.Read(decoded, NewBinaryDecoder(buf.Bytes())), nil)
+	assert(t, decoded.Get("status"), "NEW")
+	assert(t, decoded.Get("note"), "fragile")
+	assert(t, decoded.Get("priority"), int32(3))
+
+	enum := NewGenericEnum([]string{"NEW", "DONE"})
+	enum.Set("DONE")
+	record.Set("status", enum)
+	assert(t, ValidateDatum(schema, record), nil)
+}
+
+func TestWriteDatumInvalid(t *testing.T) {
+	schema := MustParseSchema(validationTestSchema)
+	invalid := []struct {
+		field   string
+		value   interface{}
+		message string
+	}{
+		{"id", int32(1), "Invalid datum at Order.id: 1 (int32) is not a valid long value"},
+		{"status", "CANCELLED", `Invalid datum at Order.status: "CANCELLED" is not a symbol of enum Status`},
+		{"hash", []byte{0x01, 0x02}, "Invalid datum at Order.hash: fixed Hash needs 4 bytes, got 2"},
+		{"items", []interface{}{"apple", 2}, "Invalid datum at Order.items[1]: 2 (int) is not a valid string value"},
+		{"prices", map[string]interface{}{"apple": float32(1.5)}, `Invalid datum at Order.prices["apple"]: 1.5 (float32) is not a valid double value`},
+		{"note", int64(7), "Invalid datum at Order.note: 7 (int64) matches no union branch"},
+		{"priority", "high", "Invalid datum at Order.priority: high (string) is not a valid int value"},
+	}
+	for _, test := range invalid {
+		record := newValidationTestRecord()
+		record.Set(test.field, test.value)
+		buf := &bytes.Buffer{}
+		err := NewBinaryEncoder(buf).WriteDatum(schema, record)
+		if !errors.Is(err, InvalidDatum) {
+			t.Fatalf("Expected %v for field %s, actual %v", InvalidDatum, test.field, err)
+		}
+		assert(t, err.Error(), test.message)
+		assert(t, buf.Len(), 0)
+	}
+
+	record := newValidationTestRecord()
+	record.Set("id", nil)
+	assertError(t, ValidateDatum(schema, record), InvalidDatum)
+	assertError(t, ValidateDatum(schema, map[string]interface{}{}), InvalidDatum)
+	assertError(t, ValidateDatum(nil, record), SchemaNotSet)
+}
+
+func TestValidate(t *testing.T) {
+	schema := MustParseSchema(validationTestSchema)
+	buf := &bytes.Buffer{}
+	assert(t, NewBinaryEncoder(buf).WriteDatum(schema, newValidationTestRecord()), nil)
+	encoded := buf.Bytes()
+	assert(t, Validate(schema, encoded), nil)
+
+	for i := 0; i < len(encoded); i++ {
+		if err := Validate(schema, encoded[:i]); err == nil {
+			t.Fatalf("Expected an error for data truncated to %d bytes", i)
+		}
+	}
+
+	err := Validate(schema, append(append([]byte(nil), encoded...), 0x00, 0x01))
+	assertError(t, err, TrailingBytes)
+	assert(t, err.Error(), fmt.Sprintf("Trailing bytes after value: 2 bytes after the value ending at position %d", len(encoded)))
+
+	// the status enum index follows the id
+	corrupted := append([]byte(nil), encoded...)
+	corrupted[1] = 0x04
+	assertError(t, Validate(schema, corrupted), EnumIndexOutOfRange)
+
+	assertError(t, Validate(MustParseSchema(`"boolean"`), []byte{0x02}), InvalidBool)
+	assertError(t, Validate(MustParseSchema(`["null", "long"]`), []byte{0x04}), UnionIndexOutOfRange)
+	assertError(t, Validate(nil, encoded), SchemaNotSet)
+}
+
+func TestValidateMaxDepth(t *testing.T) {
+	schema := MustParseSchema(linkedListSchema)
+	assert(t, Validate(schema, encodeLinkedList(make([]int32, 256)...)), nil)
+	assertError(t, Validate(schema, encodeLinkedList(make([]int32, 257)...)), MaxDepthExceeded)
+	assertError(t, Validate(schema, encodeLinkedList(make([]int32, 1000000)...)), MaxDepthExceeded)
+}
@@ -166,6 +166,10 @@ func (this *SpecificDatumWriter) writeArray(v reflect.Value, enc Encoder, s Sche
 
 	//TODO should probably write blocks of some length
 	enc.WriteArrayStart(int64(v.Len()))
+	// an empty array is written as just the terminating block
+	if v.Len() == 0 {
+		return nil
+	}
 	for i := 0; i < v.Len(); i++ {
 		if err := this.write(v.Index(i), enc, s.(*ArraySchema).Items); err != nil {
 			return err
@@ -183,6 +187,10 @@ func (this *SpecificDatumWriter) writeMap(v reflect.Value, enc Encoder, s Schema
 
 	//TODO should probably write blocks of some length
 	enc.WriteMapStart(int64(v.Len()))
+	// an empty map is written as just the terminating block
+	if v.Len() == 0 {
+		return nil
+	}
 	for _, key := range v.MapKeys() {
 		this.writeString(key, enc, &StringSchema{})
 		if err := this.write(v.MapIndex(key), enc, s.(*MapSchema).Values); err != nil {
@@ -213,6 +221,10 @@ func (this *SpecificDatumWriter) writeUnion(v reflect.Value, enc Encoder, s Sche
 	}
 
 	enc.WriteLong(int64(index))
+	// pointers to non-null values are written as the values they point to
+	if unionSchema.Types[index].Type() != Null {
+		v = dereference(v)
+	}
 	return this.write(v, enc, unionSchema.Types[index])
 }
 
@@ -248,9 +260,18 @@ func (this *SpecificDatumWriter) writeRecord(v reflect.Value, enc Encoder, s Sch
 	return nil
 }
 
+// UnionValue wraps a value written by GenericDatumWriter to a union to select the union branch explicitly instead of
+// by the Go type of the value, e.g. to tell an int branch from a long branch or two record branches apart. Type is the
+// name of a primitive type such as "long" or the name or full name of a named type.
+type UnionValue struct {
+	Type  string
+	Value interface{}
+}
+
 // GenericDatumWriter implements DatumWriter and is used for writing GenericRecords or other Avro supported types
 // (full list is: interface{}, bool, int32, int64, float32, float64, string, slices of any type, maps with string keys
-// and any values, GenericEnums) to a given Encoder.
+// and any values, GenericEnums) to a given Encoder. Values of schemas annotated with a logical type may also be given
+// as the native Go types GenericDatumReader reads them as.
 type GenericDatumWriter struct {
 	schema Schema
 }
@@ -274,6 +295,13 @@ func (this *GenericDatumWriter) Write(obj interface{}, enc Encoder) error {
 }
 
 func (this *GenericDatumWriter) write(v interface{}, enc Encoder, s Schema) error {
+	if raw, ok := v.(RawDatum); ok {
+		enc.WriteRaw(raw.Bytes)
+		return nil
+	}
+	// native Go values of logical types as GenericDatumReader reads them are written as their primitive values
+	v = primitiveValue(s, v)
+
 	switch s.Type() {
 	case Null:
 	case Boolean:
@@ -394,6 +422,10 @@ func (this *GenericDatumWriter) writeArray(v interface{}, enc Encoder, s Schema)
 
 	//TODO should probably write blocks of some length
 	enc.WriteArrayStart(int64(rv.Len()))
+	// an empty array is written as just the terminating block
+	if rv.Len() == 0 {
+		return nil
+	}
 	for i := 0; i < rv.Len(); i++ {
 		this.write(rv.Index(i).Interface(), enc, s.(*ArraySchema).Items)
 	}
@@ -410,6 +442,10 @@ func (this *GenericDatumWriter) writeMap(v interface{}, enc Encoder, s Schema) e
 
 	//TODO should probably write blocks of some length
 	enc.WriteMapStart(int64(rv.Len()))
+	// an empty map is written as just the terminating block
+	if rv.Len() == 0 {
+		return nil
+	}
 	for _, key := range rv.MapKeys() {
 		this.writeString(key.Interface(), enc)
 		this.write(rv.MapIndex(key).Interface(), enc, s.(*MapSchema).Values)
@@ -420,39 +456,32 @@ func (this *GenericDatumWriter) writeMap(v interface{}, enc Encoder, s Schema) e
 }
 
 func (this *GenericDatumWriter) writeEnum(v interface{}, enc Encoder, s Schema) error {
-	switch v.(type) {
+	var symbol string
+	switch value := v.(type) {
 	case *GenericEnum:
-		{
-			rs := s.(*EnumSchema)
-			for i := range rs.Symbols {
-				if rs.Name == rs.Symbols[i] {
-					this.writeInt(i, enc)
-					break
-				}
-			}
-		}
+		symbol = value.Get()
 	case string:
-		{
-			rs := s.(*EnumSchema)
-			for i := range rs.Symbols {
-				if v.(string) == rs.Symbols[i] {
-					enc.WriteInt(int32(i))
-					break
-				}
-			}
-		}
+		symbol = value
 	default:
 		return fmt.Errorf("%v is not a *GenericEnum", v)
 	}
 
+	index := enumIndex(s.(*EnumSchema), symbol, -1)
+	if index < 0 {
+		return fmt.Errorf("%s is not a symbol of %s", symbol, s.GetName())
+	}
+	enc.WriteInt(index)
 	return nil
 }
 
 func (this *GenericDatumWriter) writeUnion(v interface{}, enc Encoder, s Schema) error {
 	unionSchema := s.(*UnionSchema)
 
-	index := unionSchema.GetType(reflect.ValueOf(v))
+	index := unionBranch(unionSchema, v)
 	if index != -1 {
+		if hinted, ok := v.(UnionValue); ok {
+			v = hinted.Value
+		}
 		enc.WriteInt(int32(index))
 		return this.write(v, enc, unionSchema.Types[index])
 	}
@@ -498,7 +527,16 @@ func (this *GenericDatumWriter) isWritableAs(v interface{}, s Schema) bool {
 }
 
 func (this *GenericDatumWriter) writeFixed(v interface{}, enc Encoder, s Schema) error {
-	return this.writeBytes(v, enc)
+	fixed, ok := v.([]byte)
+	if !ok {
+		return fmt.Errorf("%v is not a []byte", v)
+	}
+	if len(fixed) != s.(*FixedSchema).Size {
+		return InvalidFixedSize
+	}
+	// fixed values are written without a length
+	enc.WriteRaw(fixed)
+	return nil
 }
 
 func (this *GenericDatumWriter) writeRecord(v interface{}, enc Encoder, s Schema) error {
@@ -509,10 +547,15 @@ func (this *GenericDatumWriter) writeRecord(v interface{}, enc Encoder, s Schema
 			for i := range rs.Fields {
 				schemaField := rs.Fields[i]
 				field := value.Get(schemaField.Name)
-				if field == nil {
-					field = schemaField.Default
+				if field == nil && schemaField.Default != nil {
+					var err error
+					if field, err = defaultValue(schemaField.Type, schemaField.Default); err != nil {
+						return err
+					}
+				}
+				if err := this.write(field, enc, schemaField.Type); err != nil {
+					return err
 				}
-				this.write(field, enc, schemaField.Type)
 			}
 		}
 	default:
@@ -391,3 +391,51 @@ var _Employee_schema, _Employee_schema_err = ParseSchema(`{
         }
     ]
 }`)
+
+func TestGenericDatumWriterUnionValue(t *testing.T) {
+	write := func(schema Schema, v interface{}) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		writer := NewGenericDatumWriter()
+		writer.SetSchema(schema)
+		err := writer.Write(v, NewBinaryEncoder(buf))
+		return buf.Bytes(), err
+	}
+
+	numbers := MustParseSchema(`["int", "long"]`)
+	data, err := write(numbers, int32(5))
+	assert(t, err, nil)
+	assert(t, data, []byte{0x00, 0x0A})
+	data, err = write(numbers, UnionValue{Type: "long", Value: int64(5)})
+	assert(t, err, nil)
+	assert(t, data, []byte{0x02, 0x0A})
+	data, err = write(numbers, UnionValue{Type: "int", Value: int32(5)})
+	assert(t, err, nil)
+	assert(t, data, []byte{0x00, 0x0A})
+	_, err = write(numbers, UnionValue{Type: "long", Value: int32(5)})
+	assert(t, err != nil, true)
+	_, err = write(numbers, UnionValue{Type: "double", Value: 5.0})
+	assert(t, err != nil, true)
+
+	records := MustParseSchema(`[
+		{"type": "record", "name": "Created", "namespace": "events", "fields": [{"name": "id", "type": "long"}]},
+		{"type": "record", "name": "Deleted", "namespace": "events", "fields": [{"name": "id", "type": "long"}]}
+	]`)
+	deleted := records.(*UnionSchema).Types[1]
+	record := NewGenericRecord(deleted)
+	record.Set("id", int64(1))
+	data, err = write(records, record)
+	assert(t, err, nil)
+	assert(t, data, []byte{0x00, 0x02})
+	for _, name := range []string{"Deleted", "events.Deleted"} {
+		data, err = write(records, UnionValue{Type: name, Value: record})
+		assert(t, err, nil)
+		assert(t, data, []byte{0x02, 0x02})
+	}
+
+	assert(t, ValidateDatum(numbers, UnionValue{Type: "long", Value: int64(5)}), nil)
+	assertError(t, ValidateDatum(numbers, UnionValue{Type: "long", Value: int32(5)}), InvalidDatum)
+	data, err = EncodeFromMap(`{"type": "record", "name": "Number", "fields": [{"name": "n", "type": ["int", "long"]}]}`,
+		map[string]interface{}{"n": UnionValue{Type: "long", Value: 5}})
+	assert(t, err, nil)
+	assert(t, data, []byte{0x02, 0x0A})
+}
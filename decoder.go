@@ -2,12 +2,16 @@ package avro
 
 import (
 	"encoding/binary"
+	"fmt"
+	"io"
 	"math"
+	"unsafe"
 )
 
 // Decoder is an interface that provides low-level support for deserializing Avro values.
 type Decoder interface {
-	// Reads a null value. Returns a decoded value and an error if it occurs.
+	// Reads a null value. Null values are not represented in the encoded data so implementations must not
+	// advance the reading position. Always returns a nil value.
 	ReadNull() (interface{}, error)
 
 	// Reads a boolean value. Returns a decoded value and an error if it occurs.
@@ -87,36 +91,165 @@ type DataBlock struct {
 	BlockRemaining int64
 }
 
-var max_int_buf_size = 5
-var max_long_buf_size = 10
+// Creates a new DataBlock holding a given buffer with a given number of encoded entries, e.g. the values of an Object
+// Container File block after decompression. None of the entries are read yet.
+func NewDataBlock(data []byte, count int64) *DataBlock {
+	return &DataBlock{
+		Data:           data,
+		NumEntries:     count,
+		BlockSize:      len(data),
+		BlockRemaining: count,
+	}
+}
+
+// default limits for the number of bytes a varint encoded int and long may take
+const max_int_buf_size = 5
+const max_long_buf_size = 10
+
+// the largest number of array items preallocated before reading them, as counts come from untrusted data
+const max_prealloc_items = 1024
+
+// default limits for decoded lengths of bytes, strings and fixed values and for item counts of array and map blocks
+const max_alloc_size = 1 << 30
+const max_array_elements = 1 << 24
+const max_map_entries = 1 << 24
+
+// Returns the maximum number of bytes a varint encoded int takes, which is the default limit of BinaryDecoder
+// before it returns IntOverflow and the worst-case size of an int written by BinaryEncoder.
+func MaxVarintLen32() int {
+	return max_int_buf_size
+}
+
+// Returns the maximum number of bytes a varint encoded long takes, which is the default limit of BinaryDecoder
+// before it returns LongOverflow and the worst-case size of a long written by BinaryEncoder.
+func MaxVarintLen64() int {
+	return max_long_buf_size
+}
 
 // BinaryDecoder implements Decoder and provides low-level support for deserializing Avro values.
 type BinaryDecoder struct {
-	buf []byte
-	pos int64
+	buf              []byte
+	pos              int64
+	maxIntBufSize    int
+	maxLongBufSize   int
+	strictVarints    bool
+	maxAllocSize     int64
+	maxArrayElements int64
+	maxMapEntries    int64
+	maxDepth         int
+	depth            int
 }
 
 // Creates a new BinaryDecoder to read from a given buffer.
 func NewBinaryDecoder(buf []byte) *BinaryDecoder {
-	return &BinaryDecoder{buf, 0}
+	return &BinaryDecoder{
+		buf:              buf,
+		maxIntBufSize:    max_int_buf_size,
+		maxLongBufSize:   max_long_buf_size,
+		maxAllocSize:     max_alloc_size,
+		maxArrayElements: max_array_elements,
+		maxMapEntries:    max_map_entries,
+		maxDepth:         max_depth,
+	}
+}
+
+// Sets the maximum number of bytes an encoded int value may take for this BinaryDecoder.
+// Reading a longer value returns IntOverflow. Defaults to 5.
+func (this *BinaryDecoder) SetMaxIntBufSize(size int) {
+	this.maxIntBufSize = size
+}
+
+// Sets the maximum number of bytes an encoded long value may take for this BinaryDecoder.
+// Reading a longer value returns LongOverflow. Defaults to 10.
+func (this *BinaryDecoder) SetMaxLongBufSize(size int) {
+	this.maxLongBufSize = size
+}
+
+// Sets the maximum length in bytes of a bytes, string or fixed value this BinaryDecoder reads. Reading a value
+// with a longer decoded length returns SizeLimitExceeded before anything is allocated. Defaults to 1 GiB.
+func (this *BinaryDecoder) SetMaxAllocSize(size int64) {
+	this.maxAllocSize = size
+}
+
+// Sets the maximum number of items of a single array block this BinaryDecoder reads. Reading a block with more
+// items returns SizeLimitExceeded. Defaults to 16777216.
+func (this *BinaryDecoder) SetMaxArrayElements(count int64) {
+	this.maxArrayElements = count
 }
 
-// Reads a null value. Returns a decoded value and an error if it occurs.
+// Sets the maximum number of entries of a single map block this BinaryDecoder reads. Reading a block with more
+// entries returns SizeLimitExceeded. Defaults to 16777216.
+func (this *BinaryDecoder) SetMaxMapEntries(count int64) {
+	this.maxMapEntries = count
+}
+
+// Sets how deeply records, arrays and maps may be nested in values this BinaryDecoder skips without decoding them,
+// e.g. with GenericDatumReader.Skip or ReadRaw. Skipping a value nested deeper returns MaxDepthExceeded.
+// Defaults to 256.
+func (this *BinaryDecoder) SetMaxDepth(depth int) {
+	this.maxDepth = depth
+}
+
+// Returns the maximum number of bytes an encoded int value may take for this BinaryDecoder.
+func (this *BinaryDecoder) MaxIntBufSize() int {
+	return this.maxIntBufSize
+}
+
+// Returns the maximum number of bytes an encoded long value may take for this BinaryDecoder.
+func (this *BinaryDecoder) MaxLongBufSize() int {
+	return this.maxLongBufSize
+}
+
+// Sets whether this BinaryDecoder rejects varint encoded ints and longs (including lengths and counts) that are
+// longer than necessary, e.g. 0 encoded as 0x80 0x00. Reading such a value returns NonCanonicalVarint.
+// Defaults to false.
+func (this *BinaryDecoder) SetStrictVarints(strict bool) {
+	this.strictVarints = strict
+}
+
+// Reset makes this BinaryDecoder read from a given buffer starting at position 0, allowing to reuse a single
+// decoder for many messages instead of allocating a new one for each. Like the rest of BinaryDecoder it is not
+// safe for concurrent use.
+func (this *BinaryDecoder) Reset(buf []byte) {
+	this.buf = buf
+	this.pos = 0
+	this.depth = 0
+}
+
+// Reads a null value. Null values take zero bytes in Avro binary encoding so this never changes the reading
+// position and always returns (nil, nil). It is still useful to call it for null-typed record fields and union
+// branches so that decoding logic does not need to special-case them.
 func (this *BinaryDecoder) ReadNull() (interface{}, error) {
 	return nil, nil
 }
 
 // Reads an int value. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadInt() (int32, error) {
+func (this *BinaryDecoder) ReadInt() (_ int32, err error) {
+	defer this.wrapError("ReadInt", this.pos, &err)
+	value, _, err := this.readIntWithSize()
+	return value, err
+}
+
+// Reads an int value and also returns the number of bytes its encoding took.
+// Returns a decoded value, its size in bytes and an error if it occurs.
+func (this *BinaryDecoder) ReadIntWithSize() (_ int32, _ int, err error) {
+	defer this.wrapError("ReadIntWithSize", this.pos, &err)
+	return this.readIntWithSize()
+}
+
+func (this *BinaryDecoder) readIntWithSize() (int32, int, error) {
 	if err := checkEOF(this.buf, this.pos, 1); err != nil {
-		return 0, EOF
+		return 0, 0, EOF
 	}
 	var value uint32
 	var b uint8
 	var offset int
 	for {
-		if offset == max_int_buf_size {
-			return 0, IntOverflow
+		if offset >= this.maxIntBufSize {
+			return 0, offset, IntOverflow
+		}
+		if this.pos >= int64(len(this.buf)) {
+			return 0, offset, EOF
 		}
 		b = this.buf[this.pos]
 		value |= uint32(b&0x7F) << uint(7*offset)
@@ -126,17 +259,44 @@ func (this *BinaryDecoder) ReadInt() (int32, error) {
 			break
 		}
 	}
-	return int32((value >> 1) ^ -(value & 1)), nil
+	// a last byte without any bits set only pads the encoding
+	if this.strictVarints && offset > 1 && b == 0 {
+		return 0, offset, NonCanonicalVarint
+	}
+	return int32((value >> 1) ^ -(value & 1)), offset, nil
 }
 
 // Reads a long value. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadLong() (int64, error) {
+func (this *BinaryDecoder) ReadLong() (_ int64, err error) {
+	defer this.wrapError("ReadLong", this.pos, &err)
+	return this.readLong()
+}
+
+// Reads a long value and also returns the number of bytes its encoding took.
+// Returns a decoded value, its size in bytes and an error if it occurs.
+func (this *BinaryDecoder) ReadLongWithSize() (_ int64, _ int, err error) {
+	defer this.wrapError("ReadLongWithSize", this.pos, &err)
+	return this.readLongWithSize()
+}
+
+func (this *BinaryDecoder) readLong() (int64, error) {
+	value, _, err := this.readLongWithSize()
+	return value, err
+}
+
+func (this *BinaryDecoder) readLongWithSize() (int64, int, error) {
+	if err := checkEOF(this.buf, this.pos, 1); err != nil {
+		return 0, 0, EOF
+	}
 	var value uint64
 	var b uint8
 	var offset int
 	for {
-		if offset == max_long_buf_size {
-			return 0, LongOverflow
+		if offset >= this.maxLongBufSize {
+			return 0, offset, LongOverflow
+		}
+		if this.pos >= int64(len(this.buf)) {
+			return 0, offset, EOF
 		}
 		b = this.buf[this.pos]
 		value |= uint64(b&0x7F) << uint(7*offset)
@@ -146,53 +306,78 @@ func (this *BinaryDecoder) ReadLong() (int64, error) {
 			break
 		}
 	}
-	return int64((value >> 1) ^ -(value & 1)), nil
+	// a last byte without any bits set only pads the encoding
+	if this.strictVarints && offset > 1 && b == 0 {
+		return 0, offset, NonCanonicalVarint
+	}
+	return int64((value >> 1) ^ -(value & 1)), offset, nil
 }
 
 // Reads a string value. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadString() (string, error) {
-	if err := checkEOF(this.buf, this.pos, 1); err != nil {
+func (this *BinaryDecoder) ReadString() (_ string, err error) {
+	defer this.wrapError("ReadString", this.pos, &err)
+	bytes, err := this.readStringBytes()
+	if err != nil {
 		return "", err
 	}
-	length, err := this.ReadLong()
+	return string(bytes), nil
+}
+
+// Reads a string value without copying it, so the returned string points directly into the decoder's buffer.
+// The caller must guarantee that the buffer outlives the string and is never modified afterwards: mutating the
+// buffer (e.g. reusing it for the next message) silently changes or corrupts every string returned by this method.
+// Returns a decoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadStringUnsafe() (_ string, err error) {
+	defer this.wrapError("ReadStringUnsafe", this.pos, &err)
+	bytes, err := this.readStringBytes()
+	if err != nil || len(bytes) == 0 {
+		return "", err
+	}
+	return *(*string)(unsafe.Pointer(&bytes)), nil
+}
+
+// reads a string value and returns the slice of the buffer holding it
+func (this *BinaryDecoder) readStringBytes() ([]byte, error) {
+	if err := checkEOF(this.buf, this.pos, 1); err != nil {
+		return nil, err
+	}
+	length, err := this.readLong()
 	if err != nil || length < 0 {
-		return "", InvalidStringLength
+		return nil, InvalidStringLength
 	}
-	if err := checkEOF(this.buf, this.pos, int(length)); err != nil {
-		return "", err
+	if err := checkEOF(this.buf, this.pos, length); err != nil {
+		return nil, UnexpectedEOF
+	}
+	if length > this.maxAllocSize {
+		return nil, SizeLimitExceeded
 	}
-	value := string(this.buf[this.pos : this.pos+length])
+	bytes := this.buf[this.pos : this.pos+length]
 	this.pos += length
-	return value, nil
+	return bytes, nil
 }
 
-// Reads a boolean value. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadBoolean() (bool, error) {
+// Reads a boolean value. Returns a decoded value and an error if it occurs. A byte other than 0x00 or 0x01 gives
+// an InvalidBoolError, the position still moves past that byte.
+func (this *BinaryDecoder) ReadBoolean() (_ bool, err error) {
+	defer this.wrapError("ReadBoolean", this.pos, &err)
+	if err := checkEOF(this.buf, this.pos, 1); err != nil {
+		return false, EOF
+	}
 	b := this.buf[this.pos] & 0xFF
 	this.pos++
-	var err error = nil
 	if b != 0 && b != 1 {
-		err = InvalidBool
+		err = &InvalidBoolError{Value: b, Pos: this.pos - 1}
 	}
 	return b == 1, err
 }
 
 // Reads a bytes value. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadBytes() ([]byte, error) {
-	//TODO make something with these if's!!
-	if err := checkEOF(this.buf, this.pos, 1); err != nil {
-		return nil, EOF
-	}
-	length, err := this.ReadLong()
+func (this *BinaryDecoder) ReadBytes() (_ []byte, err error) {
+	defer this.wrapError("ReadBytes", this.pos, &err)
+	length, err := this.readBytesLength()
 	if err != nil {
 		return nil, err
 	}
-	if length < 0 {
-		return nil, NegativeBytesLength
-	}
-	if err := checkEOF(this.buf, this.pos, int(length)); err != nil {
-		return nil, EOF
-	}
 
 	bytes := make([]byte, length)
 	copy(bytes[:], this.buf[this.pos:this.pos+length])
@@ -200,12 +385,76 @@ func (this *BinaryDecoder) ReadBytes() ([]byte, error) {
 	return bytes, err
 }
 
+// Reads a bytes value into dst, reusing its backing array when its capacity is sufficient and allocating a new
+// slice otherwise. The returned slice may alias dst, so dst should not be used independently afterwards.
+// Returns a decoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadBytesInto(dst []byte) (_ []byte, err error) {
+	defer this.wrapError("ReadBytesInto", this.pos, &err)
+	length, err := this.readBytesLength()
+	if err != nil {
+		return dst, err
+	}
+
+	if int64(cap(dst)) >= length {
+		dst = dst[:length]
+	} else {
+		dst = make([]byte, length)
+	}
+	copy(dst, this.buf[this.pos:this.pos+length])
+	this.pos += length
+	return dst, nil
+}
+
+// Reads a bytes value and writes it to a given io.Writer instead of returning it, so that large values can be passed
+// on without being copied. The reading position moves past the value even if writing fails.
+// Returns the number of bytes written and an error if it occurs.
+func (this *BinaryDecoder) ReadBytesTo(w io.Writer) (_ int64, err error) {
+	defer this.wrapError("ReadBytesTo", this.pos, &err)
+	length, err := this.readBytesLength()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(this.buf[this.pos : this.pos+length])
+	this.pos += length
+	return int64(n), err
+}
+
+// reads the length of a bytes value and checks that the value itself is not truncated
+func (this *BinaryDecoder) readBytesLength() (int64, error) {
+	//TODO make something with these if's!!
+	if err := checkEOF(this.buf, this.pos, 1); err != nil {
+		return 0, EOF
+	}
+	length, err := this.readLong()
+	if err == EOF {
+		return 0, UnexpectedEOF
+	}
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, NegativeBytesLength
+	}
+	if err := checkEOF(this.buf, this.pos, length); err != nil {
+		return 0, UnexpectedEOF
+	}
+	if length > this.maxAllocSize {
+		return 0, SizeLimitExceeded
+	}
+	return length, nil
+}
+
 // Reads a float value. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadFloat() (float32, error) {
+func (this *BinaryDecoder) ReadFloat() (_ float32, err error) {
+	defer this.wrapError("ReadFloat", this.pos, &err)
 	var float float32
-	if err := checkEOF(this.buf, this.pos, 4); err != nil {
+	if err := checkEOF(this.buf, this.pos, 1); err != nil {
 		return float, err
 	}
+	if err := checkEOF(this.buf, this.pos, 4); err != nil {
+		return float, UnexpectedEOF
+	}
 	bits := binary.LittleEndian.Uint32(this.buf[this.pos : this.pos+4])
 	float = math.Float32frombits(bits)
 	this.pos += 4
@@ -213,61 +462,340 @@ func (this *BinaryDecoder) ReadFloat() (float32, error) {
 }
 
 // Reads a double value. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadDouble() (float64, error) {
+func (this *BinaryDecoder) ReadDouble() (_ float64, err error) {
+	defer this.wrapError("ReadDouble", this.pos, &err)
 	var double float64
-	if err := checkEOF(this.buf, this.pos, 8); err != nil {
+	if err := checkEOF(this.buf, this.pos, 1); err != nil {
 		return double, err
 	}
+	if err := checkEOF(this.buf, this.pos, 8); err != nil {
+		return double, UnexpectedEOF
+	}
 	bits := binary.LittleEndian.Uint64(this.buf[this.pos : this.pos+8])
 	double = math.Float64frombits(bits)
 	this.pos += 8
 	return double, nil
 }
 
+// Reads a float value like ReadFloat() but returns NonFiniteFloat if the decoded value is NaN or infinite,
+// for callers that treat such values as corrupted data.
+func (this *BinaryDecoder) ReadFloatStrict() (_ float32, err error) {
+	defer this.wrapError("ReadFloatStrict", this.pos, &err)
+	float, err := this.ReadFloat()
+	if err != nil {
+		return float, err
+	}
+	if math.IsNaN(float64(float)) || math.IsInf(float64(float), 0) {
+		return float, NonFiniteFloat
+	}
+	return float, nil
+}
+
+// Reads a double value like ReadDouble() but returns NonFiniteFloat if the decoded value is NaN or infinite,
+// for callers that treat such values as corrupted data.
+func (this *BinaryDecoder) ReadDoubleStrict() (_ float64, err error) {
+	defer this.wrapError("ReadDoubleStrict", this.pos, &err)
+	double, err := this.ReadDouble()
+	if err != nil {
+		return double, err
+	}
+	if math.IsNaN(double) || math.IsInf(double, 0) {
+		return double, NonFiniteFloat
+	}
+	return double, nil
+}
+
 // Reads an enum value (which is an Avro int value). Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadEnum() (int32, error) {
-	return this.ReadInt()
+func (this *BinaryDecoder) ReadEnum() (_ int32, err error) {
+	defer this.wrapError("ReadEnum", this.pos, &err)
+	value, _, err := this.readIntWithSize()
+	return value, err
+}
+
+// Reads an enum value and returns the symbol at its index in a given list of enum symbols, for callers that know the
+// symbols of the enum without having its schema. Returns the decoded symbol, EnumIndexOutOfRange if the index does
+// not refer to any of the symbols or an error if it occurs.
+func (this *BinaryDecoder) ReadEnumWith(symbols []string) (_ string, err error) {
+	defer this.wrapError("ReadEnumWith", this.pos, &err)
+	index, err := readEnumIndex(symbols, this)
+	if err != nil {
+		return "", err
+	}
+	return symbols[index], nil
 }
 
 // Reads and returns the size of the first block of an array. If call to this return non-zero, then the caller
 // should read the indicated number of items and then call ArrayNext() to find out the number of items in the
 // next block. Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadArrayStart() (int64, error) {
-	return this.readItemCount()
+func (this *BinaryDecoder) ReadArrayStart() (_ int64, err error) {
+	defer this.wrapError("ReadArrayStart", this.pos, &err)
+	return this.readItemCount(this.maxArrayElements)
 }
 
 // Processes the next block of an array and returns the number of items in the block.
 // Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ArrayNext() (int64, error) {
-	return this.readItemCount()
+func (this *BinaryDecoder) ArrayNext() (_ int64, err error) {
+	defer this.wrapError("ArrayNext", this.pos, &err)
+	return this.readItemCount(this.maxArrayElements)
 }
 
 // Reads and returns the size of the first block of map entries. If call to this return non-zero, then the caller
 // should read the indicated number of items and then call MapNext() to find out the number of items in the
 // next block. Usage is similar to ReadArrayStart(). Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) ReadMapStart() (int64, error) {
-	return this.readItemCount()
+func (this *BinaryDecoder) ReadMapStart() (_ int64, err error) {
+	defer this.wrapError("ReadMapStart", this.pos, &err)
+	return this.readItemCount(this.maxMapEntries)
 }
 
 // Processes the next block of map entries and returns the number of items in the block.
 // Returns a decoded value and an error if it occurs.
-func (this *BinaryDecoder) MapNext() (int64, error) {
-	return this.readItemCount()
+func (this *BinaryDecoder) MapNext() (_ int64, err error) {
+	defer this.wrapError("MapNext", this.pos, &err)
+	return this.readItemCount(this.maxMapEntries)
+}
+
+// Reads the size of the first block of an array like ReadArrayStart() and also returns the size of the block in
+// bytes if the writer provided it (blocks with negative item counts), or -1 otherwise. The byte size allows
+// skipping the whole block with Seek without decoding its items.
+func (this *BinaryDecoder) ReadArrayStartWithSize() (_ int64, _ int64, err error) {
+	defer this.wrapError("ReadArrayStartWithSize", this.pos, &err)
+	return this.readLimitedItemCount(this.maxArrayElements)
+}
+
+// Processes the next block of an array like ArrayNext() and also returns the size of the block in bytes if the
+// writer provided it, or -1 otherwise.
+func (this *BinaryDecoder) ArrayNextWithSize() (_ int64, _ int64, err error) {
+	defer this.wrapError("ArrayNextWithSize", this.pos, &err)
+	return this.readLimitedItemCount(this.maxArrayElements)
+}
+
+// Reads the size of the first block of map entries like ReadMapStart() and also returns the size of the block in
+// bytes if the writer provided it (blocks with negative item counts), or -1 otherwise. The byte size allows
+// skipping the whole block with Seek without decoding its entries.
+func (this *BinaryDecoder) ReadMapStartWithSize() (_ int64, _ int64, err error) {
+	defer this.wrapError("ReadMapStartWithSize", this.pos, &err)
+	return this.readLimitedItemCount(this.maxMapEntries)
+}
+
+// Processes the next block of map entries like MapNext() and also returns the size of the block in bytes if the
+// writer provided it, or -1 otherwise.
+func (this *BinaryDecoder) MapNextWithSize() (_ int64, _ int64, err error) {
+	defer this.wrapError("MapNextWithSize", this.pos, &err)
+	return this.readLimitedItemCount(this.maxMapEntries)
+}
+
+// Reads the branch index of a union value with a given number of branches. Returns UnionIndexOutOfRange if the
+// decoded index is not within [0, branchCount) or an error if it occurs.
+func (this *BinaryDecoder) ReadUnionIndex(branchCount int) (_ int, err error) {
+	defer this.wrapError("ReadUnionIndex", this.pos, &err)
+	index, err := this.readLong()
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= int64(branchCount) {
+		return 0, UnionIndexOutOfRange
+	}
+	return int(index), nil
 }
 
 // Reads fixed sized binary object into the provided buffer.
 // Returns an error if it occurs.
-func (this *BinaryDecoder) ReadFixed(bytes []byte) error {
+func (this *BinaryDecoder) ReadFixed(bytes []byte) (err error) {
+	defer this.wrapError("ReadFixed", this.pos, &err)
 	return this.readBytes(bytes, 0, len(bytes))
 }
 
 // Reads fixed sized binary object into the provided buffer.
 // The second parameter is the position where the data needs to be written, the third is the size of binary object.
-// Returns an error if it occurs.
-func (this *BinaryDecoder) ReadFixedWithBounds(bytes []byte, start int, length int) error {
+// Returns InvalidBounds if the object does not fit into the buffer at the given position or an error if it occurs.
+func (this *BinaryDecoder) ReadFixedWithBounds(bytes []byte, start int, length int) (err error) {
+	defer this.wrapError("ReadFixedWithBounds", this.pos, &err)
 	return this.readBytes(bytes, start, length)
 }
 
+// Reads a fixed sized binary object of a given size into a newly allocated slice.
+// Returns the slice and an error if it occurs.
+func (this *BinaryDecoder) ReadFixedAlloc(size int) (_ []byte, err error) {
+	defer this.wrapError("ReadFixedAlloc", this.pos, &err)
+	if size < 0 {
+		return nil, NegativeBytesLength
+	}
+	if err := checkEOF(this.buf, this.pos, int64(size)); err != nil {
+		return nil, EOF
+	}
+	if int64(size) > this.maxAllocSize {
+		return nil, SizeLimitExceeded
+	}
+	fixed := make([]byte, size)
+	copy(fixed, this.buf[this.pos:])
+	this.pos += int64(size)
+	return fixed, nil
+}
+
+// Skips an int value without decoding it. Returns an error if it occurs.
+func (this *BinaryDecoder) SkipInt() (err error) {
+	defer this.wrapError("SkipInt", this.pos, &err)
+	_, _, err = this.readIntWithSize()
+	return err
+}
+
+// Skips a long value without decoding it. Returns an error if it occurs.
+func (this *BinaryDecoder) SkipLong() (err error) {
+	defer this.wrapError("SkipLong", this.pos, &err)
+	_, _, err = this.readLongWithSize()
+	return err
+}
+
+// Skips a float value without decoding it. Returns an error if it occurs.
+func (this *BinaryDecoder) SkipFloat() error {
+	return this.SkipFixed(4)
+}
+
+// Skips a double value without decoding it. Returns an error if it occurs.
+func (this *BinaryDecoder) SkipDouble() error {
+	return this.SkipFixed(8)
+}
+
+// Skips a bytes value by reading its length and moving past the payload without allocating it.
+// Returns an error if it occurs.
+func (this *BinaryDecoder) SkipBytes() (err error) {
+	defer this.wrapError("SkipBytes", this.pos, &err)
+	length, err := this.readLong()
+	if err != nil {
+		return err
+	}
+	if length < 0 {
+		return NegativeBytesLength
+	}
+	return this.skip(length)
+}
+
+// Skips a string value by reading its length and moving past the payload without allocating it.
+// Returns an error if it occurs.
+func (this *BinaryDecoder) SkipString() (err error) {
+	defer this.wrapError("SkipString", this.pos, &err)
+	length, err := this.readLong()
+	if err != nil || length < 0 {
+		return InvalidStringLength
+	}
+	return this.skip(length)
+}
+
+// Skips a fixed sized binary object of a given size. Returns an error if it occurs.
+func (this *BinaryDecoder) SkipFixed(size int) (err error) {
+	defer this.wrapError("SkipFixed", this.pos, &err)
+	if size < 0 {
+		return NegativeBytesLength
+	}
+	return this.skip(int64(size))
+}
+
+// Skips an array with items of a given schema. Blocks that provide their size in bytes are skipped at once, the
+// items of other blocks are skipped one by one. Returns an error if it occurs.
+func (this *BinaryDecoder) SkipArray(itemSchema Schema) (err error) {
+	defer this.wrapError("SkipArray", this.pos, &err)
+	return this.skipBlocks(func() error {
+		return this.skipValue(itemSchema)
+	})
+}
+
+// Skips a map with values of a given schema. Blocks that provide their size in bytes are skipped at once, the
+// entries of other blocks are skipped one by one. Returns an error if it occurs.
+func (this *BinaryDecoder) SkipMap(valueSchema Schema) (err error) {
+	defer this.wrapError("SkipMap", this.pos, &err)
+	return this.skipBlocks(func() error {
+		if err := this.SkipString(); err != nil {
+			return err
+		}
+		return this.skipValue(valueSchema)
+	})
+}
+
+func (this *BinaryDecoder) skipBlocks(skipItem func() error) error {
+	for {
+		count, blockSize, err := this.readItemCountWithSize()
+		if err != nil || count == 0 {
+			return err
+		}
+		if blockSize >= 0 {
+			if err := this.skip(blockSize); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := int64(0); i < count; i++ {
+			if err := skipItem(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skips a value of any schema
+func (this *BinaryDecoder) skipValue(schema Schema) error {
+	switch schema.(type) {
+	case *ArraySchema, *MapSchema, *RecordSchema:
+		if err := this.enterNested(); err != nil {
+			return err
+		}
+		defer this.leaveNested()
+	}
+
+	switch s := schema.(type) {
+	case *NullSchema:
+		return nil
+	case *BooleanSchema:
+		return this.SkipFixed(1)
+	case *IntSchema, *EnumSchema:
+		return this.SkipInt()
+	case *LongSchema:
+		return this.SkipLong()
+	case *FloatSchema:
+		return this.SkipFloat()
+	case *DoubleSchema:
+		return this.SkipDouble()
+	case *BytesSchema:
+		return this.SkipBytes()
+	case *StringSchema:
+		return this.SkipString()
+	case *FixedSchema:
+		return this.SkipFixed(s.Size)
+	case *ArraySchema:
+		return this.SkipArray(s.Items)
+	case *MapSchema:
+		return this.SkipMap(s.Values)
+	case *UnionSchema:
+		index, err := this.ReadUnionIndex(len(s.Types))
+		if err != nil {
+			return err
+		}
+		return this.skipValue(s.Types[index])
+	case *RecordSchema:
+		for _, field := range s.Fields {
+			if err := this.skipValue(field.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *RecursiveSchema:
+		return this.skipValue(s.Actual)
+	}
+	return fmt.Errorf("Unknown field type: %d", schema.Type())
+}
+
+// enters a record, array or map while walking over a value, returns MaxDepthExceeded if they are nested too deeply
+func (this *BinaryDecoder) enterNested() error {
+	if this.depth >= this.maxDepth {
+		return MaxDepthExceeded
+	}
+	this.depth++
+	return nil
+}
+
+func (this *BinaryDecoder) leaveNested() {
+	this.depth--
+}
+
 // SetBlock is used for Avro Object Container Files where the data is split in blocks and sets a data block
 // for this decoder and sets the position to the start of this block.
 func (this *BinaryDecoder) SetBlock(block *DataBlock) {
@@ -280,39 +808,179 @@ func (this *BinaryDecoder) Seek(pos int64) {
 	this.pos = pos
 }
 
+// Sets the reading position of this BinaryDecoder like Seek but validates it first.
+// Returns InvalidSeek and leaves the position unchanged if the position is outside of the underlying buffer.
+// Seeking to the end of the buffer is allowed.
+func (this *BinaryDecoder) SeekChecked(pos int64) error {
+	if pos < 0 || pos > int64(len(this.buf)) {
+		return InvalidSeek
+	}
+	this.pos = pos
+	return nil
+}
+
+// Moves the reading position of this BinaryDecoder by a given number of bytes forward or backward if negative.
+// Returns InvalidSeek and leaves the position unchanged if the new position is outside of the underlying buffer.
+func (this *BinaryDecoder) SeekRelative(delta int64) error {
+	return this.SeekChecked(this.pos + delta)
+}
+
 // Tell returns the current reading position of this Decoder.
 func (this *BinaryDecoder) Tell() int64 {
 	return this.pos
 }
 
-func checkEOF(buf []byte, pos int64, length int) error {
-	if int64(len(buf)) < pos+int64(length) {
+// Returns the current reading position of this BinaryDecoder to return to later with Restore.
+func (this *BinaryDecoder) Mark() int64 {
+	return this.pos
+}
+
+// Returns the reading position of this BinaryDecoder to a position previously returned by Mark.
+// Returns InvalidSeek and leaves the position unchanged if the mark is outside of the underlying buffer.
+func (this *BinaryDecoder) Restore(mark int64) error {
+	return this.SeekChecked(mark)
+}
+
+// Calls a given function and restores the reading position of this BinaryDecoder to where it was before the call
+// if the function returns an error, so that a failed speculative read leaves the decoder untouched.
+// Returns the error of the function.
+func (this *BinaryDecoder) WithRollback(fn func() error) error {
+	mark := this.Mark()
+	err := fn()
+	if err != nil {
+		this.pos = mark
+	}
+	return err
+}
+
+// Returns the number of bytes left to read after the current reading position of this BinaryDecoder.
+// Returns 0 if the position was set past the end of the underlying buffer.
+func (this *BinaryDecoder) Remaining() int64 {
+	if remaining := int64(len(this.buf)) - this.pos; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Checks whether all bytes of the underlying buffer were read, e.g. to make sure there is no trailing data after
+// a decoded value.
+func (this *BinaryDecoder) AtEnd() bool {
+	return this.Remaining() == 0
+}
+
+// Returns the next n bytes of this BinaryDecoder without moving the reading position, e.g. to detect a message
+// framing before decoding. The returned slice aliases the underlying buffer, so it must not be modified and should
+// be copied if it is kept while the buffer is reused. Returns EOF if fewer than n bytes are left.
+func (this *BinaryDecoder) Peek(n int) (_ []byte, err error) {
+	defer this.wrapError("Peek", this.pos, &err)
+	if n < 0 {
+		return nil, NegativeBytesLength
+	}
+	if err := checkEOF(this.buf, this.pos, int64(n)); err != nil {
+		return nil, err
+	}
+	return this.buf[this.pos : this.pos+int64(n) : this.pos+int64(n)], nil
+}
+
+// Reads a zig-zag encoded long value directly from a given io.ByteReader, consuming only the bytes of that value.
+// Returns EOF if the reader ends before the value is complete and LongOverflow if the value is too long.
+func ReadLongFromReader(r io.ByteReader) (int64, error) {
+	var value uint64
+	var offset int
+	for {
+		if offset == max_long_buf_size {
+			return 0, LongOverflow
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, EOF
+		}
+		value |= uint64(b&0x7F) << uint(7*offset)
+		offset++
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return int64((value >> 1) ^ -(value & 1)), nil
+}
+
+// wraps an error returned by a BinaryDecoder method into a DecodeError unless it is one already
+func (this *BinaryDecoder) wrapError(op string, pos int64, err *error) {
+	if *err == nil {
+		return
+	}
+	if _, ok := (*err).(*DecodeError); !ok {
+		*err = &DecodeError{Op: op, Pos: pos, Err: *err}
+	}
+}
+
+// checks whether there are at least length bytes left after pos, comparing against the remaining size so that
+// huge lengths can not overflow the arithmetic
+func checkEOF(buf []byte, pos int64, length int64) error {
+	if pos < 0 || int64(len(buf))-pos < length {
 		return EOF
 	}
 	return nil
 }
 
-func (this *BinaryDecoder) readItemCount() (int64, error) {
-	if count, err := this.ReadLong(); err != nil {
-		return 0, err
-	} else {
-		if count < 0 {
-			this.ReadLong()
-			count = -count
-		}
-		return count, err
+func (this *BinaryDecoder) readItemCount(limit int64) (int64, error) {
+	count, _, err := this.readLimitedItemCount(limit)
+	return count, err
+}
+
+// reads the item count and size of a block like readItemCountWithSize but fails if there are more items than a given
+// limit
+func (this *BinaryDecoder) readLimitedItemCount(limit int64) (int64, int64, error) {
+	count, blockSize, err := this.readItemCountWithSize()
+	if err == nil && count > limit {
+		return 0, 0, SizeLimitExceeded
+	}
+	return count, blockSize, err
+}
+
+func (this *BinaryDecoder) readItemCountWithSize() (int64, int64, error) {
+	count, err := this.readLong()
+	if err != nil {
+		return 0, 0, err
 	}
+	if count >= 0 {
+		return count, -1, nil
+	}
+	blockSize, err := this.readLong()
+	if err != nil {
+		return 0, 0, err
+	}
+	return -count, blockSize, nil
 }
 
 func (this *BinaryDecoder) readBytes(bytes []byte, start int, length int) error {
-	if length < 0 {
-		return NegativeBytesLength
+	if err := checkBounds(bytes, start, length); err != nil {
+		return err
 	}
-	if err := checkEOF(this.buf, this.pos, int(start+length)); err != nil {
+	if err := checkEOF(this.buf, this.pos, int64(length)); err != nil {
 		return EOF
 	}
-	copy(bytes[:], this.buf[this.pos+int64(start):this.pos+int64(start)+int64(length)])
+	copy(bytes[start:start+length], this.buf[this.pos:this.pos+int64(length)])
 	this.pos += int64(length)
 
 	return nil
 }
+
+// checks that length bytes starting at start fit into the given destination buffer
+func checkBounds(bytes []byte, start int, length int) error {
+	if length < 0 {
+		return NegativeBytesLength
+	}
+	if start < 0 || start > len(bytes) || len(bytes)-start < length {
+		return InvalidBounds
+	}
+	return nil
+}
+
+func (this *BinaryDecoder) skip(length int64) error {
+	if err := checkEOF(this.buf, this.pos, length); err != nil {
+		return err
+	}
+	this.pos += length
+	return nil
+}
@@ -0,0 +1,43 @@
+package avro
+
+import "sync"
+
+// DecoderPool hands out reusable BinaryDecoders to save allocating a new one per message, e.g. in a server decoding
+// concurrent requests. It is safe for concurrent use, the decoders themselves are not.
+type DecoderPool struct {
+	pool sync.Pool
+}
+
+// Creates a new empty DecoderPool.
+func NewDecoderPool() *DecoderPool {
+	return &DecoderPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return NewBinaryDecoder(nil)
+			},
+		},
+	}
+}
+
+// Returns a BinaryDecoder from this DecoderPool that reads from a given buffer starting at position 0 with the
+// default limits and options, no matter how the decoder was used before it was put back.
+func (this *DecoderPool) Get(buf []byte) *BinaryDecoder {
+	dec := this.pool.Get().(*BinaryDecoder)
+	dec.Reset(buf)
+	dec.maxIntBufSize = max_int_buf_size
+	dec.maxLongBufSize = max_long_buf_size
+	dec.strictVarints = false
+	dec.maxAllocSize = max_alloc_size
+	dec.maxArrayElements = max_array_elements
+	dec.maxMapEntries = max_map_entries
+	dec.maxDepth = max_depth
+	return dec
+}
+
+// Returns a BinaryDecoder to this DecoderPool for reuse. The decoder must not be used after that, and nothing read
+// without copying (e.g. strings from ReadStringUnsafe) may be used once the buffer it came from is reused.
+func (this *DecoderPool) Put(dec *BinaryDecoder) {
+	// drops the reference to the buffer so that the pool does not keep it alive
+	dec.Reset(nil)
+	this.pool.Put(dec)
+}
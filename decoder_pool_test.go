@@ -0,0 +1,69 @@
+package avro
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDecoderPool(t *testing.T) {
+	pool := NewDecoderPool()
+	dec := pool.Get([]byte{0x02, 0x04})
+	dec.SetMaxIntBufSize(1)
+	dec.SetStrictVarints(true)
+	dec.SetMaxAllocSize(0)
+	dec.ReadInt()
+	pool.Put(dec)
+	assert(t, dec.buf, []byte(nil))
+
+	dec = pool.Get([]byte{0x80, 0x01, 0x82, 0x00})
+	assert(t, dec.Tell(), int64(0))
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(64))
+	value, err = dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(1))
+	assert(t, dec.maxAllocSize, int64(max_alloc_size))
+}
+
+func TestDecoderPoolConcurrent(t *testing.T) {
+	pool := NewDecoderPool()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				buf := &bytes.Buffer{}
+				enc := NewBinaryEncoder(buf)
+				enc.WriteInt(int32(worker))
+				enc.WriteLong(int64(i))
+
+				dec := pool.Get(buf.Bytes())
+				w, err := dec.ReadInt()
+				if err == nil && w != int32(worker) {
+					err = fmt.Errorf("Expected worker %d, actual %d", worker, w)
+				}
+				if err == nil {
+					var n int64
+					if n, err = dec.ReadLong(); err == nil && n != int64(i) {
+						err = fmt.Errorf("Expected value %d, actual %d", i, n)
+					}
+				}
+				pool.Put(dec)
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
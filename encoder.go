@@ -6,6 +6,9 @@ import (
 	"math"
 )
 
+// the longest a zig-zag encoded long can get as a varint
+const max_varint_size = 10
+
 // Encoder is an interface that provides low-level support for serializing Avro values.
 type Encoder interface {
 	// Writes a null value. Doesn't actually do anything but may advance the state of Encoder implementation if it
@@ -52,6 +55,7 @@ type Encoder interface {
 	// Writes raw bytes to this Encoder.
 	WriteRaw([]byte)
 
+	// Tell returns the number of bytes written so far.
 	Tell() int64
 }
 
@@ -60,16 +64,33 @@ type BinaryEncoder struct {
 	buffer *bytes.Buffer
 }
 
-// Creates a new BinaryEncoder that will write to a given buffer.
+// Creates a new BinaryEncoder that will write to a given buffer. If the buffer is nil the encoder allocates its own,
+// which grows as needed and is accessed with Bytes.
 func NewBinaryEncoder(buffer *bytes.Buffer) *BinaryEncoder {
+	if buffer == nil {
+		buffer = &bytes.Buffer{}
+	}
 	return &BinaryEncoder{buffer: buffer}
 }
 
+// Returns the bytes written to this BinaryEncoder since it was created or last reset. The slice is only valid until
+// the next write or Reset, copy it to keep it longer.
+func (this *BinaryEncoder) Bytes() []byte {
+	return this.buffer.Bytes()
+}
+
+// Resets this BinaryEncoder so that it writes the next value from the start of its buffer, keeping the allocated
+// memory for reuse, e.g. when encoding one message after another or taking encoders from a sync.Pool.
+func (this *BinaryEncoder) Reset() {
+	this.buffer.Reset()
+}
+
 // Writes a null value. Doesn't actually do anything in this implementation.
 func (this *BinaryEncoder) WriteNull(_ interface{}) {
 	//do nothing
 }
 
+// Tell returns the number of bytes written to the underlying buffer so far.
 func (this *BinaryEncoder) Tell() int64 {
 	return int64(this.buffer.Len())
 }
@@ -107,7 +128,9 @@ func (this *BinaryEncoder) WriteDouble(x float64) {
 	this.buffer.Write(bytes)
 }
 
-// Writes raw bytes to this Encoder.
+// Writes raw bytes to this Encoder, e.g. a value already encoded in Avro binary format such as the Bytes of a
+// RawDatum. The bytes are appended verbatim and count towards the encoded length like any other value, so blocks of
+// a DataFileWriter are sized and framed correctly.
 func (this *BinaryEncoder) WriteRaw(x []byte) {
 	this.buffer.Write(x)
 }
@@ -153,7 +176,13 @@ func (this *BinaryEncoder) writeItemCount(count int64) {
 }
 
 func (this *BinaryEncoder) encodeVarint(x int64) []byte {
-	var buf = make([]byte, 10)
+	var buf = make([]byte, max_varint_size)
+	return buf[0:putVarint(buf, x)]
+}
+
+// writes a given long zig-zag encoded as a varint to a given buffer of at least max_varint_size bytes and returns
+// the number of bytes written
+func putVarint(buf []byte, x int64) int {
 	ux := uint64(x) << 1
 	if x < 0 {
 		ux = ^ux
@@ -166,5 +195,5 @@ func (this *BinaryEncoder) encodeVarint(x int64) []byte {
 	}
 	buf[i] = byte(ux)
 
-	return buf[0 : i+1]
+	return i + 1
 }
@@ -1,10 +1,28 @@
 package avro
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
-// Signals that an end of file or stream has been reached unexpectedly.
+// Signals that an end of file or stream has been reached before reading a value.
 var EOF = errors.New("End of file reached")
 
+// Happens when data ends in the middle of a value, e.g. when bytes, strings or floating point values are truncated.
+var UnexpectedEOF = errors.New("Unexpected end of file in the middle of a value")
+
+// Happens when a varint encoded value is longer than necessary and the decoder rejects non-canonical encodings.
+var NonCanonicalVarint = errors.New("Non-canonical varint encoding")
+
+// Happens when a map contains a key more than once and the reader rejects duplicate keys.
+var DuplicateMapKey = errors.New("Duplicate map key")
+
+// Happens when a value to write does not conform to its schema.
+var InvalidDatum = errors.New("Invalid datum")
+
+// Happens when raw values are read from a Decoder that does not give access to the encoded bytes.
+var RawDecoderRequired = errors.New("Reading raw values requires a BinaryDecoder")
+
 // Happens when the given value to decode overflows maximum int32 value.
 var IntOverflow = errors.New("Overflowed an int value")
 
@@ -14,6 +32,10 @@ var LongOverflow = errors.New("Overflowed a long value")
 // Happens when given value to decode as bytes has negative length.
 var NegativeBytesLength = errors.New("Negative bytes length")
 
+// Happens when a decoded length or item count exceeds the limit set on the decoder, e.g. a corrupted bytes length that
+// would otherwise allocate gigabytes.
+var SizeLimitExceeded = errors.New("Size limit exceeded")
+
 // Happens when given value to decode as bool is neither 0x00 nor 0x01.
 var InvalidBool = errors.New("Invalid bool value")
 
@@ -23,8 +45,32 @@ var InvalidStringLength = errors.New("Invalid string length")
 // Indicates the given file to decode does not correspond to Avro data file format.
 var NotAvroFile = errors.New("Not an Avro data file")
 
-// Happens when file header's sync and block's sync do not match - indicates corrupted data.
-var InvalidSync = errors.New("Invalid sync")
+// Happens when file header's sync and block's sync do not match - indicates corrupted data or a misaligned read.
+var SyncMismatch = errors.New("Sync marker mismatch")
+
+// Deprecated: use SyncMismatch, which this is an alias of.
+var InvalidSync = SyncMismatch
+
+// Happens when a data file is compressed with a codec that is not supported.
+var UnsupportedCodec = errors.New("Unsupported codec")
+
+// Happens when the avro.codec metadata of a data file names an unknown codec. An alias of UnsupportedCodec.
+var UnknownCodec = UnsupportedCodec
+
+// Happens when custom data file metadata uses a key from the avro. namespace reserved for the spec.
+var ReservedMetadataKey = errors.New("Metadata keys starting with avro. are reserved")
+
+// Happens when setting data file metadata after the file header has been written.
+var HeaderAlreadyWritten = errors.New("Data file header already written")
+
+// Happens when writing a data file with a codec that can only decompress data, e.g. bzip2.
+var CodecEncodeUnsupported = errors.New("Codec does not support encoding")
+
+// Happens when a data block compressed with the snappy codec is malformed.
+var InvalidSnappyData = errors.New("Invalid snappy data")
+
+// Happens when the CRC32 checksum of a data block compressed with the snappy codec does not match its data.
+var SnappyCRCMismatch = errors.New("Snappy block CRC32 mismatch")
 
 // Happens when trying to read next block without finishing the previous one.
 var BlockNotFinished = errors.New("Block read is unfinished")
@@ -43,3 +89,114 @@ var InvalidSchema = errors.New("Invalid schema")
 
 // Happens when a datum reader has no set schema.
 var SchemaNotSet = errors.New("Schema not set")
+
+// Happens when trying to move the reading position of a decoder to a place it cannot reach.
+var InvalidSeek = errors.New("Invalid seek position")
+
+// Happens when a value of uuid logical type is not a canonical hyphenated UUID string.
+var InvalidUUID = errors.New("Invalid UUID")
+
+// Happens when a float or double value read in strict mode is NaN or infinite.
+var NonFiniteFloat = errors.New("Non-finite float value")
+
+// Happens when a position and length given to read a fixed value do not fit into the destination buffer.
+var InvalidBounds = errors.New("Invalid bounds for destination buffer")
+
+// Happens when a decoded union branch index does not refer to any of the union types.
+var UnionIndexOutOfRange = errors.New("Union index out of range")
+
+// Happens when a decoded enum index does not refer to any of the enum symbols.
+var EnumIndexOutOfRange = errors.New("Enum index out of range")
+
+// Happens when a writer schema numeric type cannot be promoted to the reader schema type, e.g. double to int.
+var IncompatiblePromotion = errors.New("Incompatible type promotion")
+
+// Happens when a writer enum symbol is not a symbol of the reader enum and the reader enum has no default.
+var UnknownEnumSymbol = errors.New("Unknown enum symbol")
+
+// Happens when a Go value held by a generated union field does not match any of the union types.
+var InvalidUnionValue = errors.New("Invalid union value")
+
+// Happens when a value to encode does not fit any branch of the union it is written to.
+var NoMatchingUnionBranch = errors.New("No matching union branch")
+
+// Happens when records, arrays and maps in a value being read are nested deeper than the reader allows.
+var MaxDepthExceeded = errors.New("Maximum nesting depth exceeded")
+
+// Happens when data continues after the value it was expected to hold.
+var TrailingBytes = errors.New("Trailing bytes after value")
+
+// Happens when a GenericRecord does not have a value for a requested field.
+var FieldNotFound = errors.New("Field not found")
+
+// Happens when a GenericRecord field value is not of the type requested by a typed getter.
+var FieldTypeMismatch = errors.New("Field value has a different type")
+
+// Happens when a message does not start with the single-object encoding marker.
+var NotSingleObject = errors.New("Not an Avro single-object encoded message")
+
+// Happens when a single-object encoded message was written with a schema whose fingerprint is not known.
+var UnknownFingerprint = errors.New("Unknown schema fingerprint")
+
+// Happens when a message does not start with the Confluent Schema Registry wire format magic byte.
+var InvalidConfluentMagic = errors.New("Invalid Confluent wire format magic byte")
+
+// DecodeError is returned by BinaryDecoder when a value can not be decoded. It wraps one of the errors above
+// (e.g. EOF or IntOverflow), which can be checked for with errors.Is, and records where the value started.
+type DecodeError struct {
+	// Name of the BinaryDecoder method that failed, e.g. "ReadString".
+	Op string
+
+	// Position of the value that failed to decode.
+	Pos int64
+
+	// Underlying error.
+	Err error
+}
+
+func (this *DecodeError) Error() string {
+	return fmt.Sprintf("%s at position %d: %s", this.Op, this.Pos, this.Err)
+}
+
+// Returns the underlying error of this DecodeError.
+func (this *DecodeError) Unwrap() error {
+	return this.Err
+}
+
+// InvalidBoolError is returned when a value decoded as bool is neither 0x00 nor 0x01. It matches InvalidBool with
+// errors.Is and tells which byte was found where, which helps telling corrupt data from misaligned reads.
+type InvalidBoolError struct {
+	// The byte found instead of 0x00 or 0x01.
+	Value byte
+
+	// Position of the invalid byte.
+	Pos int64
+}
+
+func (this *InvalidBoolError) Error() string {
+	return fmt.Sprintf("%s 0x%02X", InvalidBool, this.Value)
+}
+
+// Returns InvalidBool.
+func (this *InvalidBoolError) Unwrap() error {
+	return InvalidBool
+}
+
+// MapKeyDecodeError is returned by datum readers when the key of a map entry can not be read. As map keys are always
+// strings, this mostly means the data is misaligned or was written by a buggy producer.
+type MapKeyDecodeError struct {
+	// Index of the entry whose key failed to decode, counted from the first entry of the map over all blocks.
+	Index int64
+
+	// Underlying error.
+	Err error
+}
+
+func (this *MapKeyDecodeError) Error() string {
+	return fmt.Sprintf("Could not decode key of map entry %d: %s", this.Index, this.Err)
+}
+
+// Returns the underlying error of this MapKeyDecodeError.
+func (this *MapKeyDecodeError) Unwrap() error {
+	return this.Err
+}
@@ -0,0 +1,44 @@
+package avro
+
+import "encoding/binary"
+
+// The initial value and the empty string fingerprint of CRC-64-AVRO.
+const fingerprint_empty uint64 = 0xc15d213aa4d7a795
+
+var fingerprintTable = newFingerprintTable()
+
+func newFingerprintTable() [256]uint64 {
+	var table [256]uint64
+	for i := range table {
+		fingerprint := uint64(i)
+		for j := 0; j < 8; j++ {
+			fingerprint = (fingerprint >> 1) ^ (fingerprint_empty & -(fingerprint & 1))
+		}
+		table[i] = fingerprint
+	}
+	return table
+}
+
+// Computes the 64-bit Rabin fingerprint (CRC-64-AVRO) of the Parsing Canonical Form of a given Schema.
+// This is the fingerprint used by single-object encoding and schema registries to identify schemas.
+// Panics if the schema is not one of the Schema implementations of this package.
+func Fingerprint(schema Schema) uint64 {
+	canonical, err := CanonicalForm(schema)
+	if err != nil {
+		panic(err)
+	}
+
+	fingerprint := fingerprint_empty
+	for _, b := range []byte(canonical) {
+		fingerprint = (fingerprint >> 8) ^ fingerprintTable[byte(fingerprint)^b]
+	}
+	return fingerprint
+}
+
+// Returns the CRC-64-AVRO fingerprint of a given Schema in its 8 byte little-endian form as it is written to the wire.
+// Panics if the schema is not one of the Schema implementations of this package.
+func FingerprintBytes(schema Schema) []byte {
+	fingerprint := make([]byte, 8)
+	binary.LittleEndian.PutUint64(fingerprint, Fingerprint(schema))
+	return fingerprint
+}
@@ -0,0 +1,38 @@
+package avro
+
+import "testing"
+
+func TestFingerprintPrimitives(t *testing.T) {
+	// test vectors from the Avro specification test suite (share/test/data/schema-tests.txt)
+	vectors := map[string]int64{
+		`"null"`:    7195948357588979594,
+		`"boolean"`: -6970731678124411036,
+		`"int"`:     8247732601305521295,
+		`"long"`:    -3434872931120570953,
+		`"float"`:   5583340709985441680,
+		`"double"`:  -8181574048448539266,
+		`"bytes"`:   5746618253357095269,
+		`"string"`:  -8142146995180207161,
+	}
+	for rawSchema, expected := range vectors {
+		if fingerprint := Fingerprint(MustParseSchema(rawSchema)); int64(fingerprint) != expected {
+			t.Errorf("Fingerprint of %s: expected %d, got %d", rawSchema, expected, int64(fingerprint))
+		}
+	}
+
+	assert(t, FingerprintBytes(MustParseSchema(`"int"`)), []byte{0x8f, 0x5c, 0x39, 0x3f, 0x1a, 0xd5, 0x75, 0x72})
+}
+
+func TestFingerprintIgnoresNonCanonicalAttributes(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Test", "namespace": "ns", "doc": "A test record",
+		"fields": [{"name": "f", "type": "long", "doc": "A field", "default": 1, "aliases": ["g"]}]}`)
+	canonical, err := CanonicalForm(schema)
+	assert(t, err, nil)
+	assert(t, canonical, `{"name":"ns.Test","type":"record","fields":[{"name":"f","type":"long"}]}`)
+
+	stripped := MustParseSchema(`{"name":"ns.Test","type":"record","fields":[{"name":"f","type":"long"}]}`)
+	assert(t, Fingerprint(schema), Fingerprint(stripped))
+	if Fingerprint(schema) == Fingerprint(MustParseSchema(`{"name":"ns.Test","type":"record","fields":[{"name":"f","type":"int"}]}`)) {
+		t.Fatal("Expected different schemas to have different fingerprints")
+	}
+}
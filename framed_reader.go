@@ -0,0 +1,94 @@
+package avro
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// LengthFraming tells how the length prefix of the messages read by a FramedReader is encoded.
+type LengthFraming int
+
+const (
+	// The length is a zig-zag varint encoded long like Avro bytes lengths.
+	VarintLength LengthFraming = iota
+
+	// The length is a 4 byte big-endian unsigned int.
+	Fixed32Length
+)
+
+// FramedReader reads Avro messages from an io.Reader where every message is prefixed with its length in bytes, e.g.
+// from an append-only log of Avro messages. Each message is read into memory as a whole and handed out through a
+// BinaryDecoder.
+type FramedReader struct {
+	stream       *StreamBinaryDecoder
+	framing      LengthFraming
+	frame        []byte
+	dec          *BinaryDecoder
+	maxFrameSize int64
+}
+
+// Creates a new FramedReader reading length prefixed messages from a given io.Reader. Lengths are varint encoded
+// unless set otherwise with SetLengthFraming.
+func NewFramedReader(r io.Reader) *FramedReader {
+	return &FramedReader{
+		stream:       NewStreamBinaryDecoder(r),
+		dec:          NewBinaryDecoder(nil),
+		maxFrameSize: max_alloc_size,
+	}
+}
+
+// Sets how the length prefix of messages is encoded for this FramedReader. Defaults to VarintLength.
+func (this *FramedReader) SetLengthFraming(framing LengthFraming) {
+	this.framing = framing
+}
+
+// Sets the maximum length in bytes of a message this FramedReader reads. Reading a message with a longer length
+// prefix returns SizeLimitExceeded before anything is allocated. Defaults to 1 GiB.
+func (this *FramedReader) SetMaxFrameSize(size int64) {
+	this.maxFrameSize = size
+}
+
+// Reads the next message and returns a BinaryDecoder positioned at its start. The decoder and the message bytes are
+// reused by the next call, so values read without copying must not be used after that.
+// Returns EOF if there are no more messages, UnexpectedEOF if the last message is truncated, SizeLimitExceeded if
+// the message is longer than the maximum frame size or an error if it occurs.
+func (this *FramedReader) Next() (*BinaryDecoder, error) {
+	start := this.stream.Tell()
+	length, err := this.readLength()
+	if err == EOF && this.stream.Tell() > start {
+		return nil, UnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		return nil, NegativeBytesLength
+	}
+	if length > this.maxFrameSize {
+		return nil, SizeLimitExceeded
+	}
+
+	if int64(cap(this.frame)) < length {
+		this.frame = make([]byte, length)
+	}
+	this.frame = this.frame[:length]
+	if err := this.stream.ReadFixed(this.frame); err != nil {
+		if err == EOF {
+			return nil, UnexpectedEOF
+		}
+		return nil, err
+	}
+	this.dec.Reset(this.frame)
+	return this.dec, nil
+}
+
+func (this *FramedReader) readLength() (int64, error) {
+	if this.framing == Fixed32Length {
+		var length [4]byte
+		if err := this.stream.ReadFixed(length[:]); err != nil {
+			return 0, err
+		}
+		return int64(binary.BigEndian.Uint32(length[:])), nil
+	}
+	return this.stream.ReadLong()
+}
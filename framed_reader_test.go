@@ -0,0 +1,79 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// writes each given string as a length prefixed message using a given length framing
+func encodeFramedMessages(framing LengthFraming, values ...string) []byte {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	for _, value := range values {
+		message := &bytes.Buffer{}
+		NewBinaryEncoder(message).WriteString(value)
+		if framing == Fixed32Length {
+			length := make([]byte, 4)
+			binary.BigEndian.PutUint32(length, uint32(message.Len()))
+			enc.WriteRaw(length)
+		} else {
+			enc.WriteLong(int64(message.Len()))
+		}
+		enc.WriteRaw(message.Bytes())
+	}
+	return buf.Bytes()
+}
+
+func TestFramedReader(t *testing.T) {
+	values := []string{"first", "", "third message"}
+	for _, framing := range []LengthFraming{VarintLength, Fixed32Length} {
+		data := encodeFramedMessages(framing, values...)
+		reader := NewFramedReader(bytes.NewReader(data))
+		reader.SetLengthFraming(framing)
+		for _, expected := range values {
+			dec, err := reader.Next()
+			assert(t, err, nil)
+			value, err := dec.ReadString()
+			assert(t, err, nil)
+			assert(t, value, expected)
+			assert(t, dec.AtEnd(), true)
+		}
+		_, err := reader.Next()
+		assert(t, err, EOF)
+
+		// the final frame is cut off right after the first byte and in the middle of the message
+		lastFrame := len(encodeFramedMessages(framing, values[:2]...))
+		for _, end := range []int{lastFrame + 1, len(data) - 1} {
+			reader = NewFramedReader(bytes.NewReader(data[:end]))
+			reader.SetLengthFraming(framing)
+			for i := 0; i < 2; i++ {
+				_, err = reader.Next()
+				assert(t, err, nil)
+			}
+			_, err = reader.Next()
+			assertError(t, err, UnexpectedEOF)
+		}
+	}
+
+	reader := NewFramedReader(bytes.NewReader([]byte{0x01, 0x00}))
+	_, err := reader.Next()
+	assert(t, err, NegativeBytesLength)
+	reader = NewFramedReader(bytes.NewReader([]byte{0x00, 0x00}))
+	reader.SetLengthFraming(Fixed32Length)
+	_, err = reader.Next()
+	assert(t, err, UnexpectedEOF)
+
+	// a length prefix of 2^62 is rejected before allocating the frame
+	reader = NewFramedReader(bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}))
+	_, err = reader.Next()
+	assert(t, err, SizeLimitExceeded)
+	reader = NewFramedReader(bytes.NewReader(encodeFramedMessages(VarintLength, "first")))
+	reader.SetMaxFrameSize(5)
+	_, err = reader.Next()
+	assert(t, err, SizeLimitExceeded)
+	reader = NewFramedReader(bytes.NewReader(encodeFramedMessages(VarintLength, "first")))
+	reader.SetMaxFrameSize(6)
+	_, err = reader.Next()
+	assert(t, err, nil)
+}
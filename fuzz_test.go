@@ -0,0 +1,101 @@
+package avro
+
+import (
+	"bytes"
+	"testing"
+)
+
+const fuzzTestSchema = `{"type": "record", "name": "Fuzz", "fields": [
+	{"name": "flag", "type": "boolean"},
+	{"name": "int", "type": "int"},
+	{"name": "long", "type": "long"},
+	{"name": "float", "type": "float"},
+	{"name": "double", "type": "double"},
+	{"name": "bytes", "type": "bytes"},
+	{"name": "string", "type": "string"},
+	{"name": "enum", "type": {"type": "enum", "name": "Suit", "symbols": ["SPADES", "HEARTS"]}},
+	{"name": "fixed", "type": {"type": "fixed", "name": "Hash", "size": 4}},
+	{"name": "array", "type": {"type": "array", "items": ["null", "long"]}},
+	{"name": "map", "type": {"type": "map", "values": "string"}},
+	{"name": "union", "type": ["null", "string", "Suit"]}
+]}`
+
+// reads values of every kind from a given Decoder until an error occurs
+var fuzzDecoderReads = []func(dec Decoder) error{
+	func(dec Decoder) error { _, err := dec.ReadNull(); return err },
+	func(dec Decoder) error { _, err := dec.ReadBoolean(); return err },
+	func(dec Decoder) error { _, err := dec.ReadInt(); return err },
+	func(dec Decoder) error { _, err := dec.ReadLong(); return err },
+	func(dec Decoder) error { _, err := dec.ReadFloat(); return err },
+	func(dec Decoder) error { _, err := dec.ReadDouble(); return err },
+	func(dec Decoder) error { _, err := dec.ReadBytes(); return err },
+	func(dec Decoder) error { _, err := dec.ReadString(); return err },
+	func(dec Decoder) error { _, err := dec.ReadEnum(); return err },
+	func(dec Decoder) error { _, err := dec.ReadArrayStart(); return err },
+	func(dec Decoder) error { _, err := dec.ArrayNext(); return err },
+	func(dec Decoder) error { _, err := dec.ReadMapStart(); return err },
+	func(dec Decoder) error { _, err := dec.MapNext(); return err },
+	func(dec Decoder) error { return dec.ReadFixed(make([]byte, 3)) },
+	func(dec Decoder) error { return dec.ReadFixedWithBounds(make([]byte, 4), 1, 3) },
+}
+
+func fuzzTestSeeds(t testing.TB) [][]byte {
+	schema := MustParseSchema(fuzzTestSchema)
+	record := NewGenericRecord(schema)
+	record.Set("flag", true)
+	record.Set("int", int32(-300))
+	record.Set("long", int64(1)<<40)
+	record.Set("float", float32(1.5))
+	record.Set("double", 2.5)
+	record.Set("bytes", []byte{1, 2, 3})
+	record.Set("string", "fuzz")
+	record.Set("enum", "HEARTS")
+	record.Set("fixed", []byte{0xDE, 0xAD, 0xBE, 0xEF})
+	record.Set("array", []interface{}{nil, int64(7)})
+	record.Set("map", map[string]interface{}{"k": "v"})
+	record.Set("union", "text")
+
+	buf := &bytes.Buffer{}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	if err := writer.Write(record, NewBinaryEncoder(buf)); err != nil {
+		t.Fatal(err)
+	}
+	return [][]byte{
+		buf.Bytes(),
+		{},
+		{0x02},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01},
+		{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01},
+		{0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01},
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range fuzzTestSeeds(f) {
+		f.Add(seed)
+	}
+	schema := MustParseSchema(fuzzTestSchema)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		newDecoders := []func() Decoder{
+			func() Decoder { return NewBinaryDecoder(data) },
+			func() Decoder { return NewStreamBinaryDecoder(bytes.NewReader(data)) },
+		}
+		for _, newDecoder := range newDecoders {
+			for _, read := range fuzzDecoderReads {
+				dec := newDecoder()
+				for i := 0; i <= len(data) && read(dec) == nil; i++ {
+				}
+			}
+
+			reader := NewGenericDatumReader()
+			reader.SetSchema(schema)
+			reader.Read(NewGenericRecord(schema), newDecoder())
+		}
+
+		NewBinaryDecoder(data).skipValue(schema)
+		NewBinaryDecoder(data).ReadRaw(schema)
+		DecodeToMap(fuzzTestSchema, data)
+	})
+}
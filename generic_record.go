@@ -48,3 +48,141 @@ func (this *GenericRecord) Set(name string, value interface{}) {
 func (this *GenericRecord) Schema() Schema {
 	return this.schema
 }
+
+// Gets a boolean value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a boolean.
+func (this *GenericRecord) GetBoolean(name string) (bool, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return false, err
+	}
+	if typed, ok := value.(bool); ok {
+		return typed, nil
+	}
+	return false, FieldTypeMismatch
+}
+
+// Gets an int value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not an int.
+func (this *GenericRecord) GetInt(name string) (int32, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return 0, err
+	}
+	if typed, ok := value.(int32); ok {
+		return typed, nil
+	}
+	return 0, FieldTypeMismatch
+}
+
+// Gets a long value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a long.
+func (this *GenericRecord) GetLong(name string) (int64, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return 0, err
+	}
+	if typed, ok := value.(int64); ok {
+		return typed, nil
+	}
+	return 0, FieldTypeMismatch
+}
+
+// Gets a float value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a float.
+func (this *GenericRecord) GetFloat(name string) (float32, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return 0, err
+	}
+	if typed, ok := value.(float32); ok {
+		return typed, nil
+	}
+	return 0, FieldTypeMismatch
+}
+
+// Gets a double value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a double.
+func (this *GenericRecord) GetDouble(name string) (float64, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return 0, err
+	}
+	if typed, ok := value.(float64); ok {
+		return typed, nil
+	}
+	return 0, FieldTypeMismatch
+}
+
+// Gets a bytes or fixed value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a byte slice.
+func (this *GenericRecord) GetBytes(name string) ([]byte, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return nil, err
+	}
+	if typed, ok := value.([]byte); ok {
+		return typed, nil
+	}
+	return nil, FieldTypeMismatch
+}
+
+// Gets a string or enum symbol value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a string.
+func (this *GenericRecord) GetString(name string) (string, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return "", err
+	}
+	if typed, ok := value.(string); ok {
+		return typed, nil
+	}
+	return "", FieldTypeMismatch
+}
+
+// Gets an array value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not an array.
+func (this *GenericRecord) GetArray(name string) ([]interface{}, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return nil, err
+	}
+	if typed, ok := value.([]interface{}); ok {
+		return typed, nil
+	}
+	return nil, FieldTypeMismatch
+}
+
+// Gets a map value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a map.
+func (this *GenericRecord) GetMap(name string) (map[string]interface{}, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return nil, err
+	}
+	if typed, ok := value.(map[string]interface{}); ok {
+		return typed, nil
+	}
+	return nil, FieldTypeMismatch
+}
+
+// Gets a nested record value by its name.
+// Returns FieldNotFound if there is no value for the field or FieldTypeMismatch if the value is not a record.
+func (this *GenericRecord) GetRecord(name string) (*GenericRecord, error) {
+	value, err := this.getValue(name)
+	if err != nil {
+		return nil, err
+	}
+	if typed, ok := value.(*GenericRecord); ok {
+		return typed, nil
+	}
+	return nil, FieldTypeMismatch
+}
+
+func (this *GenericRecord) getValue(name string) (interface{}, error) {
+	value, exists := this.fields[name]
+	if !exists {
+		return nil, FieldNotFound
+	}
+	return value, nil
+}
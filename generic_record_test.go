@@ -0,0 +1,78 @@
+package avro
+
+import "testing"
+
+func TestGenericRecordTypedGetters(t *testing.T) {
+	schema := MustParseSchema(genericTestSchema)
+	datumReader := NewGenericDatumReader()
+	datumReader.SetSchema(schema)
+	record := NewGenericRecord(schema)
+	assert(t, datumReader.Read(record, NewBinaryDecoder(encodeGenericTestRecord())), nil)
+
+	booleanValue, err := record.GetBoolean("booleanField")
+	assert(t, err, nil)
+	assert(t, booleanValue, true)
+	intValue, err := record.GetInt("intField")
+	assert(t, err, nil)
+	assert(t, intValue, int32(-123))
+	longValue, err := record.GetLong("longField")
+	assert(t, err, nil)
+	assert(t, longValue, int64(1234567890123))
+	floatValue, err := record.GetFloat("floatField")
+	assert(t, err, nil)
+	assert(t, floatValue, float32(1.5))
+	doubleValue, err := record.GetDouble("doubleField")
+	assert(t, err, nil)
+	assert(t, doubleValue, float64(-2.25))
+	bytesValue, err := record.GetBytes("bytesField")
+	assert(t, err, nil)
+	assert(t, bytesValue, []byte{0x01, 0x02})
+	fixedValue, err := record.GetBytes("fixedField")
+	assert(t, err, nil)
+	assert(t, fixedValue, []byte{0x0A, 0x0B, 0x0C, 0x0D})
+	stringValue, err := record.GetString("stringField")
+	assert(t, err, nil)
+	assert(t, stringValue, "hello")
+	enumValue, err := record.GetString("enumField")
+	assert(t, err, nil)
+	assert(t, enumValue, "C")
+	arrayValue, err := record.GetArray("arrayField")
+	assert(t, err, nil)
+	assert(t, arrayValue, []interface{}{"a", "b", "c"})
+	mapValue, err := record.GetMap("mapField")
+	assert(t, err, nil)
+	assert(t, mapValue, map[string]interface{}{"key": int64(42)})
+	nested, err := record.GetRecord("recordField")
+	assert(t, err, nil)
+	nestedInt, err := nested.GetInt("x")
+	assert(t, err, nil)
+	assert(t, nestedInt, int32(7))
+}
+
+func TestGenericRecordGetterErrors(t *testing.T) {
+	record := NewGenericRecord(MustParseSchema(genericTestSchema))
+	record.Set("intField", int32(1))
+	record.Set("nullField", nil)
+
+	_, err := record.GetInt("missing")
+	assert(t, err, FieldNotFound)
+	_, err = record.GetRecord("recordField")
+	assert(t, err, FieldNotFound)
+
+	_, err = record.GetLong("intField")
+	assert(t, err, FieldTypeMismatch)
+	_, err = record.GetString("intField")
+	assert(t, err, FieldTypeMismatch)
+	_, err = record.GetArray("nullField")
+	assert(t, err, FieldTypeMismatch)
+	_, err = record.GetBoolean("intField")
+	assert(t, err, FieldTypeMismatch)
+	_, err = record.GetFloat("intField")
+	assert(t, err, FieldTypeMismatch)
+	_, err = record.GetDouble("intField")
+	assert(t, err, FieldTypeMismatch)
+	_, err = record.GetBytes("intField")
+	assert(t, err, FieldTypeMismatch)
+	_, err = record.GetMap("intField")
+	assert(t, err, FieldTypeMismatch)
+}
@@ -0,0 +1,448 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// JSONDecoder implements Decoder and provides support for deserializing values in Avro JSON encoding
+// (https://avro.apache.org/docs/current/spec.html#json_encoding) so that existing DatumReaders can be reused over
+// JSON payloads. As JSON encoded data carries field names, union type names and symbols instead of positions, the
+// decoder needs the schema of the data upfront and converts each JSON value to the sequence of values a DatumReader
+// reads for that schema. This changes the meaning of some Decoder methods:
+//
+// ReadArrayStart and ReadMapStart return the total number of items as a single block, so the following ArrayNext
+// and MapNext always return 0.
+//
+// Seek and Tell work with the index of a decoded value in that sequence rather than with a byte position.
+//
+// SetBlock has no effect as JSON encoded data is not split into blocks.
+type JSONDecoder struct {
+	values []interface{}
+	pos    int64
+}
+
+// the JSON decoder reports these types for the values it has to tell apart from plain ints and longs
+type jsonItemCount int64
+type jsonUnionIndex int32
+type jsonEnumIndex int32
+
+// Creates a new JSONDecoder to read values of a given schema from Avro JSON encoded data. The data may contain
+// several concatenated JSON values each of which is decoded according to the schema.
+// May return an error if the data is not valid JSON or does not match the schema.
+func NewJSONDecoder(schema Schema, data []byte) (*JSONDecoder, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	this := &JSONDecoder{}
+	for {
+		var value interface{}
+		if err := decoder.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err := this.flatten(schema, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return this, nil
+}
+
+// Reads a null value. Nulls are not stored in the decoded sequence so this never changes the reading position and
+// always returns (nil, nil).
+func (this *JSONDecoder) ReadNull() (interface{}, error) {
+	return nil, nil
+}
+
+// Reads a boolean value. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadBoolean() (bool, error) {
+	value, err := this.next()
+	if err != nil {
+		return false, err
+	}
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	return false, this.mismatch("boolean", value)
+}
+
+// Reads an int value. Union branch indexes are also read with this method. Returns a decoded value and an error
+// if it occurs.
+func (this *JSONDecoder) ReadInt() (int32, error) {
+	value, err := this.next()
+	if err != nil {
+		return 0, err
+	}
+	switch v := value.(type) {
+	case int32:
+		return v, nil
+	case jsonUnionIndex:
+		return int32(v), nil
+	}
+	return 0, this.mismatch("int", value)
+}
+
+// Reads a long value. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadLong() (int64, error) {
+	value, err := this.next()
+	if err != nil {
+		return 0, err
+	}
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case jsonUnionIndex:
+		return int64(v), nil
+	}
+	return 0, this.mismatch("long", value)
+}
+
+// Reads a float value. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadFloat() (float32, error) {
+	value, err := this.next()
+	if err != nil {
+		return 0, err
+	}
+	if f, ok := value.(float32); ok {
+		return f, nil
+	}
+	return 0, this.mismatch("float", value)
+}
+
+// Reads a double value. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadDouble() (float64, error) {
+	value, err := this.next()
+	if err != nil {
+		return 0, err
+	}
+	if d, ok := value.(float64); ok {
+		return d, nil
+	}
+	return 0, this.mismatch("double", value)
+}
+
+// Reads a bytes value. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadBytes() ([]byte, error) {
+	value, err := this.next()
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := value.([]byte); ok {
+		return b, nil
+	}
+	return nil, this.mismatch("bytes", value)
+}
+
+// Reads a string value. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadString() (string, error) {
+	value, err := this.next()
+	if err != nil {
+		return "", err
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return "", this.mismatch("string", value)
+}
+
+// Reads an enum value and returns the index of its symbol. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadEnum() (int32, error) {
+	value, err := this.next()
+	if err != nil {
+		return 0, err
+	}
+	if index, ok := value.(jsonEnumIndex); ok {
+		return int32(index), nil
+	}
+	return 0, this.mismatch("enum", value)
+}
+
+// Reads the number of items of an array. As JSON arrays are not split into blocks this returns the total number of
+// items. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadArrayStart() (int64, error) {
+	return this.readItemCount()
+}
+
+// Processes the next block of an array. As JSON arrays are not split into blocks this always returns 0 once the
+// items are read. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ArrayNext() (int64, error) {
+	return this.readItemCount()
+}
+
+// Reads the number of entries of a map. As JSON objects are not split into blocks this returns the total number of
+// entries. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) ReadMapStart() (int64, error) {
+	return this.readItemCount()
+}
+
+// Processes the next block of map entries. As JSON objects are not split into blocks this always returns 0 once
+// the entries are read. Returns a decoded value and an error if it occurs.
+func (this *JSONDecoder) MapNext() (int64, error) {
+	return this.readItemCount()
+}
+
+// Reads fixed sized binary object into the provided buffer. The length of the buffer must match the length of the
+// decoded value. Returns an error if it occurs.
+func (this *JSONDecoder) ReadFixed(bytes []byte) error {
+	return this.ReadFixedWithBounds(bytes, 0, len(bytes))
+}
+
+// Reads fixed sized binary object into the provided buffer.
+// The second parameter is the position where the data needs to be written, the third is the size of binary object.
+// Returns an error if it occurs.
+func (this *JSONDecoder) ReadFixedWithBounds(bytes []byte, start int, length int) error {
+	if err := checkBounds(bytes, start, length); err != nil {
+		return err
+	}
+	value, err := this.next()
+	if err != nil {
+		return err
+	}
+	fixed, ok := value.([]byte)
+	if !ok {
+		return this.mismatch("fixed", value)
+	}
+	if len(fixed) != length {
+		return fmt.Errorf("Invalid fixed size in JSON data: expected %d, actual %d", length, len(fixed))
+	}
+	copy(bytes[start:start+length], fixed)
+	return nil
+}
+
+// SetBlock has no effect for JSONDecoder as JSON encoded data is not split into blocks.
+func (this *JSONDecoder) SetBlock(block *DataBlock) {}
+
+// Seek sets the reading position of this JSONDecoder to the index of a given decoded value.
+func (this *JSONDecoder) Seek(pos int64) {
+	this.pos = pos
+}
+
+// Tell returns the index of the next decoded value to read.
+func (this *JSONDecoder) Tell() int64 {
+	return this.pos
+}
+
+func (this *JSONDecoder) next() (interface{}, error) {
+	if this.pos < 0 || this.pos >= int64(len(this.values)) {
+		return nil, EOF
+	}
+	value := this.values[this.pos]
+	this.pos++
+	return value, nil
+}
+
+func (this *JSONDecoder) readItemCount() (int64, error) {
+	value, err := this.next()
+	if err != nil {
+		return 0, err
+	}
+	if count, ok := value.(jsonItemCount); ok {
+		return int64(count), nil
+	}
+	return 0, this.mismatch("array or map", value)
+}
+
+func (this *JSONDecoder) mismatch(expected string, actual interface{}) error {
+	this.pos--
+	return fmt.Errorf("Expected %s value in JSON data, actual %v", expected, actual)
+}
+
+func (this *JSONDecoder) flatten(schema Schema, value interface{}) error {
+	switch schema.Type() {
+	case Null:
+		if value != nil {
+			return jsonMismatch(schema, value)
+		}
+	case Boolean:
+		b, ok := value.(bool)
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, b)
+	case Int:
+		i, ok := jsonInt(value, math.MinInt32, math.MaxInt32)
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, int32(i))
+	case Long:
+		i, ok := jsonInt(value, math.MinInt64, math.MaxInt64)
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, i)
+	case Float:
+		number, ok := value.(json.Number)
+		f, err := number.Float64()
+		if !ok || err != nil {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, float32(f))
+	case Double:
+		number, ok := value.(json.Number)
+		f, err := number.Float64()
+		if !ok || err != nil {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, f)
+	case Bytes, Fixed:
+		bytes, ok := jsonBytes(value)
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, bytes)
+	case String:
+		s, ok := value.(string)
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, s)
+	case Enum:
+		symbol, ok := value.(string)
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		for i, s := range schema.(*EnumSchema).Symbols {
+			if s == symbol {
+				this.values = append(this.values, jsonEnumIndex(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("Unknown enum symbol in JSON data: %s", symbol)
+	case Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, jsonItemCount(len(items)))
+		for _, item := range items {
+			if err := this.flatten(schema.(*ArraySchema).Items, item); err != nil {
+				return err
+			}
+		}
+		if len(items) > 0 {
+			this.values = append(this.values, jsonItemCount(0))
+		}
+	case Map:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return jsonMismatch(schema, value)
+		}
+		this.values = append(this.values, jsonItemCount(len(entries)))
+		for key, entry := range entries {
+			this.values = append(this.values, key)
+			if err := this.flatten(schema.(*MapSchema).Values, entry); err != nil {
+				return err
+			}
+		}
+		if len(entries) > 0 {
+			this.values = append(this.values, jsonItemCount(0))
+		}
+	case Union:
+		return this.flattenUnion(schema.(*UnionSchema), value)
+	case Record:
+		return this.flattenRecord(schema.(*RecordSchema), value)
+	case Recursive:
+		return this.flattenRecord(schema.(*RecursiveSchema).Actual, value)
+	default:
+		return fmt.Errorf("Unknown schema type: %d", schema.Type())
+	}
+
+	return nil
+}
+
+func (this *JSONDecoder) flattenUnion(schema *UnionSchema, value interface{}) error {
+	if value == nil {
+		for i, t := range schema.Types {
+			if t.Type() == Null {
+				this.values = append(this.values, jsonUnionIndex(i))
+				return nil
+			}
+		}
+		return jsonMismatch(schema, value)
+	}
+
+	branch, ok := value.(map[string]interface{})
+	if !ok || len(branch) != 1 {
+		return jsonMismatch(schema, value)
+	}
+	for name, branchValue := range branch {
+		for i, t := range schema.Types {
+			if jsonTypeName(t) == name || t.GetName() == name {
+				this.values = append(this.values, jsonUnionIndex(i))
+				return this.flatten(t, branchValue)
+			}
+		}
+		return fmt.Errorf("Unknown union branch in JSON data: %s", name)
+	}
+	return nil
+}
+
+func (this *JSONDecoder) flattenRecord(schema *RecordSchema, value interface{}) error {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return jsonMismatch(schema, value)
+	}
+	for _, field := range schema.Fields {
+		fieldValue, exists := fields[field.Name]
+		if !exists {
+			return fmt.Errorf("Missing field %s of record %s in JSON data", field.Name, schema.GetName())
+		}
+		if err := this.flatten(field.Type, fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returns the name used for a given schema to tag union branches in JSON encoding
+func jsonTypeName(schema Schema) string {
+	switch s := schema.(type) {
+	case *RecordSchema:
+		return getFullName(s.Name, s.Namespace)
+	case *RecursiveSchema:
+		return getFullName(s.Actual.Name, s.Actual.Namespace)
+	case *EnumSchema:
+		return getFullName(s.Name, s.Namespace)
+	case *FixedSchema:
+		return getFullName(s.Name, s.Namespace)
+	}
+	return schema.GetName()
+}
+
+func jsonInt(value interface{}, min int64, max int64) (int64, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := number.Int64()
+	if err != nil || i < min || i > max {
+		return 0, false
+	}
+	return i, true
+}
+
+// bytes and fixed values are JSON strings whose code points 0-255 map to the byte values
+func jsonBytes(value interface{}) ([]byte, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+	bytes := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xFF {
+			return nil, false
+		}
+		bytes = append(bytes, byte(r))
+	}
+	return bytes, true
+}
+
+func jsonMismatch(schema Schema, value interface{}) error {
+	return fmt.Errorf("Invalid JSON value for %s: %v", schema.GetName(), value)
+}
@@ -0,0 +1,142 @@
+package avro
+
+import "testing"
+
+const genericTestJSON = `{
+	"booleanField": true,
+	"intField": -123,
+	"longField": 1234567890123,
+	"floatField": 1.5,
+	"doubleField": -2.25,
+	"bytesField": "\u0001\u0002",
+	"stringField": "hello",
+	"nullField": null,
+	"arrayField": ["a", "b", "c"],
+	"mapField": {"key": 42},
+	"enumField": "C",
+	"unionField": {"string": "union value"},
+	"fixedField": "\u000a\u000b\u000c\u000d",
+	"recordField": {"x": 7}
+}`
+
+func TestJSONDecoderGenericRecord(t *testing.T) {
+	schema := MustParseSchema(genericTestSchema)
+	datumReader := NewGenericDatumReader()
+	datumReader.SetSchema(schema)
+
+	dec, err := NewJSONDecoder(schema, []byte(genericTestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := NewGenericRecord(schema)
+	assert(t, datumReader.Read(record, dec), nil)
+
+	assert(t, record.Get("booleanField"), true)
+	assert(t, record.Get("intField"), int32(-123))
+	assert(t, record.Get("longField"), int64(1234567890123))
+	assert(t, record.Get("floatField"), float32(1.5))
+	assert(t, record.Get("doubleField"), float64(-2.25))
+	assert(t, record.Get("bytesField"), []byte{0x01, 0x02})
+	assert(t, record.Get("stringField"), "hello")
+	assert(t, record.Get("nullField"), nil)
+	assert(t, record.Get("arrayField"), []interface{}{"a", "b", "c"})
+	assert(t, record.Get("mapField"), map[string]interface{}{"key": int64(42)})
+	assert(t, record.Get("enumField"), "C")
+	assert(t, record.Get("unionField"), "union value")
+	assert(t, record.Get("fixedField"), []byte{0x0A, 0x0B, 0x0C, 0x0D})
+	nested := record.Get("recordField").(*GenericRecord)
+	assert(t, nested.Get("x"), int32(7))
+
+	if _, err := dec.ReadBoolean(); err != EOF {
+		t.Fatalf("Expected EOF after the last value, got %v", err)
+	}
+}
+
+// the union example of the JSON encoding section of the specification
+func TestJSONDecoderSpecUnion(t *testing.T) {
+	schema := MustParseSchema(`{"type":"record","name":"Union","fields":[{"name":"value","type":["null","string",
+		{"type":"record","name":"Foo","namespace":"example","fields":[{"name":"bar","type":"int"}]}]}]}`)
+	datumReader := NewGenericDatumReader()
+	datumReader.SetSchema(schema)
+
+	dec, err := NewJSONDecoder(schema, []byte(`{"value":null} {"value":{"string":"a"}} {"value":{"example.Foo":{"bar":1}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := NewGenericRecord(schema)
+	assert(t, datumReader.Read(record, dec), nil)
+	assert(t, record.Get("value"), nil)
+
+	record = NewGenericRecord(schema)
+	assert(t, datumReader.Read(record, dec), nil)
+	assert(t, record.Get("value"), "a")
+
+	record = NewGenericRecord(schema)
+	assert(t, datumReader.Read(record, dec), nil)
+	assert(t, record.Get("value").(*GenericRecord).Get("bar"), int32(1))
+}
+
+func TestJSONDecoderBlocks(t *testing.T) {
+	schema := MustParseSchema(`{"type":"array","items":"long"}`)
+	dec, err := NewJSONDecoder(schema, []byte(`[1, 2, 3] []`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := dec.ReadArrayStart()
+	assert(t, err, nil)
+	assert(t, count, int64(3))
+	for i := int64(1); i <= count; i++ {
+		value, err := dec.ReadLong()
+		assert(t, err, nil)
+		assert(t, value, i)
+	}
+	count, err = dec.ArrayNext()
+	assert(t, err, nil)
+	assert(t, count, int64(0))
+
+	count, err = dec.ReadArrayStart()
+	assert(t, err, nil)
+	assert(t, count, int64(0))
+
+	dec.Seek(1)
+	assert(t, dec.Tell(), int64(1))
+	value, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, value, int64(1))
+}
+
+func TestJSONDecoderMismatch(t *testing.T) {
+	invalid := []struct {
+		schema string
+		json   string
+	}{
+		{`"int"`, `"1"`},
+		{`"int"`, `2147483648`},
+		{`"long"`, `1.5`},
+		{`"boolean"`, `0`},
+		{`"bytes"`, `"Ā"`},
+		{`{"type":"enum","name":"foo","symbols":["A"]}`, `"B"`},
+		{`["null","int"]`, `1`},
+		{`["null","int"]`, `{"string":"a"}`},
+		{`{"type":"record","name":"r","fields":[{"name":"a","type":"int"}]}`, `{"b":1}`},
+		{`"string"`, `"unterminated`},
+	}
+	for _, test := range invalid {
+		if _, err := NewJSONDecoder(MustParseSchema(test.schema), []byte(test.json)); err == nil {
+			t.Errorf("Expected an error decoding %s as %s", test.json, test.schema)
+		}
+	}
+
+	dec, err := NewJSONDecoder(MustParseSchema(`"string"`), []byte(`"a"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.ReadInt(); err == nil {
+		t.Fatal("Expected an error reading a string as an int")
+	}
+	value, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "a")
+}
@@ -0,0 +1,71 @@
+package avro
+
+import "fmt"
+
+// LazyRecord is a record whose fields are decoded only when they are accessed, which saves decoding the fields of
+// wide records that are never read. The byte range of every field is found by skipping over the fields once, each
+// field is then decoded with GenericDatumReader on its first access.
+type LazyRecord struct {
+	schema  *RecordSchema
+	data    []byte
+	offsets []int64
+	reader  *GenericDatumReader
+	values  map[string]interface{}
+}
+
+// Creates a new LazyRecord of a given record schema from the record at the current position of a given
+// BinaryDecoder, moving the decoder past the record. The record keeps referring to the buffer of the decoder, which
+// must not be modified while the record is in use. Returns an error if the record is malformed.
+func NewLazyRecord(schema Schema, dec *BinaryDecoder) (*LazyRecord, error) {
+	recordSchema, ok := actualSchema(schema).(*RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("Expected a record schema, got %s", schema.GetName())
+	}
+
+	start := dec.Tell()
+	offsets := make([]int64, len(recordSchema.Fields)+1)
+	for i, field := range recordSchema.Fields {
+		offsets[i] = dec.Tell() - start
+		if err := dec.skipValue(field.Type); err != nil {
+			return nil, err
+		}
+	}
+	offsets[len(recordSchema.Fields)] = dec.Tell() - start
+
+	return &LazyRecord{
+		schema:  recordSchema,
+		data:    dec.buf[start:dec.Tell()],
+		offsets: offsets,
+		reader:  NewGenericDatumReader(),
+		values:  make(map[string]interface{}),
+	}, nil
+}
+
+// Returns a schema for this LazyRecord.
+func (this *LazyRecord) Schema() Schema {
+	return this.schema
+}
+
+// Gets a field value by its name, decoding it on first access. Values have the types GenericRecord holds.
+// Returns FieldNotFound if the record schema has no such field or an error if the field cannot be decoded.
+func (this *LazyRecord) Get(name string) (interface{}, error) {
+	if value, ok := this.values[name]; ok {
+		return value, nil
+	}
+
+	for i, field := range this.schema.Fields {
+		if field.Name != name {
+			continue
+		}
+		value, err := this.reader.readValue(field.Type, NewBinaryDecoder(this.data[this.offsets[i]:this.offsets[i+1]]))
+		if err != nil {
+			return nil, err
+		}
+		if enum, ok := value.(*GenericEnum); ok {
+			value = enum.Get()
+		}
+		this.values[name] = value
+		return value, nil
+	}
+	return nil, FieldNotFound
+}
@@ -0,0 +1,103 @@
+package avro
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLazyRecord(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Lazy", "fields": [
+		{"name": "id", "type": "long"},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "suit", "type": {"type": "enum", "name": "Suit", "symbols": ["SPADES", "HEARTS"]}},
+		{"name": "note", "type": ["null", "string"]}
+	]}`)
+	record := NewGenericRecord(schema)
+	record.Set("id", int64(42))
+	record.Set("tags", []interface{}{"a", "b"})
+	record.Set("suit", "HEARTS")
+	record.Set("note", "lazy")
+
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	assert(t, writer.Write(record, enc), nil)
+	enc.WriteLong(7)
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	lazy, err := NewLazyRecord(schema, dec)
+	assert(t, err, nil)
+	// the decoder is moved past the record
+	trailing, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, trailing, int64(7))
+
+	for _, name := range []string{"note", "suit", "tags", "id", "note"} {
+		value, err := lazy.Get(name)
+		assert(t, err, nil)
+		assert(t, value, record.Get(name))
+	}
+	_, err = lazy.Get("missing")
+	assertError(t, err, FieldNotFound)
+
+	_, err = NewLazyRecord(schema, NewBinaryDecoder(buf.Bytes()[:5]))
+	if err == nil {
+		t.Fatal("Expected an error for a truncated record")
+	}
+	_, err = NewLazyRecord(MustParseSchema(`"long"`), NewBinaryDecoder(nil))
+	if err == nil {
+		t.Fatal("Expected an error for a non-record schema")
+	}
+}
+
+// returns a record schema with a given number of string fields and a value of it encoded
+func wideRecordPayload(fields int) (Schema, []byte) {
+	var definitions []string
+	for i := 0; i < fields; i++ {
+		definitions = append(definitions, fmt.Sprintf(`{"name": "field%d", "type": "string"}`, i))
+	}
+	schema := MustParseSchema(fmt.Sprintf(`{"type": "record", "name": "Wide", "fields": [%s]}`, strings.Join(definitions, ",")))
+
+	record := NewGenericRecord(schema)
+	for i := 0; i < fields; i++ {
+		record.Set(fmt.Sprintf("field%d", i), strings.Repeat("x", 32))
+	}
+	buf := &bytes.Buffer{}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	writer.Write(record, NewBinaryEncoder(buf))
+	return schema, buf.Bytes()
+}
+
+func BenchmarkLazyRecordGet(b *testing.B) {
+	schema, payload := wideRecordPayload(200)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		record, err := NewLazyRecord(schema, NewBinaryDecoder(payload))
+		if err != nil {
+			b.Fatal(err)
+		}
+		record.Get("field10")
+		record.Get("field150")
+	}
+}
+
+func BenchmarkGenericRecordGet(b *testing.B) {
+	schema, payload := wideRecordPayload(200)
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		record := NewGenericRecord(schema)
+		if err := reader.Read(record, NewBinaryDecoder(payload)); err != nil {
+			b.Fatal(err)
+		}
+		record.Get("field10")
+		record.Get("field150")
+	}
+}
@@ -0,0 +1,335 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// Reads a decimal logical type value backed by Avro bytes. The bytes hold the two's-complement big-endian unscaled
+// value which is then divided by 10^scale. Returns a decoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadDecimal(scale int) (*big.Rat, error) {
+	if scale < 0 {
+		return nil, fmt.Errorf("Invalid decimal scale: %d", scale)
+	}
+	bytes, err := this.ReadBytes()
+	if err != nil {
+		return nil, err
+	}
+	return decimalFromBytes(bytes, scale), nil
+}
+
+// Reads a decimal logical type value backed by an Avro fixed of a given size. The bytes hold the two's-complement
+// big-endian unscaled value which is then divided by 10^scale. Returns a decoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadFixedDecimal(size int, scale int) (*big.Rat, error) {
+	if scale < 0 {
+		return nil, fmt.Errorf("Invalid decimal scale: %d", scale)
+	}
+	unscaled, err := this.ReadFixedBigInt(size, true)
+	if err != nil {
+		return nil, err
+	}
+	return decimalFromBigInt(unscaled, scale), nil
+}
+
+// Reads an Avro fixed of a given size holding a big-endian integer, e.g. the unscaled value of a decimal backed by
+// a fixed. The integer is read as two's-complement if signed is true and as unsigned otherwise.
+// Returns a decoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadFixedBigInt(size int, signed bool) (*big.Int, error) {
+	bytes, err := this.ReadFixedAlloc(size)
+	if err != nil {
+		return nil, err
+	}
+	if signed {
+		return bigIntFromBytes(bytes), nil
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+// Reads a date logical type value backed by an Avro int holding the number of days since the Unix epoch.
+// Returns a decoded value in UTC and an error if it occurs.
+func (this *BinaryDecoder) ReadDate() (time.Time, error) {
+	days, err := this.ReadInt()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return dateFromDays(days), nil
+}
+
+// Reads a time-millis logical type value backed by an Avro int holding the number of milliseconds after midnight.
+// Returns a decoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadTimeMillis() (time.Duration, error) {
+	millis, err := this.ReadInt()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(millis) * time.Millisecond, nil
+}
+
+// Reads a time-micros logical type value backed by an Avro long holding the number of microseconds after midnight.
+// Returns a decoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadTimeMicros() (time.Duration, error) {
+	micros, err := this.ReadLong()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(micros) * time.Microsecond, nil
+}
+
+// Reads a timestamp-millis logical type value backed by an Avro long holding the number of milliseconds since the
+// Unix epoch. Returns a decoded value in UTC and an error if it occurs.
+func (this *BinaryDecoder) ReadTimestampMillis() (time.Time, error) {
+	millis, err := this.ReadLong()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return timestampFromMillis(millis), nil
+}
+
+// Reads a timestamp-micros logical type value backed by an Avro long holding the number of microseconds since the
+// Unix epoch. Returns a decoded value in UTC and an error if it occurs.
+func (this *BinaryDecoder) ReadTimestampMicros() (time.Time, error) {
+	micros, err := this.ReadLong()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return timestampFromMicros(micros), nil
+}
+
+// Reads a duration logical type value backed by an Avro fixed of size 12 holding three little-endian unsigned ints:
+// the number of months, days and milliseconds. Returns decoded values and an error if it occurs.
+func (this *BinaryDecoder) ReadDuration() (months uint32, days uint32, millis uint32, err error) {
+	bytes := make([]byte, durationSize)
+	if err = this.ReadFixed(bytes); err != nil {
+		return
+	}
+	return durationFromBytes(bytes)
+}
+
+// Reads a uuid logical type value backed by an Avro string in the canonical 36 character hyphenated form.
+// Returns the 16 raw bytes of the UUID, or InvalidUUID if the string is not a valid UUID.
+func (this *BinaryDecoder) ReadUUID() ([16]byte, error) {
+	str, err := this.ReadString()
+	if err != nil {
+		return [16]byte{}, err
+	}
+	return uuidFromString(str)
+}
+
+// Duration is the value of a duration logical type as read by GenericDatumReader.
+type Duration struct {
+	Months uint32
+	Days   uint32
+	Millis uint32
+}
+
+const schema_logicalTypeProp = "logicalType"
+
+// converts a value read with a given schema into the native Go type of the logical type of that schema. Values of
+// schemas without a logical type, with an unknown logical type or with a logical type not applicable to the schema
+// are returned as they are, as the spec requires invalid logical types to be ignored.
+func logicalValue(schema Schema, value interface{}) interface{} {
+	logicalType, ok := schema.Prop(schema_logicalTypeProp)
+	if !ok {
+		return value
+	}
+
+	switch v := value.(type) {
+	case int32:
+		switch logicalType {
+		case "date":
+			return dateFromDays(v)
+		case "time-millis":
+			return time.Duration(v) * time.Millisecond
+		}
+	case int64:
+		switch logicalType {
+		case "time-micros":
+			return time.Duration(v) * time.Microsecond
+		case "timestamp-millis":
+			return timestampFromMillis(v)
+		case "timestamp-micros":
+			return timestampFromMicros(v)
+		}
+	case []byte:
+		switch logicalType {
+		case "decimal":
+			if scale, ok := decimalScale(schema); ok {
+				return decimalFromBytes(v, scale)
+			}
+		case "duration":
+			if months, days, millis, err := durationFromBytes(v); err == nil {
+				return Duration{Months: months, Days: days, Millis: millis}
+			}
+		}
+	case string:
+		if logicalType == "uuid" {
+			if uuid, err := uuidFromString(v); err == nil {
+				return uuid
+			}
+		}
+	}
+
+	return value
+}
+
+// converts a native Go value of the logical type of a given schema, as logicalValue returns it, back into the
+// primitive value of that schema so that values read by GenericDatumReader can be written again. Values that are not
+// of the native type of the logical type or can not be represented by the schema are returned as they are.
+func primitiveValue(schema Schema, value interface{}) interface{} {
+	logicalType, ok := schema.Prop(schema_logicalTypeProp)
+	if !ok {
+		return value
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		switch {
+		case logicalType == "date" && schema.Type() == Int:
+			days := v.Unix() / (24 * 60 * 60)
+			if v.Unix() < 0 && v.Unix()%(24*60*60) != 0 {
+				days--
+			}
+			if days >= math.MinInt32 && days <= math.MaxInt32 {
+				return int32(days)
+			}
+		case logicalType == "timestamp-millis" && schema.Type() == Long:
+			return v.Unix()*1e3 + int64(v.Nanosecond())/1e6
+		case logicalType == "timestamp-micros" && schema.Type() == Long:
+			return v.Unix()*1e6 + int64(v.Nanosecond())/1e3
+		}
+	case time.Duration:
+		switch {
+		case logicalType == "time-millis" && schema.Type() == Int:
+			if millis := v / time.Millisecond; millis >= math.MinInt32 && millis <= math.MaxInt32 {
+				return int32(millis)
+			}
+		case logicalType == "time-micros" && schema.Type() == Long:
+			return int64(v / time.Microsecond)
+		}
+	case *big.Rat:
+		scale, ok := decimalScale(schema)
+		if logicalType != "decimal" || !ok {
+			break
+		}
+		unscaled := new(big.Rat).Mul(v, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+		if !unscaled.IsInt() {
+			break
+		}
+		size := -1
+		if fixed, ok := schema.(*FixedSchema); ok {
+			size = fixed.Size
+		} else if schema.Type() != Bytes {
+			break
+		}
+		if bytes, ok := bigIntToBytes(unscaled.Num(), size); ok {
+			return bytes
+		}
+	case [16]byte:
+		if logicalType == "uuid" && schema.Type() == String {
+			return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:16])
+		}
+	case Duration:
+		if fixed, ok := schema.(*FixedSchema); ok && logicalType == "duration" && fixed.Size == durationSize {
+			bytes := make([]byte, durationSize)
+			binary.LittleEndian.PutUint32(bytes[0:4], v.Months)
+			binary.LittleEndian.PutUint32(bytes[4:8], v.Days)
+			binary.LittleEndian.PutUint32(bytes[8:12], v.Millis)
+			return bytes
+		}
+	}
+
+	return value
+}
+
+// returns the scale of a decimal schema, which defaults to 0, and false if the scale is invalid
+func decimalScale(schema Schema) (int, bool) {
+	prop, ok := schema.Prop("scale")
+	if !ok {
+		return 0, true
+	}
+	scale, err := strconv.Atoi(prop)
+	return scale, err == nil && scale >= 0
+}
+
+// returns the two's-complement big-endian bytes of a given integer, as few as possible if size is negative or
+// exactly size bytes otherwise. Returns false if the integer does not fit into size bytes.
+func bigIntToBytes(value *big.Int, size int) ([]byte, bool) {
+	bits := value.BitLen()
+	if value.Sign() < 0 {
+		bits = new(big.Int).Not(value).BitLen()
+	}
+	// one more bit for the sign
+	length := bits/8 + 1
+	if size >= 0 {
+		if length > size {
+			return nil, false
+		}
+		length = size
+	}
+
+	unsigned := value
+	if value.Sign() < 0 {
+		unsigned = new(big.Int).Add(value, new(big.Int).Lsh(big.NewInt(1), uint(length*8)))
+	}
+	return unsigned.FillBytes(make([]byte, length)), true
+}
+
+// interprets the given bytes as a two's-complement big-endian integer
+func bigIntFromBytes(bytes []byte) *big.Int {
+	value := new(big.Int).SetBytes(bytes)
+	if len(bytes) > 0 && bytes[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(len(bytes)*8)))
+	}
+	return value
+}
+
+func decimalFromBytes(bytes []byte, scale int) *big.Rat {
+	return decimalFromBigInt(bigIntFromBytes(bytes), scale)
+}
+
+func decimalFromBigInt(unscaled *big.Int, scale int) *big.Rat {
+	denominator := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	return new(big.Rat).SetFrac(unscaled, denominator)
+}
+
+const durationSize = 12
+
+func durationFromBytes(bytes []byte) (months uint32, days uint32, millis uint32, err error) {
+	if len(bytes) != durationSize {
+		err = fmt.Errorf("Invalid duration size: expected %d bytes, actual %d", durationSize, len(bytes))
+		return
+	}
+	months = binary.LittleEndian.Uint32(bytes[0:4])
+	days = binary.LittleEndian.Uint32(bytes[4:8])
+	millis = binary.LittleEndian.Uint32(bytes[8:12])
+	return
+}
+
+func dateFromDays(days int32) time.Time {
+	return time.Unix(int64(days)*24*60*60, 0).UTC()
+}
+
+func timestampFromMillis(millis int64) time.Time {
+	return time.Unix(millis/1e3, (millis%1e3)*1e6).UTC()
+}
+
+func timestampFromMicros(micros int64) time.Time {
+	return time.Unix(micros/1e6, (micros%1e6)*1e3).UTC()
+}
+
+func uuidFromString(str string) ([16]byte, error) {
+	var uuid [16]byte
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return uuid, InvalidUUID
+	}
+	digits := str[0:8] + str[9:13] + str[14:18] + str[19:23] + str[24:36]
+	if _, err := hex.Decode(uuid[:], []byte(digits)); err != nil {
+		return uuid, InvalidUUID
+	}
+	return uuid, nil
+}
@@ -0,0 +1,302 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestDecimal(t *testing.T) {
+	decimals := []struct {
+		bytes    []byte
+		scale    int
+		expected string
+	}{
+		{[]byte{}, 0, "0"},
+		{[]byte{0x00}, 2, "0"},
+		{[]byte{0x01, 0x00}, 2, "2.56"},
+		{[]byte{0x7F}, 0, "127"},
+		{[]byte{0xFF}, 0, "-1"},
+		{[]byte{0x80}, 1, "-12.8"},
+		{[]byte{0xFF, 0x38}, 2, "-2"},
+		{[]byte{0x00, 0xFF, 0x38}, 2, "653.36"},
+	}
+
+	for _, decimal := range decimals {
+		expected, _ := new(big.Rat).SetString(decimal.expected)
+
+		dec := NewBinaryDecoder(append([]byte{byte(len(decimal.bytes) * 2)}, decimal.bytes...))
+		actual, err := dec.ReadDecimal(decimal.scale)
+		assert(t, err, nil)
+		if actual.Cmp(expected) != 0 {
+			t.Errorf("Unexpected decimal for %v: expected %v, actual %v", decimal.bytes, expected, actual)
+		}
+
+		dec = NewBinaryDecoder(decimal.bytes)
+		actual, err = dec.ReadFixedDecimal(len(decimal.bytes), decimal.scale)
+		assert(t, err, nil)
+		if actual.Cmp(expected) != 0 {
+			t.Errorf("Unexpected fixed decimal for %v: expected %v, actual %v", decimal.bytes, expected, actual)
+		}
+	}
+
+	if _, err := NewBinaryDecoder([]byte{0xFF, 0xFF}).ReadFixedDecimal(4, 0); !errors.Is(err, EOF) {
+		t.Errorf("Unexpected error for truncated fixed decimal: expected %v, actual %v", EOF, err)
+	}
+	if _, err := NewBinaryDecoder([]byte{0x02, 0x01}).ReadDecimal(-1); err == nil {
+		t.Error("Expected an error for negative scale")
+	}
+}
+
+func TestReadFixedBigInt(t *testing.T) {
+	values := []struct {
+		bytes    []byte
+		signed   string
+		unsigned string
+	}{
+		{[]byte{}, "0", "0"},
+		{[]byte{0x00}, "0", "0"},
+		{[]byte{0x00, 0x00, 0x00, 0x00}, "0", "0"},
+		{[]byte{0x7F}, "127", "127"},
+		{[]byte{0x80}, "-128", "128"},
+		{[]byte{0xFF, 0xFF}, "-1", "65535"},
+		{[]byte{0x01, 0x00, 0x00}, "65536", "65536"},
+		{[]byte{0xFF, 0x38}, "-200", "65336"},
+		{[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, "1", "1"},
+		{[]byte{0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, "-2361183241434822606848", "2361183241434822606848"},
+	}
+
+	for _, value := range values {
+		for _, signed := range []bool{true, false} {
+			expected, _ := new(big.Int).SetString(value.unsigned, 10)
+			if signed {
+				expected, _ = new(big.Int).SetString(value.signed, 10)
+			}
+
+			dec := NewBinaryDecoder(append(append([]byte(nil), value.bytes...), 0x2A))
+			actual, err := dec.ReadFixedBigInt(len(value.bytes), signed)
+			assert(t, err, nil)
+			if actual.Cmp(expected) != 0 {
+				t.Errorf("Unexpected integer for %v (signed %v): expected %v, actual %v", value.bytes, signed, expected, actual)
+			}
+			assert(t, dec.Tell(), int64(len(value.bytes)))
+		}
+	}
+
+	_, err := NewBinaryDecoder([]byte{0x01}).ReadFixedBigInt(2, false)
+	assertError(t, err, EOF)
+	_, err = NewBinaryDecoder([]byte{0x01}).ReadFixedBigInt(-1, true)
+	assertError(t, err, NegativeBytesLength)
+	dec := NewBinaryDecoder([]byte{0x01, 0x02})
+	dec.SetMaxAllocSize(1)
+	_, err = dec.ReadFixedBigInt(2, false)
+	assertError(t, err, SizeLimitExceeded)
+}
+
+func TestDateAndTime(t *testing.T) {
+	encode := func(write func(enc *BinaryEncoder)) *BinaryDecoder {
+		buf := &bytes.Buffer{}
+		write(NewBinaryEncoder(buf))
+		return NewBinaryDecoder(buf.Bytes())
+	}
+
+	dates := map[int32]time.Time{
+		0:      time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		1:      time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC),
+		-1:     time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+		17897:  time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		-25567: time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for days, expected := range dates {
+		actual, err := encode(func(enc *BinaryEncoder) { enc.WriteInt(days) }).ReadDate()
+		assert(t, err, nil)
+		assert(t, actual, expected)
+	}
+
+	timestamps := map[int64]time.Time{
+		0:              time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		1:              time.Date(1970, 1, 1, 0, 0, 0, 1000000, time.UTC),
+		-1:             time.Date(1969, 12, 31, 23, 59, 59, 999000000, time.UTC),
+		1546300800123:  time.Date(2019, 1, 1, 0, 0, 0, 123000000, time.UTC),
+		-2208988800000: time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for millis, expected := range timestamps {
+		actual, err := encode(func(enc *BinaryEncoder) { enc.WriteLong(millis) }).ReadTimestampMillis()
+		assert(t, err, nil)
+		assert(t, actual, expected)
+
+		actual, err = encode(func(enc *BinaryEncoder) { enc.WriteLong(millis * 1000) }).ReadTimestampMicros()
+		assert(t, err, nil)
+		assert(t, actual, expected)
+	}
+
+	micros, err := encode(func(enc *BinaryEncoder) { enc.WriteLong(-1) }).ReadTimestampMicros()
+	assert(t, err, nil)
+	assert(t, micros, time.Date(1969, 12, 31, 23, 59, 59, 999999000, time.UTC))
+
+	millis, err := encode(func(enc *BinaryEncoder) { enc.WriteInt(45296789) }).ReadTimeMillis()
+	assert(t, err, nil)
+	assert(t, millis, 12*time.Hour+34*time.Minute+56*time.Second+789*time.Millisecond)
+
+	timeMicros, err := encode(func(enc *BinaryEncoder) { enc.WriteLong(45296789012) }).ReadTimeMicros()
+	assert(t, err, nil)
+	assert(t, timeMicros, 12*time.Hour+34*time.Minute+56*time.Second+789012*time.Microsecond)
+
+	if _, err := NewBinaryDecoder(nil).ReadDate(); !errors.Is(err, EOF) {
+		t.Errorf("Unexpected error for empty date: expected %v, actual %v", EOF, err)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0x0E, 0x00, 0x00, 0x00, 0x1F, 0x00, 0x00, 0x00, 0x15, 0xCD, 0x5B, 0x07, 0x01})
+	months, days, millis, err := dec.ReadDuration()
+	assert(t, err, nil)
+	assert(t, months, uint32(14))
+	assert(t, days, uint32(31))
+	assert(t, millis, uint32(123456789))
+	assert(t, dec.Tell(), int64(12))
+
+	dec = NewBinaryDecoder([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	months, _, _, err = dec.ReadDuration()
+	assert(t, err, nil)
+	assert(t, months, uint32(4294967295))
+
+	if _, _, _, err := NewBinaryDecoder(make([]byte, 11)).ReadDuration(); !errors.Is(err, EOF) {
+		t.Errorf("Unexpected error for truncated duration: expected %v, actual %v", EOF, err)
+	}
+	if _, _, _, err := durationFromBytes(make([]byte, 16)); err == nil {
+		t.Error("Expected an error for a duration that is not 12 bytes long")
+	}
+}
+
+func TestUUID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	NewBinaryEncoder(buf).WriteString("123e4567-e89b-12d3-A456-426614174000")
+	uuid, err := NewBinaryDecoder(buf.Bytes()).ReadUUID()
+	assert(t, err, nil)
+	assert(t, uuid, [16]byte{0x12, 0x3E, 0x45, 0x67, 0xE8, 0x9B, 0x12, 0xD3, 0xA4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00})
+
+	invalid := []string{
+		"",
+		"123e4567e89b12d3a456426614174000",
+		"123e4567-e89b-12d3-a456-42661417400",
+		"123e4567-e89b-12d3-a456_426614174000",
+		"123e4567-e89b-12d3-a456-42661417400g",
+		"{123e4567-e89b-12d3-a456-42661417400}",
+	}
+	for _, str := range invalid {
+		buf := &bytes.Buffer{}
+		NewBinaryEncoder(buf).WriteString(str)
+		if _, err := NewBinaryDecoder(buf.Bytes()).ReadUUID(); !errors.Is(err, InvalidUUID) {
+			t.Errorf("Unexpected error for %q: expected %v, actual %v", str, InvalidUUID, err)
+		}
+	}
+}
+
+func TestPrimitiveValue(t *testing.T) {
+	decimal := MustParseSchema(`{"type": "bytes", "logicalType": "decimal", "precision": 5, "scale": 2}`)
+	for _, encoded := range [][]byte{{0x00}, {0x7F}, {0x00, 0x80}, {0xFF}, {0x80}, {0xFF, 0x7F}, {0x01, 0x00}} {
+		assert(t, primitiveValue(decimal, decimalFromBytes(encoded, 2)), encoded)
+	}
+	// a value with more digits than the scale allows is left as it is
+	third := big.NewRat(1, 3)
+	assert(t, primitiveValue(decimal, third), third)
+
+	fixed := MustParseSchema(`{"type": "fixed", "name": "Price", "size": 2, "logicalType": "decimal", "scale": 1}`)
+	assert(t, primitiveValue(fixed, big.NewRat(-1, 10)), []byte{0xFF, 0xFF})
+	tooLarge := big.NewRat(1<<20, 1)
+	assert(t, primitiveValue(fixed, tooLarge), tooLarge)
+
+	date := MustParseSchema(`{"type": "int", "logicalType": "date"}`)
+	for _, days := range []int32{0, 1, -1, 365, -365} {
+		assert(t, primitiveValue(date, dateFromDays(days)), days)
+	}
+	timestamp := MustParseSchema(`{"type": "long", "logicalType": "timestamp-micros"}`)
+	for _, micros := range []int64{0, 1, -1, 1500, -1500} {
+		assert(t, primitiveValue(timestamp, timestampFromMicros(micros)), micros)
+	}
+
+	// values of other types or schemas without a logical type are left as they are
+	assert(t, primitiveValue(date, "text"), "text")
+	now := time.Now()
+	assert(t, primitiveValue(MustParseSchema(`"int"`), now), now)
+}
+
+func TestGenericDatumReaderLogicalTypes(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Logical", "fields": [
+		{"name": "price", "type": {"type": "bytes", "logicalType": "decimal", "precision": 5, "scale": 2}},
+		{"name": "fixedPrice", "type": {"type": "fixed", "name": "Price", "size": 2, "logicalType": "decimal", "scale": 1}},
+		{"name": "day", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "timeMillis", "type": {"type": "int", "logicalType": "time-millis"}},
+		{"name": "timeMicros", "type": {"type": "long", "logicalType": "time-micros"}},
+		{"name": "createdMillis", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "createdMicros", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+		{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "period", "type": {"type": "fixed", "name": "Period", "size": 12, "logicalType": "duration"}},
+		{"name": "unknown", "type": {"type": "long", "logicalType": "unknown"}},
+		{"name": "optionalDay", "type": ["null", {"type": "int", "logicalType": "date"}]}
+	]}`)
+
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteBytes([]byte{0x01, 0x00})
+	enc.WriteRaw([]byte{0xFF, 0x38})
+	enc.WriteInt(365)
+	enc.WriteInt(1500)
+	enc.WriteLong(2500)
+	enc.WriteLong(1500)
+	enc.WriteLong(-1)
+	enc.WriteString("123e4567-e89b-12d3-a456-426614174000")
+	enc.WriteRaw([]byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0})
+	enc.WriteLong(42)
+	enc.WriteLong(1)
+	enc.WriteInt(1)
+
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	record := NewGenericRecord(schema)
+	assert(t, reader.Read(record, NewBinaryDecoder(buf.Bytes())), nil)
+
+	assert(t, record.Get("price").(*big.Rat).RatString(), "64/25")
+	assert(t, record.Get("fixedPrice").(*big.Rat).RatString(), "-20")
+	assert(t, record.Get("day"), time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert(t, record.Get("timeMillis"), 1500*time.Millisecond)
+	assert(t, record.Get("timeMicros"), 2500*time.Microsecond)
+	assert(t, record.Get("createdMillis"), time.Date(1970, 1, 1, 0, 0, 1, 5e8, time.UTC))
+	assert(t, record.Get("createdMicros"), time.Date(1969, 12, 31, 23, 59, 59, 999999000, time.UTC))
+	assert(t, record.Get("id"), [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00})
+	assert(t, record.Get("period"), Duration{Months: 1, Days: 2, Millis: 3})
+	assert(t, record.Get("unknown"), int64(42))
+	assert(t, record.Get("optionalDay"), time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	// the native values are written back as they were read
+	written := &bytes.Buffer{}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	assert(t, writer.Write(record, NewBinaryEncoder(written)), nil)
+	assert(t, written.Bytes(), buf.Bytes())
+
+	schemaJSON := schema.String()
+	decoded, err := DecodeToMap(schemaJSON, buf.Bytes())
+	assert(t, err, nil)
+	encoded, err := EncodeFromMap(schemaJSON, decoded)
+	assert(t, err, nil)
+	assert(t, encoded, buf.Bytes())
+
+	reader.SetLogicalTypes(false)
+	record = NewGenericRecord(schema)
+	assert(t, reader.Read(record, NewBinaryDecoder(buf.Bytes())), nil)
+
+	assert(t, record.Get("price"), []byte{0x01, 0x00})
+	assert(t, record.Get("fixedPrice"), []byte{0xFF, 0x38})
+	assert(t, record.Get("day"), int32(365))
+	assert(t, record.Get("timeMillis"), int32(1500))
+	assert(t, record.Get("timeMicros"), int64(2500))
+	assert(t, record.Get("createdMillis"), int64(1500))
+	assert(t, record.Get("createdMicros"), int64(-1))
+	assert(t, record.Get("id"), "123e4567-e89b-12d3-a456-426614174000")
+	assert(t, record.Get("period"), []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0})
+	assert(t, record.Get("optionalDay"), int32(1))
+}
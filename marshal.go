@@ -0,0 +1,204 @@
+package avro
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// Encodes a given Go value according to a given Schema using reflection and returns the Avro binary encoded data.
+// Struct fields are mapped to record fields the same way SpecificDatumWriter does it, either by exported name
+// or by `avro:"field_name"` struct tags. Slices are written as arrays, maps as maps and nil pointers as the null type
+// of nullable unions. May return an error if the value does not match the schema.
+func Marshal(schema Schema, v interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := NewSpecificDatumWriter()
+	writer.SetSchema(schema)
+	if err := writer.Write(v, NewBinaryEncoder(buffer)); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Decodes given Avro binary encoded data according to a given Schema into a Go value using reflection.
+// Given value MUST be a pointer to a struct and is filled the same way SpecificDatumReader does it.
+// May return an error if the data is malformed or does not fit the value.
+func Unmarshal(schema Schema, data []byte, v interface{}) error {
+	reader := NewSpecificDatumReader()
+	reader.SetSchema(schema)
+	return reader.Read(v, NewBinaryDecoder(data))
+}
+
+// Decodes given Avro binary encoded data holding a single record of a given JSON schema into a map from field names
+// to values. Values have the types GenericDatumReader reads, except that nested records are maps as well and enums
+// are their symbols. May return an error if the schema is not a valid record schema or the data is malformed.
+func DecodeToMap(schemaJSON string, data []byte) (map[string]interface{}, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Type() != Record {
+		return nil, fmt.Errorf("Expected a record schema, got %s", schema.GetName())
+	}
+
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	record := NewGenericRecord(schema)
+	if err := reader.Read(record, NewBinaryDecoder(data)); err != nil {
+		return nil, err
+	}
+
+	return genericToMap(record).(map[string]interface{}), nil
+}
+
+// Decodes all values of a given Schema from given Avro binary encoded data holding the values one after another
+// without any framing. Records are read as *GenericRecord, other values have the types GenericDatumReader reads.
+// Returns the values read so far and an error if the data ends in the middle of a value or holds bytes that are
+// not a value of the schema. Returns TrailingBytes if data is left but values of the schema take no bytes.
+func DecodeAll(schema Schema, data []byte) ([]interface{}, error) {
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	dec := NewBinaryDecoder(data)
+
+	var values []interface{}
+	for dec.Remaining() > 0 {
+		start := dec.Tell()
+		var value interface{}
+		var err error
+		if schema.Type() == Record {
+			record := NewGenericRecord(schema)
+			err = reader.Read(record, dec)
+			value = record
+		} else {
+			err = reader.Read(&value, dec)
+		}
+		if err != nil {
+			return values, err
+		}
+		// values taking no bytes, e.g. nulls or empty records, would be read from the remaining data forever
+		if dec.Tell() == start {
+			return values, fmt.Errorf("%w: %d bytes left at position %d but values of %s take no bytes", TrailingBytes, dec.Remaining(), start, schema.GetName())
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// converts GenericRecords in a given value read by GenericDatumReader to maps and GenericEnums to their symbols
+func genericToMap(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *GenericRecord:
+		fields := make(map[string]interface{}, len(v.fields))
+		for name, field := range v.fields {
+			fields[name] = genericToMap(field)
+		}
+		return fields
+	case *GenericEnum:
+		return v.Get()
+	case []interface{}:
+		for i, item := range v {
+			v[i] = genericToMap(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = genericToMap(item)
+		}
+	}
+	return value
+}
+
+// Encodes a given map from field names to values as a record of a given JSON schema and returns the Avro binary
+// encoded data. Values have the types GenericDatumWriter writes, except that nested records may be maps as well and
+// Go ints are accepted for int and long values. Union branches are selected by value: nil selects the null branch and
+// other values the first branch they fit, unless a UnionValue names the branch. Fields missing in the map are written
+// with their defaults.
+// Returns NoMatchingUnionBranch if a value fits no union branch or InvalidDatum if it does not fit its schema.
+func EncodeFromMap(schemaJSON string, data map[string]interface{}) ([]byte, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Type() != Record {
+		return nil, fmt.Errorf("Expected a record schema, got %s", schema.GetName())
+	}
+
+	record, err := mapToGeneric(schema, data, schema.GetName())
+	if err != nil {
+		return nil, err
+	}
+	buffer := &bytes.Buffer{}
+	if err := NewBinaryEncoder(buffer).WriteDatum(schema, record); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// converts maps in a given value to GenericRecords where a given schema expects records, Go ints to int32 or int64
+// and selects union branches, so that the result can be written with GenericDatumWriter
+func mapToGeneric(schema Schema, value interface{}, path string) (interface{}, error) {
+	switch s := actualSchema(schema).(type) {
+	case *IntSchema:
+		if v, ok := value.(int); ok && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int32(v), nil
+		}
+	case *LongSchema:
+		if v, ok := value.(int); ok {
+			return int64(v), nil
+		}
+	case *ArraySchema:
+		if v, ok := value.([]interface{}); ok {
+			items := make([]interface{}, len(v))
+			for i, item := range v {
+				var err error
+				if items[i], err = mapToGeneric(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return nil, err
+				}
+			}
+			return items, nil
+		}
+	case *MapSchema:
+		if v, ok := value.(map[string]interface{}); ok {
+			values := make(map[string]interface{}, len(v))
+			for key, item := range v {
+				var err error
+				if values[key], err = mapToGeneric(s.Values, item, fmt.Sprintf("%s[%q]", path, key)); err != nil {
+					return nil, err
+				}
+			}
+			return values, nil
+		}
+	case *RecordSchema:
+		if v, ok := value.(map[string]interface{}); ok {
+			record := NewGenericRecord(s)
+			for _, field := range s.Fields {
+				if fieldValue, ok := v[field.Name]; ok {
+					converted, err := mapToGeneric(field.Type, fieldValue, path+"."+field.Name)
+					if err != nil {
+						return nil, err
+					}
+					record.Set(field.Name, converted)
+				}
+			}
+			return record, nil
+		}
+	case *UnionSchema:
+		if hinted, ok := value.(UnionValue); ok {
+			for _, branch := range s.Types {
+				if isBranchNamed(branch, hinted.Type) {
+					converted, err := mapToGeneric(branch, hinted.Value, path)
+					return UnionValue{Type: hinted.Type, Value: converted}, err
+				}
+			}
+			return nil, fmt.Errorf("%w at %s: no branch named %s", NoMatchingUnionBranch, path, hinted.Type)
+		}
+		for _, branch := range s.Types {
+			if converted, err := mapToGeneric(branch, value, path); err == nil && validateDatum(branch, converted, path) == nil {
+				return converted, nil
+			}
+		}
+		return nil, fmt.Errorf("%w at %s: %v (%T)", NoMatchingUnionBranch, path, value, value)
+	}
+	return value, nil
+}
@@ -0,0 +1,247 @@
+package avro
+
+import (
+	"reflect"
+	"testing"
+)
+
+const marshalTestSchema = `{
+    "type": "record",
+    "name": "Person",
+    "fields": [
+        {"name": "name", "type": "string"},
+        {"name": "age", "type": "int"},
+        {"name": "emails", "type": {"type": "array", "items": "string"}},
+        {"name": "scores", "type": {"type": "map", "values": "double"}},
+        {"name": "address", "type": {"type": "record", "name": "Address", "fields": [
+            {"name": "street", "type": "string"},
+            {"name": "zip_code", "type": "int"}
+        ]}},
+        {"name": "previous", "type": {"type": "array", "items": "Address"}},
+        {"name": "nickname", "type": ["null", "string"]},
+        {"name": "manager", "type": ["null", "Person"]}
+    ]
+}`
+
+type marshalAddress struct {
+	Street  string
+	ZipCode int32 `avro:"zip_code"`
+}
+
+type marshalPerson struct {
+	FullName string `avro:"name"`
+	Age      int32
+	Emails   []string
+	Scores   map[string]float64
+	Address  *marshalAddress
+	Previous []*marshalAddress
+	Nickname *string
+	Manager  *marshalPerson
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	schema := MustParseSchema(marshalTestSchema)
+	nickname := "jd"
+	person := &marshalPerson{
+		FullName: "John Doe",
+		Age:      42,
+		Emails:   []string{"john@example.com", "doe@example.com"},
+		Scores:   map[string]float64{"math": 4.5},
+		Address:  &marshalAddress{Street: "Main St", ZipCode: 12345},
+		Previous: []*marshalAddress{{Street: "Old St", ZipCode: 1}},
+		Nickname: &nickname,
+		Manager: &marshalPerson{
+			FullName: "Jane Roe",
+			Emails:   []string{"jane@example.com"},
+			Scores:   map[string]float64{"art": 5},
+			Address:  &marshalAddress{Street: "Side St"},
+			Previous: []*marshalAddress{},
+		},
+	}
+
+	data, err := Marshal(schema, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &marshalPerson{}
+	if err := Unmarshal(schema, data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, person) {
+		t.Fatalf("Unexpected decoded value: %+v", decoded)
+	}
+}
+
+func TestMarshalNullUnions(t *testing.T) {
+	schema := MustParseSchema(marshalTestSchema)
+	person := &marshalPerson{
+		FullName: "John Doe",
+		Emails:   []string{},
+		Scores:   map[string]float64{},
+		Address:  &marshalAddress{},
+		Previous: []*marshalAddress{},
+	}
+
+	data, err := Marshal(schema, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nickname := "stale"
+	decoded := &marshalPerson{Nickname: &nickname, Manager: &marshalPerson{}}
+	if err := Unmarshal(schema, data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, decoded.Nickname, (*string)(nil))
+	assert(t, decoded.Manager, (*marshalPerson)(nil))
+	assert(t, decoded.FullName, "John Doe")
+
+	assert(t, Unmarshal(schema, data, *decoded) != nil, true)
+}
+
+func TestDecodeToMap(t *testing.T) {
+	schema := MustParseSchema(marshalTestSchema)
+	nickname := "jd"
+	data, err := Marshal(schema, &marshalPerson{
+		FullName: "John Doe",
+		Age:      42,
+		Emails:   []string{"john@example.com"},
+		Scores:   map[string]float64{"math": 4.5},
+		Address:  &marshalAddress{Street: "Main St", ZipCode: 12345},
+		Previous: []*marshalAddress{{Street: "Old St", ZipCode: 1}},
+		Nickname: &nickname,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeToMap(marshalTestSchema, data)
+	assert(t, err, nil)
+	assert(t, decoded, map[string]interface{}{
+		"name":     "John Doe",
+		"age":      int32(42),
+		"emails":   []interface{}{"john@example.com"},
+		"scores":   map[string]interface{}{"math": 4.5},
+		"address":  map[string]interface{}{"street": "Main St", "zip_code": int32(12345)},
+		"previous": []interface{}{map[string]interface{}{"street": "Old St", "zip_code": int32(1)}},
+		"nickname": "jd",
+		"manager":  nil,
+	})
+
+	_, err = DecodeToMap(marshalTestSchema, data[:len(data)-1])
+	assertError(t, err, EOF)
+	_, err = DecodeToMap(`"string"`, data)
+	assert(t, err != nil, true)
+	_, err = DecodeToMap(`{"type": "unknown"}`, data)
+	assert(t, err != nil, true)
+}
+
+func TestDecodeAll(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Address", "fields": [
+		{"name": "street", "type": "string"},
+		{"name": "zip_code", "type": "int"}
+	]}`)
+	var data []byte
+	for _, address := range []*marshalAddress{{"Main St", 1}, {"Old St", 2}, {"Side St", 3}} {
+		encoded, err := Marshal(schema, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, encoded...)
+	}
+
+	values, err := DecodeAll(schema, data)
+	assert(t, err, nil)
+	assert(t, len(values), 3)
+	for i, street := range []string{"Main St", "Old St", "Side St"} {
+		record := values[i].(*GenericRecord)
+		assert(t, record.Get("street"), street)
+		assert(t, record.Get("zip_code"), int32(i+1))
+	}
+
+	// a string length prefix pointing past the end of the data
+	values, err = DecodeAll(schema, append(data, 0x08, 'x'))
+	assertError(t, err, UnexpectedEOF)
+	assert(t, len(values), 3)
+
+	values, err = DecodeAll(MustParseSchema(`"long"`), []byte{0x02, 0x04, 0x06})
+	assert(t, err, nil)
+	assert(t, values, []interface{}{int64(1), int64(2), int64(3)})
+
+	values, err = DecodeAll(schema, nil)
+	assert(t, err, nil)
+	assert(t, len(values), 0)
+
+	// values taking no bytes cannot consume the remaining data
+	values, err = DecodeAll(MustParseSchema(`{"type":"record","name":"E","fields":[]}`), []byte{0x00})
+	assertError(t, err, TrailingBytes)
+	assert(t, len(values), 0)
+	values, err = DecodeAll(MustParseSchema(`"null"`), []byte{0x00, 0x00})
+	assertError(t, err, TrailingBytes)
+	assert(t, len(values), 0)
+}
+
+func TestEncodeFromMap(t *testing.T) {
+	person := map[string]interface{}{
+		"name":     "John Doe",
+		"age":      42,
+		"emails":   []interface{}{"john@example.com"},
+		"scores":   map[string]interface{}{"math": 4.5},
+		"address":  map[string]interface{}{"street": "Main St", "zip_code": int32(12345)},
+		"previous": []interface{}{},
+		"nickname": nil,
+		"manager": map[string]interface{}{
+			"name":     "Jane Roe",
+			"age":      int32(50),
+			"emails":   []interface{}{},
+			"scores":   map[string]interface{}{},
+			"address":  map[string]interface{}{"street": "Side St", "zip_code": 1},
+			"previous": []interface{}{map[string]interface{}{"street": "Old St", "zip_code": 2}},
+			"nickname": "jr",
+			"manager":  nil,
+		},
+	}
+
+	data, err := EncodeFromMap(marshalTestSchema, person)
+	assert(t, err, nil)
+
+	decoded := &marshalPerson{}
+	assert(t, Unmarshal(MustParseSchema(marshalTestSchema), data, decoded), nil)
+	nickname := "jr"
+	assert(t, decoded, &marshalPerson{
+		FullName: "John Doe",
+		Age:      42,
+		Emails:   []string{"john@example.com"},
+		Scores:   map[string]float64{"math": 4.5},
+		Address:  &marshalAddress{Street: "Main St", ZipCode: 12345},
+		Previous: []*marshalAddress{},
+		Manager: &marshalPerson{
+			FullName: "Jane Roe",
+			Age:      50,
+			Emails:   []string{},
+			Scores:   map[string]float64{},
+			Address:  &marshalAddress{Street: "Side St", ZipCode: 1},
+			Previous: []*marshalAddress{{Street: "Old St", ZipCode: 2}},
+			Nickname: &nickname,
+		},
+	})
+
+	roundTrip, err := DecodeToMap(marshalTestSchema, data)
+	assert(t, err, nil)
+	reencoded, err := EncodeFromMap(marshalTestSchema, roundTrip)
+	assert(t, err, nil)
+	assert(t, reencoded, data)
+
+	person["nickname"] = 5
+	_, err = EncodeFromMap(marshalTestSchema, person)
+	assertError(t, err, NoMatchingUnionBranch)
+	person["nickname"] = "jd"
+	person["manager"] = map[string]interface{}{"name": 1}
+	_, err = EncodeFromMap(marshalTestSchema, person)
+	assertError(t, err, NoMatchingUnionBranch)
+	person["manager"] = nil
+	person["age"] = "42"
+	_, err = EncodeFromMap(marshalTestSchema, person)
+	assertError(t, err, InvalidDatum)
+}
@@ -0,0 +1,141 @@
+package avro
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+// ParallelReader reads all values of an Avro Object Container File by decoding its blocks concurrently with a number
+// of worker goroutines. Blocks are found with a DataFileBlockReader and every worker decodes whole blocks with its
+// own BinaryDecoder taken from a DecoderPool. Values are returned in file order unless SetOrdered(false) is called.
+type ParallelReader struct {
+	blocks    *DataFileBlockReader
+	workers   int
+	unordered bool
+}
+
+// Creates a new ParallelReader for a data file of a given size in bytes readable from a given io.ReaderAt and using
+// the given DatumReader to read values. The DatumReader is shared by all workers so it must be safe for concurrent
+// use, which GenericDatumReader is. May return an error if the file contains invalid data.
+func NewParallelReader(r io.ReaderAt, size int64, datumReader DatumReader) (*ParallelReader, error) {
+	blocks, err := NewDataFileBlockReader(r, size, datumReader)
+	if err != nil {
+		return nil, err
+	}
+	return &ParallelReader{
+		blocks:  blocks,
+		workers: runtime.NumCPU(),
+	}, nil
+}
+
+// Sets the number of goroutines decoding blocks concurrently. Values less than 1 mean 1. Defaults to the number
+// of CPUs.
+func (this *ParallelReader) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	this.workers = workers
+}
+
+// Sets whether values are returned in the order they appear in the file. Returning values in the order their blocks
+// are decoded instead avoids holding back blocks decoded ahead of a slower one. Defaults to true.
+func (this *ParallelReader) SetOrdered(ordered bool) {
+	this.unordered = !ordered
+}
+
+// Reads all values of the file, each into a new pointer returned by newValue, and passes them to a given function
+// one at a time from the calling goroutine. Returning an error from the function stops reading. Returns the error
+// of the function or the first error decoding a block.
+func (this *ParallelReader) ForEach(newValue func() interface{}, fn func(v interface{}) error) error {
+	type blockValues struct {
+		index  int
+		values []interface{}
+		err    error
+	}
+
+	offsets := this.blocks.BlockOffsets()
+	indexes := make(chan int)
+	results := make(chan blockValues)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		defer close(indexes)
+		for index := range offsets {
+			select {
+			case indexes <- index:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	workers := &sync.WaitGroup{}
+	for i := 0; i < this.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				values, err := this.blocks.ReadBlockAt(offsets[index], newValue)
+				select {
+				case results <- blockValues{index: index, values: values, err: err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// blocks decoded ahead of the next block in file order
+	pending := make(map[int][]interface{})
+	next := 0
+	for result := range results {
+		if result.err != nil {
+			return result.err
+		}
+		if this.unordered {
+			if err := forEachValue(result.values, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pending[result.index] = result.values
+		for values, ok := pending[next]; ok; values, ok = pending[next] {
+			if err := forEachValue(values, fn); err != nil {
+				return err
+			}
+			delete(pending, next)
+			next++
+		}
+	}
+	return nil
+}
+
+// Reads all values of the file, each into a new pointer returned by newValue, like ForEach.
+// Returns the read values and the first error decoding a block.
+func (this *ParallelReader) ReadAll(newValue func() interface{}) ([]interface{}, error) {
+	var values []interface{}
+	err := this.ForEach(newValue, func(v interface{}) error {
+		values = append(values, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func forEachValue(values []interface{}, fn func(v interface{}) error) error {
+	for _, value := range values {
+		if err := fn(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
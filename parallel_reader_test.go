@@ -0,0 +1,93 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// writes a data file holding the values 0 to count-1 split into many blocks
+func writeParallelTestFile(t *testing.T, count int64) []byte {
+	buf := &bytes.Buffer{}
+	schema := MustParseSchema(dataFileTestSchema)
+	writer, err := NewDataFileWriter(buf, schema, NewGenericDatumWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.SetBlockSize(16)
+	for i := int64(0); i < count; i++ {
+		record := NewGenericRecord(schema)
+		record.Set("value", i)
+		assert(t, writer.Append(record), nil)
+	}
+	assert(t, writer.Close(), nil)
+	return buf.Bytes()
+}
+
+func newParallelTestRecord() interface{} {
+	return NewGenericRecord(MustParseSchema(dataFileTestSchema))
+}
+
+func TestParallelReader(t *testing.T) {
+	data := writeParallelTestFile(t, 1000)
+	var expected []int64
+	for i := int64(0); i < 1000; i++ {
+		expected = append(expected, i)
+	}
+
+	for _, workers := range []int{0, 1, 4, 16} {
+		reader, err := NewParallelReader(bytes.NewReader(data), int64(len(data)), NewGenericDatumReader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader.SetWorkers(workers)
+		values, err := reader.ReadAll(newParallelTestRecord)
+		assert(t, err, nil)
+		var actual []int64
+		for _, value := range values {
+			actual = append(actual, value.(*GenericRecord).Get("value").(int64))
+		}
+		assert(t, actual, expected)
+
+		reader.SetOrdered(false)
+		actual = nil
+		err = reader.ForEach(newParallelTestRecord, func(v interface{}) error {
+			actual = append(actual, v.(*GenericRecord).Get("value").(int64))
+			return nil
+		})
+		assert(t, err, nil)
+		sort.Slice(actual, func(i, j int) bool { return actual[i] < actual[j] })
+		assert(t, actual, expected)
+	}
+}
+
+func TestParallelReaderErrors(t *testing.T) {
+	data := writeParallelTestFile(t, 1000)
+	reader, err := NewParallelReader(bytes.NewReader(data), int64(len(data)), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.SetWorkers(4)
+	stop := errors.New("stop")
+	read := 0
+	err = reader.ForEach(newParallelTestRecord, func(v interface{}) error {
+		if read++; read == 10 {
+			return stop
+		}
+		return nil
+	})
+	assert(t, err, stop)
+	assert(t, read, 10)
+
+	// corrupt the sync marker following the first block
+	offsets := reader.blocks.BlockOffsets()
+	corrupted := append([]byte(nil), data...)
+	corrupted[offsets[1]-1] ^= 0xFF
+	reader, err = NewParallelReader(bytes.NewReader(corrupted), int64(len(corrupted)), NewGenericDatumReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = reader.ReadAll(newParallelTestRecord)
+	assert(t, err, SyncMismatch)
+}
@@ -0,0 +1,22 @@
+package avro
+
+// RawDatum holds a single value of a given schema in its encoded form, e.g. a record field a gateway forwards
+// without decoding it. GenericDatumWriter writes a RawDatum as is.
+type RawDatum struct {
+	Schema Schema
+	Bytes  []byte
+}
+
+// Reads a value of a given schema without decoding it by skipping it and keeping the bytes it took.
+// The bytes are copied so the RawDatum stays valid when the buffer of this BinaryDecoder is reused.
+// Returns the encoded value and an error if it occurs.
+func (this *BinaryDecoder) ReadRaw(schema Schema) (_ RawDatum, err error) {
+	defer this.wrapError("ReadRaw", this.pos, &err)
+	start := this.pos
+	if err := this.skipValue(schema); err != nil {
+		return RawDatum{}, err
+	}
+	bytes := make([]byte, this.pos-start)
+	copy(bytes, this.buf[start:this.pos])
+	return RawDatum{Schema: schema, Bytes: bytes}, nil
+}
@@ -0,0 +1,119 @@
+package avro
+
+import (
+	"bytes"
+	"testing"
+)
+
+const rawDatumTestSchema = `{"type":"record","name":"Message","fields":[
+	{"name":"route","type":"string"},
+	{"name":"payload","type":{"type":"record","name":"Payload","fields":[
+		{"name":"values","type":{"type":"array","items":"long"}},
+		{"name":"note","type":["null","string"]}
+	]}},
+	{"name":"attributes","type":{"type":"map","values":"string"}}
+]}`
+
+func TestReadRaw(t *testing.T) {
+	schema := MustParseSchema(rawDatumTestSchema)
+	payloadSchema := schema.(*RecordSchema).Fields[1].Type
+	payload := NewGenericRecord(payloadSchema)
+	payload.Set("values", []interface{}{int64(1), int64(2)})
+	payload.Set("note", "hello")
+
+	buf := &bytes.Buffer{}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(payloadSchema)
+	assert(t, writer.Write(payload, NewBinaryEncoder(buf)), nil)
+	encoded := append(buf.Bytes(), 0x2A)
+
+	dec := NewBinaryDecoder(encoded)
+	raw, err := dec.ReadRaw(payloadSchema)
+	assert(t, err, nil)
+	assert(t, raw.Schema, payloadSchema)
+	assert(t, raw.Bytes, encoded[:len(encoded)-1])
+	assert(t, dec.Tell(), int64(len(encoded)-1))
+
+	// the captured bytes decode to the original value
+	reader := NewGenericDatumReader()
+	reader.SetSchema(raw.Schema)
+	decoded := NewGenericRecord(payloadSchema)
+	assert(t, reader.Read(decoded, NewBinaryDecoder(raw.Bytes)), nil)
+	assert(t, decoded, payload)
+
+	_, err = NewBinaryDecoder(encoded[:3]).ReadRaw(payloadSchema)
+	assertError(t, err, EOF)
+}
+
+func TestGenericDatumReaderRawFields(t *testing.T) {
+	schema := MustParseSchema(rawDatumTestSchema)
+	payload := NewGenericRecord(schema.(*RecordSchema).Fields[1].Type)
+	payload.Set("values", []interface{}{int64(3)})
+	payload.Set("note", nil)
+	message := NewGenericRecord(schema)
+	message.Set("route", "orders")
+	message.Set("payload", payload)
+	message.Set("attributes", map[string]interface{}{"a": "b"})
+
+	buf := &bytes.Buffer{}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	assert(t, writer.Write(message, NewBinaryEncoder(buf)), nil)
+	encoded := buf.Bytes()
+
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	reader.SetRawFields("payload", "attributes")
+	routed := NewGenericRecord(schema)
+	assert(t, reader.Read(routed, NewBinaryDecoder(encoded)), nil)
+	assert(t, routed.Get("route"), "orders")
+	rawPayload := routed.Get("payload").(RawDatum)
+	assert(t, rawPayload.Schema, schema.(*RecordSchema).Fields[1].Type)
+
+	// the raw fields are forwarded untouched
+	forwarded := &bytes.Buffer{}
+	assert(t, writer.Write(routed, NewBinaryEncoder(forwarded)), nil)
+	assert(t, forwarded.Bytes(), encoded)
+
+	reader.SetRawFields()
+	decoded := NewGenericRecord(schema)
+	assert(t, reader.Read(decoded, NewBinaryDecoder(encoded)), nil)
+	assert(t, decoded.Get("payload"), payload)
+
+	reader.SetRawFields("payload")
+	assertError(t, reader.Read(NewGenericRecord(schema), NewStreamBinaryDecoder(bytes.NewReader(encoded))), RawDecoderRequired)
+}
+
+func TestWriteRaw(t *testing.T) {
+	schema := MustParseSchema(rawDatumTestSchema).(*RecordSchema)
+	payload := NewGenericRecord(schema.Fields[1].Type)
+	payload.Set("values", []interface{}{int64(-1), int64(300)})
+	payload.Set("note", "raw")
+	message := NewGenericRecord(schema)
+	message.Set("route", "orders")
+	message.Set("payload", payload)
+	message.Set("attributes", map[string]interface{}{"k": "v"})
+
+	buf := &bytes.Buffer{}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	assert(t, writer.Write(message, NewBinaryEncoder(buf)), nil)
+	encoded := buf.Bytes()
+
+	// rewrite the route and splice the other fields back in as they were read
+	dec := NewBinaryDecoder(encoded)
+	route, err := dec.ReadString()
+	assert(t, err, nil)
+	rawPayload, err := dec.ReadRaw(schema.Fields[1].Type)
+	assert(t, err, nil)
+	rawAttributes, err := dec.ReadRaw(schema.Fields[2].Type)
+	assert(t, err, nil)
+	assert(t, dec.AtEnd(), true)
+
+	rewritten := &bytes.Buffer{}
+	enc := NewBinaryEncoder(rewritten)
+	enc.WriteString(route)
+	enc.WriteRaw(rawPayload.Bytes)
+	enc.WriteRaw(rawAttributes.Bytes)
+	assert(t, rewritten.Bytes(), encoded)
+}
@@ -0,0 +1,642 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ResolvingDatumReader implements DatumReader and is used for reading data written with one schema (writer schema)
+// into GenericRecords and other generic values of another compatible schema (reader schema) according to the Avro
+// schema resolution rules: https://avro.apache.org/docs/current/spec.html#Schema+Resolution
+// Record fields are matched by name or alias so that fields may be reordered, fields missing in the reader schema
+// are skipped, fields missing in the writer schema are filled with their default values, enum symbols are matched
+// by name, union branches are matched by type and numeric values are promoted to wider types (int to long, float
+// or double, long to float or double and float to double) as well as strings to bytes and back.
+// The way to resolve the schemas is computed once when the writer schema is set and then reused for every Read.
+// Each value passed to Read is expected to be a pointer.
+type ResolvingDatumReader struct {
+	readerSchema Schema
+	plan         *resolution
+	err          error
+	generic      *GenericDatumReader
+}
+
+// Creates a new ResolvingDatumReader that reads values of a given reader schema.
+// The writer schema is provided with SetSchema.
+func NewResolvingDatumReader(readerSchema Schema) *ResolvingDatumReader {
+	return &ResolvingDatumReader{
+		readerSchema: readerSchema,
+		err:          SchemaNotSet,
+		generic:      NewGenericDatumReader(),
+	}
+}
+
+// Sets how deeply records, arrays and maps may be nested in values this ResolvingDatumReader reads, like
+// GenericDatumReader.SetMaxDepth does. Reading a value nested deeper returns MaxDepthExceeded. Defaults to 256.
+func (this *ResolvingDatumReader) SetMaxDepth(depth int) {
+	this.generic.SetMaxDepth(depth)
+}
+
+// Sets the schema the data was written with and resolves it against the reader schema.
+// If the schemas are not compatible every subsequent Read returns the resolution error.
+// Note that it must be called before calling Read.
+func (this *ResolvingDatumReader) SetSchema(writerSchema Schema) {
+	resolver := &resolver{records: make(map[[2]Schema]*resolution)}
+	this.plan, this.err = resolver.resolve(writerSchema, this.readerSchema)
+}
+
+// Reads a single entry written with the writer schema as a value of the reader schema.
+// Accepts a value to fill with data and a Decoder to read from. Given value MUST be of pointer type.
+// May return an error indicating a read failure or that the schemas are not compatible.
+func (this *ResolvingDatumReader) Read(v interface{}, dec Decoder) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("Not applicable for non-pointer types or nil")
+	}
+	rv = rv.Elem()
+	if this.err != nil {
+		return this.err
+	}
+
+	value, err := this.readValue(this.plan, dec, 0)
+	if err != nil {
+		return err
+	}
+
+	newValue := reflect.ValueOf(value)
+	if newValue.Kind() == reflect.Ptr {
+		newValue = newValue.Elem()
+	}
+	rv.Set(newValue)
+
+	return nil
+}
+
+// reads a value nested in a given number of records, arrays and maps
+func (this *ResolvingDatumReader) readValue(plan *resolution, dec Decoder, depth int) (interface{}, error) {
+	switch plan.kind {
+	case resolution_record, resolution_array, resolution_map:
+		if depth >= this.generic.maxDepth {
+			return nil, MaxDepthExceeded
+		}
+	}
+
+	switch plan.kind {
+	case resolution_record:
+		return this.readRecord(plan, dec, depth+1)
+	case resolution_array:
+		return this.readArray(plan, dec, depth+1)
+	case resolution_map:
+		return this.readMap(plan, dec, depth+1)
+	case resolution_enum:
+		return this.readEnum(plan, dec)
+	case resolution_writer_union:
+		return this.readWriterUnion(plan, dec, depth)
+	case resolution_reader_union:
+		return this.readValue(plan.items, dec, depth)
+	case resolution_promotion:
+		return this.readPromotion(plan, dec)
+	}
+	return this.generic.readValueAt(plan.writer, dec, depth)
+}
+
+func (this *ResolvingDatumReader) readRecord(plan *resolution, dec Decoder, depth int) (*GenericRecord, error) {
+	record := NewGenericRecord(plan.reader)
+	for _, field := range plan.fields {
+		if field.plan == nil {
+			// the field is unknown to the reader schema and is skipped without decoding when possible
+			if err := this.generic.Skip(field.writer, dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		value, err := this.readValue(field.plan, dec, depth)
+		if err != nil {
+			return nil, err
+		}
+		if enum, ok := value.(*GenericEnum); ok {
+			value = enum.Get()
+		}
+		record.Set(field.name, value)
+	}
+
+	// defaults are converted on every read as records must not share mutable values such as maps or slices
+	for _, field := range plan.defaults {
+		value, err := fieldDefault(field)
+		if err != nil {
+			return nil, err
+		}
+		record.Set(field.Name, value)
+	}
+
+	return record, nil
+}
+
+func (this *ResolvingDatumReader) readArray(plan *resolution, dec Decoder, depth int) ([]interface{}, error) {
+	array := make([]interface{}, 0)
+	count, err := dec.ReadArrayStart()
+	for ; count > 0 && err == nil; count, err = dec.ArrayNext() {
+		for i := int64(0); i < count; i++ {
+			value, err := this.readValue(plan.items, dec, depth)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return array, nil
+}
+
+func (this *ResolvingDatumReader) readMap(plan *resolution, dec Decoder, depth int) (map[string]interface{}, error) {
+	resultMap := make(map[string]interface{})
+	count, err := dec.ReadMapStart()
+	for ; count > 0 && err == nil; count, err = dec.MapNext() {
+		for i := int64(0); i < count; i++ {
+			key, err := dec.ReadString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := this.readValue(plan.items, dec, depth)
+			if err != nil {
+				return nil, err
+			}
+			resultMap[key] = value
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resultMap, nil
+}
+
+func (this *ResolvingDatumReader) readEnum(plan *resolution, dec Decoder) (*GenericEnum, error) {
+	index, err := dec.ReadEnum()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || int(index) >= len(plan.symbols) {
+		return nil, EnumIndexOutOfRange
+	}
+	if plan.symbols[index] < 0 {
+		return nil, UnknownEnumSymbol
+	}
+	enum := NewGenericEnum(plan.reader.(*EnumSchema).Symbols)
+	enum.SetIndex(plan.symbols[index])
+	return enum, nil
+}
+
+func (this *ResolvingDatumReader) readWriterUnion(plan *resolution, dec Decoder, depth int) (interface{}, error) {
+	index, err := dec.ReadInt()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || int(index) >= len(plan.branches) {
+		return nil, UnionIndexOutOfRange
+	}
+	// branches not matching the reader schema fail only when they are actually read
+	if plan.branchErrors[index] != nil {
+		return nil, plan.branchErrors[index]
+	}
+	return this.readValue(plan.branches[index], dec, depth)
+}
+
+func (this *ResolvingDatumReader) readPromotion(plan *resolution, dec Decoder) (interface{}, error) {
+	// the writer value is promoted as its primitive type, a logical type of the writer schema does not apply to it
+	value, err := this.generic.readBaseValue(plan.writer, dec, 0)
+	if err != nil {
+		return nil, err
+	}
+	promoted, err := promote(value, plan.reader.Type())
+	if err != nil || this.generic.rawLogicalTypes {
+		return promoted, err
+	}
+	return logicalValue(plan.reader, promoted), nil
+}
+
+// converts a primitive value of a writer type to a wider reader type
+func promote(value interface{}, readerType int) (interface{}, error) {
+	var number float64
+	switch v := value.(type) {
+	case int32:
+		if readerType == Long {
+			return int64(v), nil
+		}
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case float32:
+		number = float64(v)
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return string(v), nil
+	default:
+		return nil, fmt.Errorf("Cannot promote %v (%T): %w", value, value, IncompatiblePromotion)
+	}
+
+	if readerType == Float {
+		return float32(number), nil
+	}
+	return number, nil
+}
+
+const (
+	// the writer value is read as is
+	resolution_value = iota
+	resolution_record
+	resolution_array
+	resolution_map
+	resolution_enum
+	// a union written by the writer is resolved per branch
+	resolution_writer_union
+	// a non-union writer value is read into the matching branch of a reader union
+	resolution_reader_union
+	// a writer value is converted to a wider reader type
+	resolution_promotion
+)
+
+// reader types each writer type may be promoted to
+var promotions = map[int][]int{
+	Int:    {Long, Float, Double},
+	Long:   {Float, Double},
+	Float:  {Double},
+	String: {Bytes},
+	Bytes:  {String},
+}
+
+// resolution describes how to read a value written with a writer schema as a value of a reader schema.
+type resolution struct {
+	kind   int
+	writer Schema
+	reader Schema
+
+	// fields of a record in writer order and reader fields missing in the writer schema
+	fields   []*fieldResolution
+	defaults []*SchemaField
+
+	// resolution of array items, map values or the matching reader union branch
+	items *resolution
+
+	// resolution of each writer union branch
+	branches     []*resolution
+	branchErrors []error
+
+	// reader enum symbol index for each writer enum symbol index or -1 if the reader can not resolve the symbol
+	symbols []int32
+}
+
+type fieldResolution struct {
+	name   string
+	writer Schema
+	plan   *resolution
+}
+
+type resolver struct {
+	// record resolutions by writer and reader schema so that recursive schemas are resolved only once
+	records map[[2]Schema]*resolution
+}
+
+func (this *resolver) resolve(writer Schema, reader Schema) (*resolution, error) {
+	if writer == nil || reader == nil {
+		return nil, SchemaNotSet
+	}
+	writer, reader = actualSchema(writer), actualSchema(reader)
+	plan := &resolution{kind: resolution_value, writer: writer, reader: reader}
+
+	if writerUnion, ok := writer.(*UnionSchema); ok {
+		plan.kind = resolution_writer_union
+		for _, branch := range writerUnion.Types {
+			branchPlan, err := this.resolve(branch, reader)
+			plan.branches = append(plan.branches, branchPlan)
+			plan.branchErrors = append(plan.branchErrors, err)
+		}
+		return plan, nil
+	}
+
+	if readerUnion, ok := reader.(*UnionSchema); ok {
+		// a branch of the same type is preferred over one the writer type may be promoted to
+		for _, matches := range []func(Schema, Schema) bool{schemasMatch, isPromotable} {
+			for _, branch := range readerUnion.Types {
+				if matches(writer, actualSchema(branch)) {
+					branchPlan, err := this.resolve(writer, branch)
+					if err != nil {
+						return nil, err
+					}
+					plan.kind = resolution_reader_union
+					plan.items = branchPlan
+					return plan, nil
+				}
+			}
+		}
+		return nil, incompatibleSchemas(writer, reader)
+	}
+
+	if isPromotable(writer, reader) {
+		plan.kind = resolution_promotion
+		return plan, nil
+	}
+	if !schemasMatch(writer, reader) {
+		if isNumeric(writer) && isNumeric(reader) {
+			return nil, IncompatiblePromotion
+		}
+		return nil, incompatibleSchemas(writer, reader)
+	}
+
+	switch reader.Type() {
+	case Record:
+		return this.resolveRecord(writer.(*RecordSchema), reader.(*RecordSchema))
+	case Array:
+		plan.kind = resolution_array
+		items, err := this.resolve(writer.(*ArraySchema).Items, reader.(*ArraySchema).Items)
+		plan.items = items
+		return plan, err
+	case Map:
+		plan.kind = resolution_map
+		values, err := this.resolve(writer.(*MapSchema).Values, reader.(*MapSchema).Values)
+		plan.items = values
+		return plan, err
+	case Enum:
+		plan.kind = resolution_enum
+		readerEnum := reader.(*EnumSchema)
+		for _, symbol := range writer.(*EnumSchema).Symbols {
+			// symbols unknown to the reader resolve to the reader default if there is one
+			plan.symbols = append(plan.symbols, enumIndex(readerEnum, symbol, enumIndex(readerEnum, readerEnum.Default, -1)))
+		}
+	}
+
+	return plan, nil
+}
+
+func (this *resolver) resolveRecord(writer *RecordSchema, reader *RecordSchema) (*resolution, error) {
+	key := [2]Schema{writer, reader}
+	if plan, ok := this.records[key]; ok {
+		return plan, nil
+	}
+	plan := &resolution{kind: resolution_record, writer: writer, reader: reader}
+	this.records[key] = plan
+
+	matched := make(map[*SchemaField]bool)
+	for _, writerField := range writer.Fields {
+		field := &fieldResolution{writer: writerField.Type}
+		if readerField := findReaderField(reader, writerField.Name); readerField != nil {
+			fieldPlan, err := this.resolve(writerField.Type, readerField.Type)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot resolve field %s of %s: %v", readerField.Name, reader.Name, err)
+			}
+			field.name = readerField.Name
+			field.plan = fieldPlan
+			matched[readerField] = true
+		}
+		plan.fields = append(plan.fields, field)
+	}
+
+	for _, readerField := range reader.Fields {
+		if matched[readerField] {
+			continue
+		}
+		// the default is checked once here and converted again on every read
+		if _, err := fieldDefault(readerField); err != nil {
+			return nil, err
+		}
+		plan.defaults = append(plan.defaults, readerField)
+	}
+
+	return plan, nil
+}
+
+// finds a field of a reader record by the name of a writer field or one of the reader field aliases
+func findReaderField(reader *RecordSchema, name string) *SchemaField {
+	for _, field := range reader.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	for _, field := range reader.Fields {
+		for _, alias := range field.Aliases {
+			if alias == name {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// tells whether a writer schema may be read as a given non-union reader schema
+func schemasMatch(writer Schema, reader Schema) bool {
+	if writer.Type() != reader.Type() {
+		return false
+	}
+
+	switch r := reader.(type) {
+	case *RecordSchema:
+		return namesMatch(writer.GetName(), r.Name, r.Aliases)
+	case *EnumSchema:
+		return namesMatch(writer.GetName(), r.Name, r.Aliases)
+	case *FixedSchema:
+		return namesMatch(writer.GetName(), r.Name, r.Aliases) && writer.(*FixedSchema).Size == r.Size
+	}
+	return true
+}
+
+// returns the index of a given symbol in an enum or a fallback index if the enum does not have that symbol
+func enumIndex(enum *EnumSchema, symbol string, fallback int32) int32 {
+	for i, s := range enum.Symbols {
+		if s == symbol {
+			return int32(i)
+		}
+	}
+	return fallback
+}
+
+// tells whether a writer schema value may be converted to a wider reader schema type
+func isPromotable(writer Schema, reader Schema) bool {
+	for _, promoted := range promotions[writer.Type()] {
+		if promoted == reader.Type() {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumeric(schema Schema) bool {
+	switch schema.Type() {
+	case Int, Long, Float, Double:
+		return true
+	}
+	return false
+}
+
+// named types match by their unqualified names or by an alias of the reader type
+func namesMatch(writerName string, readerName string, readerAliases []string) bool {
+	writerName = unqualifiedName(writerName)
+	if writerName == unqualifiedName(readerName) {
+		return true
+	}
+	for _, alias := range readerAliases {
+		if writerName == unqualifiedName(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func unqualifiedName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func incompatibleSchemas(writer Schema, reader Schema) error {
+	return fmt.Errorf("Cannot read %s written as %s", reader.GetName(), writer.GetName())
+}
+
+// returns the record schema behind a recursive reference or the given schema otherwise
+func actualSchema(schema Schema) Schema {
+	if recursive, ok := schema.(*RecursiveSchema); ok {
+		return recursive.Actual
+	}
+	return schema
+}
+
+// returns the default value of a given field converted to the generic value of the field type
+func fieldDefault(field *SchemaField) (interface{}, error) {
+	if field.Default == nil {
+		// a null default cannot be told apart from a missing one unless the field may hold null
+		if schemaAllowsNull(field.Type) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("No default value for field %s", field.Name)
+	}
+	return defaultValue(field.Type, field.Default)
+}
+
+func schemaAllowsNull(schema Schema) bool {
+	switch s := schema.(type) {
+	case *NullSchema:
+		return true
+	case *UnionSchema:
+		return len(s.Types) > 0 && s.Types[0].Type() == Null
+	}
+	return false
+}
+
+// converts a default value as it appears in a JSON schema to the generic value of a given schema
+func defaultValue(schema Schema, value interface{}) (interface{}, error) {
+	schema = actualSchema(schema)
+	invalid := fmt.Errorf("Invalid default value %v for %s", value, schema.GetName())
+
+	switch schema.Type() {
+	case Null:
+		if value != nil {
+			return nil, invalid
+		}
+		return nil, nil
+	case Boolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case Int:
+		if number, ok := defaultNumber(value); ok && number == float64(int32(number)) {
+			return int32(number), nil
+		}
+	case Long:
+		if number, ok := defaultNumber(value); ok && number == float64(int64(number)) {
+			return int64(number), nil
+		}
+	case Float:
+		if number, ok := defaultNumber(value); ok {
+			return float32(number), nil
+		}
+	case Double:
+		if number, ok := defaultNumber(value); ok {
+			return number, nil
+		}
+	case Bytes, Fixed:
+		if bytes, ok := jsonBytes(value); ok {
+			if fixed, isFixed := schema.(*FixedSchema); isFixed && len(bytes) != fixed.Size {
+				return nil, invalid
+			}
+			return bytes, nil
+		}
+	case String:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case Enum:
+		if symbol, ok := value.(string); ok {
+			for _, s := range schema.(*EnumSchema).Symbols {
+				if s == symbol {
+					return symbol, nil
+				}
+			}
+		}
+	case Array:
+		if items, ok := value.([]interface{}); ok {
+			array := make([]interface{}, len(items))
+			for i, item := range items {
+				converted, err := defaultValue(schema.(*ArraySchema).Items, item)
+				if err != nil {
+					return nil, err
+				}
+				array[i] = converted
+			}
+			return array, nil
+		}
+	case Map:
+		if entries, ok := value.(map[string]interface{}); ok {
+			resultMap := make(map[string]interface{})
+			for key, entry := range entries {
+				converted, err := defaultValue(schema.(*MapSchema).Values, entry)
+				if err != nil {
+					return nil, err
+				}
+				resultMap[key] = converted
+			}
+			return resultMap, nil
+		}
+	case Union:
+		// the default value of a union corresponds to its first type
+		if types := schema.(*UnionSchema).Types; len(types) > 0 {
+			return defaultValue(types[0], value)
+		}
+	case Record:
+		if fields, ok := value.(map[string]interface{}); ok {
+			record := NewGenericRecord(schema)
+			for _, field := range schema.(*RecordSchema).Fields {
+				var converted interface{}
+				var err error
+				if fieldValue, exists := fields[field.Name]; exists {
+					converted, err = defaultValue(field.Type, fieldValue)
+				} else {
+					converted, err = fieldDefault(field)
+				}
+				if err != nil {
+					return nil, err
+				}
+				record.Set(field.Name, converted)
+			}
+			return record, nil
+		}
+	}
+
+	return nil, invalid
+}
+
+// JSON numbers are parsed as float64 but defaults of some types are converted to other numeric types when parsing
+func defaultNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	}
+	return 0, false
+}
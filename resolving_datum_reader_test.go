@@ -0,0 +1,425 @@
+package avro
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const resolvingWriterSchema = `{"type":"record","name":"Person","fields":[
+	{"name":"name","type":"string"},
+	{"name":"nicknames","type":{"type":"array","items":"string"}},
+	{"name":"age","type":"int"}
+]}`
+
+func encodeResolvingTestRecord() []byte {
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteString("Ada")
+	enc.WriteArrayStart(2)
+	enc.WriteString("A")
+	enc.WriteString("Countess")
+	enc.WriteArrayNext(0)
+	enc.WriteInt(36)
+	return buf.Bytes()
+}
+
+func TestResolvingDatumReaderDefaults(t *testing.T) {
+	readerSchema := MustParseSchema(`{"type":"record","name":"Person","fields":[
+		{"name":"age","type":"int"},
+		{"name":"name","type":"string"},
+		{"name":"height","type":"double","default":1},
+		{"name":"id","type":"long","default":0},
+		{"name":"email","type":["null","string"],"default":null},
+		{"name":"score","type":["int","null"],"default":7},
+		{"name":"tags","type":{"type":"map","values":"string"},"default":{"a":"b"}},
+		{"name":"hash","type":{"type":"fixed","name":"two","size":2},"default":"ÿ\u0001"},
+		{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]},"default":"HEARTS"},
+		{"name":"address","type":{"type":"record","name":"Address","fields":[
+			{"name":"city","type":"string"},
+			{"name":"zip","type":"int","default":0}
+		]},"default":{"city":"London"}}
+	]}`)
+
+	datumReader := NewResolvingDatumReader(readerSchema)
+	datumReader.SetSchema(MustParseSchema(resolvingWriterSchema))
+	data := encodeResolvingTestRecord()
+	dec := NewBinaryDecoder(data)
+	record := NewGenericRecord(readerSchema)
+	assert(t, datumReader.Read(record, dec), nil)
+	assert(t, dec.Tell(), int64(len(data)))
+
+	assert(t, record.Get("age"), int32(36))
+	assert(t, record.Get("name"), "Ada")
+	assert(t, record.Get("nicknames"), nil)
+	assert(t, record.Get("height"), float64(1))
+	assert(t, record.Get("id"), int64(0))
+	assert(t, record.Get("email"), nil)
+	assert(t, record.Get("score"), int32(7))
+	assert(t, record.Get("tags"), map[string]interface{}{"a": "b"})
+	assert(t, record.Get("hash"), []byte{0xFF, 0x01})
+	assert(t, record.Get("suit"), "HEARTS")
+	address := record.Get("address").(*GenericRecord)
+	assert(t, address.Get("city"), "London")
+	assert(t, address.Get("zip"), int32(0))
+}
+
+func TestResolvingDatumReaderMutableDefaults(t *testing.T) {
+	readerSchema := MustParseSchema(`{"type":"record","name":"Person","fields":[
+		{"name":"name","type":"string"},
+		{"name":"tags","type":{"type":"map","values":"string"},"default":{"a":"b"}},
+		{"name":"scores","type":{"type":"array","items":"int"},"default":[1]},
+		{"name":"hash","type":"bytes","default":"\u0001"},
+		{"name":"address","type":{"type":"record","name":"Address","fields":[
+			{"name":"city","type":"string"}
+		]},"default":{"city":"London"}}
+	]}`)
+	datumReader := NewResolvingDatumReader(readerSchema)
+	datumReader.SetSchema(MustParseSchema(resolvingWriterSchema))
+	data := encodeResolvingTestRecord()
+
+	first := NewGenericRecord(readerSchema)
+	assert(t, datumReader.Read(first, NewBinaryDecoder(data)), nil)
+	first.Get("tags").(map[string]interface{})["a"] = "changed"
+	first.Get("scores").([]interface{})[0] = int32(2)
+	first.Get("hash").([]byte)[0] = 0x02
+	first.Get("address").(*GenericRecord).Set("city", "Paris")
+
+	// the defaults of a record read next are not affected by changes to the previous record
+	second := NewGenericRecord(readerSchema)
+	assert(t, datumReader.Read(second, NewBinaryDecoder(data)), nil)
+	assert(t, second.Get("tags"), map[string]interface{}{"a": "b"})
+	assert(t, second.Get("scores"), []interface{}{int32(1)})
+	assert(t, second.Get("hash"), []byte{0x01})
+	assert(t, second.Get("address").(*GenericRecord).Get("city"), "London")
+}
+
+func TestResolvingDatumReaderNested(t *testing.T) {
+	writerSchema := MustParseSchema(`{"type":"record","name":"Team","fields":[
+		{"name":"members","type":{"type":"array","items":` + resolvingWriterSchema + `}}
+	]}`)
+	readerSchema := MustParseSchema(`{"type":"record","name":"Team","fields":[
+		{"name":"members","type":{"type":"array","items":{"type":"record","name":"Person","fields":[
+			{"name":"name","type":"string"},
+			{"name":"active","type":"boolean","default":true}
+		]}}}
+	]}`)
+
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteArrayStart(2)
+	enc.WriteRaw(encodeResolvingTestRecord())
+	enc.WriteRaw(encodeResolvingTestRecord())
+	enc.WriteArrayNext(0)
+
+	datumReader := NewResolvingDatumReader(readerSchema)
+	datumReader.SetSchema(writerSchema)
+	record := NewGenericRecord(readerSchema)
+	assert(t, datumReader.Read(record, NewBinaryDecoder(buf.Bytes())), nil)
+	members := record.Get("members").([]interface{})
+	assert(t, len(members), 2)
+	for _, member := range members {
+		assert(t, member.(*GenericRecord).Get("name"), "Ada")
+		assert(t, member.(*GenericRecord).Get("active"), true)
+	}
+}
+
+func TestResolvingDatumReaderMissingDefault(t *testing.T) {
+	invalid := []string{
+		`{"type":"record","name":"Person","fields":[{"name":"height","type":"double"}]}`,
+		`{"type":"record","name":"Person","fields":[{"name":"height","type":"int","default":"tall"}]}`,
+		`{"type":"record","name":"Person","fields":[{"name":"name","type":"int"}]}`,
+	}
+	for _, raw := range invalid {
+		datumReader := NewResolvingDatumReader(MustParseSchema(raw))
+		datumReader.SetSchema(MustParseSchema(resolvingWriterSchema))
+		if err := datumReader.Read(NewGenericRecord(datumReader.readerSchema), NewBinaryDecoder(encodeResolvingTestRecord())); err == nil {
+			t.Errorf("Expected an error reading with schema %s", raw)
+		}
+	}
+
+	datumReader := NewResolvingDatumReader(MustParseSchema(resolvingWriterSchema))
+	assert(t, datumReader.Read(&GenericRecord{}, NewBinaryDecoder(nil)), SchemaNotSet)
+}
+
+// reads data encoded with a writer schema as a value of a reader schema
+func resolve(t *testing.T, writer string, reader string, data []byte) (interface{}, error) {
+	datumReader := NewResolvingDatumReader(MustParseSchema(reader))
+	datumReader.SetSchema(MustParseSchema(writer))
+	var value interface{}
+	err := datumReader.Read(&value, NewBinaryDecoder(data))
+	return value, err
+}
+
+func TestResolvingDatumReaderMatrix(t *testing.T) {
+	resolvable := []struct {
+		writer   string
+		reader   string
+		data     []byte
+		expected interface{}
+	}{
+		{`"string"`, `"string"`, []byte{0x02, 'a'}, "a"},
+		{`["null","string"]`, `"string"`, []byte{0x02, 0x02, 'a'}, "a"},
+		{`"string"`, `["null","string"]`, []byte{0x02, 'a'}, "a"},
+		{`["int","string"]`, `["string","null","int"]`, []byte{0x00, 0x04}, int32(2)},
+		{`{"type":"array","items":["null","int"]}`, `{"type":"array","items":"int"}`, []byte{0x02, 0x02, 0x04, 0x00}, []interface{}{int32(2)}},
+		{`{"type":"map","values":"int"}`, `{"type":"map","values":["null","int"]}`, []byte{0x02, 0x02, 'k', 0x04, 0x00}, map[string]interface{}{"k": int32(2)}},
+		{`{"type":"enum","name":"E","symbols":["A","B","C"]}`, `{"type":"enum","name":"E","symbols":["C","A"]}`, []byte{0x00}, "A"},
+		{`{"type":"enum","name":"Old","symbols":["A"]}`, `{"type":"enum","name":"ns.E","aliases":["Old"],"symbols":["A"]}`, []byte{0x00}, "A"},
+		{`{"type":"fixed","name":"F","size":1}`, `{"type":"fixed","name":"F","size":1}`, []byte{0x07}, []byte{0x07}},
+		{`{"type":"fixed","name":"Old","size":1}`, `{"type":"fixed","name":"F","aliases":["ns.Old"],"size":1}`, []byte{0x07}, []byte{0x07}},
+	}
+	for _, test := range resolvable {
+		value, err := resolve(t, test.writer, test.reader, test.data)
+		if err != nil {
+			t.Fatalf("Unexpected error reading %s as %s: %v", test.writer, test.reader, err)
+		}
+		if enum, ok := value.(GenericEnum); ok {
+			value = enum.Get()
+		}
+		assert(t, value, test.expected)
+	}
+
+	unresolvable := []struct {
+		writer string
+		reader string
+		data   []byte
+	}{
+		{`"string"`, `"int"`, []byte{0x02, 'a'}},
+		{`"string"`, `["null","int"]`, []byte{0x02, 'a'}},
+		{`["null","string"]`, `"string"`, []byte{0x00}},
+		{`{"type":"enum","name":"E","symbols":["A","B","C"]}`, `{"type":"enum","name":"E","symbols":["C","A"]}`, []byte{0x02}},
+		{`{"type":"enum","name":"E","symbols":["A"]}`, `{"type":"enum","name":"Other","symbols":["A"]}`, []byte{0x00}},
+		{`{"type":"fixed","name":"F","size":1}`, `{"type":"fixed","name":"F","size":2}`, []byte{0x07}},
+		{`{"type":"fixed","name":"F","size":1}`, `{"type":"fixed","name":"G","size":1}`, []byte{0x07}},
+		{`{"type":"record","name":"R","fields":[]}`, `{"type":"record","name":"S","fields":[]}`, []byte{}},
+		{`{"type":"array","items":"int"}`, `{"type":"array","items":"string"}`, []byte{0x00}},
+		{`{"type":"array","items":"int"}`, `{"type":"map","values":"int"}`, []byte{0x00}},
+	}
+	for _, test := range unresolvable {
+		if _, err := resolve(t, test.writer, test.reader, test.data); err == nil {
+			t.Errorf("Expected an error reading %s as %s", test.writer, test.reader)
+		}
+	}
+}
+
+func TestResolvingDatumReaderAliases(t *testing.T) {
+	writer := `{"type":"record","name":"OldPerson","fields":[
+		{"name":"fullName","type":"string"},
+		{"name":"age","type":"int"}
+	]}`
+	reader := `{"type":"record","name":"Person","aliases":["OldPerson"],"fields":[
+		{"name":"age","type":"int"},
+		{"name":"name","aliases":["fullName"],"type":"string"}
+	]}`
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteString("Ada")
+	enc.WriteInt(36)
+
+	datumReader := NewResolvingDatumReader(MustParseSchema(reader))
+	datumReader.SetSchema(MustParseSchema(writer))
+	record := NewGenericRecord(datumReader.readerSchema)
+	assert(t, datumReader.Read(record, NewBinaryDecoder(buf.Bytes())), nil)
+	assert(t, record.Get("name"), "Ada")
+	assert(t, record.Get("age"), int32(36))
+}
+
+func TestResolvingDatumReaderNestedAliases(t *testing.T) {
+	// a renamed record type inside a union with renamed fields
+	writer := `{"type":"record","name":"Envelope","fields":[
+		{"name":"payload","type":["null",{"type":"record","name":"OldPayload","fields":[
+			{"name":"oldName","type":"string"},
+			{"name":"count","type":"int"}
+		]}]}
+	]}`
+	reader := `{"type":"record","name":"Envelope","fields":[
+		{"name":"body","aliases":["payload"],"type":["null",{"type":"record","name":"Payload","aliases":["OldPayload"],"fields":[
+			{"name":"newName","aliases":["oldName"],"type":"string"},
+			{"name":"count","type":"long"}
+		]}]}
+	]}`
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteLong(1)
+	enc.WriteString("Ada")
+	enc.WriteInt(36)
+
+	datumReader := NewResolvingDatumReader(MustParseSchema(reader))
+	datumReader.SetSchema(MustParseSchema(writer))
+	record := NewGenericRecord(datumReader.readerSchema)
+	assert(t, datumReader.Read(record, NewBinaryDecoder(buf.Bytes())), nil)
+	body := record.Get("body").(*GenericRecord)
+	assert(t, body.Get("newName"), "Ada")
+	assert(t, body.Get("count"), int64(36))
+
+	// without the aliases the renamed record does not resolve
+	datumReader = NewResolvingDatumReader(MustParseSchema(strings.Replace(reader, `"aliases":["OldPayload"],`, "", 1)))
+	datumReader.SetSchema(MustParseSchema(writer))
+	record = NewGenericRecord(datumReader.readerSchema)
+	if err := datumReader.Read(record, NewBinaryDecoder(buf.Bytes())); err == nil {
+		t.Fatal("Expected an error reading a renamed record without aliases")
+	}
+}
+
+func TestResolvingDatumReaderRecursive(t *testing.T) {
+	reader := MustParseSchema(`{"type":"record","name":"Node","fields":[
+		{"name":"next","type":["null","Node"]},
+		{"name":"label","type":"string","default":"node"},
+		{"name":"value","type":"int"}
+	]}`)
+	datumReader := NewResolvingDatumReader(reader)
+	datumReader.SetSchema(MustParseSchema(linkedListSchema))
+
+	record := NewGenericRecord(reader)
+	assert(t, datumReader.Read(record, NewBinaryDecoder(encodeLinkedList(1, 2))), nil)
+	assert(t, record.Get("value"), int32(1))
+	assert(t, record.Get("label"), "node")
+	next := record.Get("next").(*GenericRecord)
+	assert(t, next.Get("value"), int32(2))
+	assert(t, next.Get("next"), nil)
+}
+
+func TestResolvingDatumReaderDataFile(t *testing.T) {
+	filename := writeTempDataFile(t, encodeDataFile("null", []int64{1, 2}))
+	defer os.Remove(filename)
+
+	readerSchema := MustParseSchema(`{"type":"record","name":"Entry","fields":[
+		{"name":"value","type":"long"},
+		{"name":"comment","type":["null","string"],"default":null}
+	]}`)
+	reader, err := NewDataFileReader(filename, NewResolvingDatumReader(readerSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []int64{1, 2} {
+		record := NewGenericRecord(readerSchema)
+		ok, err := reader.Next(record)
+		assert(t, err, nil)
+		assert(t, ok, true)
+		assert(t, record.Get("value"), expected)
+		assert(t, record.Get("comment"), nil)
+	}
+}
+
+func TestResolvingDatumReaderPromotion(t *testing.T) {
+	encoded := map[string][]byte{
+		`"int"`:    {0x06},
+		`"long"`:   {0x06},
+		`"float"`:  {0x00, 0x00, 0x40, 0x40},
+		`"double"`: {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x40},
+	}
+	promoted := map[string]interface{}{
+		`"int"`:    int32(3),
+		`"long"`:   int64(3),
+		`"float"`:  float32(3),
+		`"double"`: float64(3),
+	}
+	allowed := map[string][]string{
+		`"int"`:    {`"int"`, `"long"`, `"float"`, `"double"`},
+		`"long"`:   {`"long"`, `"float"`, `"double"`},
+		`"float"`:  {`"float"`, `"double"`},
+		`"double"`: {`"double"`},
+	}
+
+	for writer, data := range encoded {
+		for reader := range encoded {
+			isAllowed := false
+			for _, allowedReader := range allowed[writer] {
+				isAllowed = isAllowed || allowedReader == reader
+			}
+
+			value, err := resolve(t, writer, reader, data)
+			if isAllowed {
+				if err != nil {
+					t.Fatalf("Unexpected error promoting %s to %s: %v", writer, reader, err)
+				}
+				assert(t, value, promoted[reader])
+			} else if err != IncompatiblePromotion {
+				t.Errorf("Unexpected error promoting %s to %s: expected %v, actual %v", writer, reader, IncompatiblePromotion, err)
+			}
+		}
+	}
+
+	value, err := resolve(t, `"string"`, `"bytes"`, []byte{0x02, 'a'})
+	assert(t, err, nil)
+	assert(t, value, []byte{'a'})
+	value, err = resolve(t, `"bytes"`, `"string"`, []byte{0x02, 'a'})
+	assert(t, err, nil)
+	assert(t, value, "a")
+
+	// a branch of the writer type is preferred over a promotion
+	value, err = resolve(t, `"int"`, `["null","double","int"]`, []byte{0x06})
+	assert(t, err, nil)
+	assert(t, value, int32(3))
+	value, err = resolve(t, `"int"`, `["null","double","long"]`, []byte{0x06})
+	assert(t, err, nil)
+	assert(t, value, float64(3))
+	value, err = resolve(t, `{"type":"array","items":"long"}`, `{"type":"array","items":"double"}`, []byte{0x02, 0x06, 0x00})
+	assert(t, err, nil)
+	assert(t, value, []interface{}{float64(3)})
+}
+
+func TestResolvingDatumReaderLogicalTypePromotion(t *testing.T) {
+	// logical types of the writer schema do not apply to promoted values
+	value, err := resolve(t, `{"type":"int","logicalType":"date"}`, `"long"`, []byte{0x06})
+	assert(t, err, nil)
+	assert(t, value, int64(3))
+	value, err = resolve(t, `{"type":"long","logicalType":"timestamp-millis"}`, `"double"`, []byte{0x06})
+	assert(t, err, nil)
+	assert(t, value, float64(3))
+	value, err = resolve(t, `{"type":"int","logicalType":"time-millis"}`, `["null","long"]`, []byte{0x06})
+	assert(t, err, nil)
+	assert(t, value, int64(3))
+	value, err = resolve(t, `{"type":"string","logicalType":"uuid"}`, `"bytes"`, append([]byte{0x48}, "00112233-4455-6677-8899-aabbccddeeff"...))
+	assert(t, err, nil)
+	assert(t, value, []byte("00112233-4455-6677-8899-aabbccddeeff"))
+
+	// the logical type of the reader schema applies to the promoted value
+	value, err = resolve(t, `"int"`, `{"type":"long","logicalType":"timestamp-millis"}`, []byte{0x06})
+	assert(t, err, nil)
+	assert(t, value, timestampFromMillis(3))
+}
+
+func TestResolvingDatumReaderEnumDefault(t *testing.T) {
+	writer := `{"type":"record","name":"Card","fields":[
+		{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS","DIAMONDS","CLUBS"]}}
+	]}`
+	withDefault := `{"type":"record","name":"Card","fields":[
+		{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["UNKNOWN","HEARTS","SPADES"],"default":"UNKNOWN"}}
+	]}`
+	withoutDefault := `{"type":"record","name":"Card","fields":[
+		{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["UNKNOWN","HEARTS","SPADES"]}}
+	]}`
+
+	tests := []struct {
+		reader   string
+		index    byte
+		expected interface{}
+		err      error
+	}{
+		{withDefault, 0x00, "SPADES", nil},
+		{withDefault, 0x02, "HEARTS", nil},
+		{withDefault, 0x06, "UNKNOWN", nil},
+		{withoutDefault, 0x00, "SPADES", nil},
+		{withoutDefault, 0x06, nil, UnknownEnumSymbol},
+	}
+	for _, test := range tests {
+		value, err := resolve(t, writer, test.reader, []byte{test.index})
+		assert(t, err, test.err)
+		if err == nil {
+			record := value.(GenericRecord)
+			assert(t, record.Get("suit"), test.expected)
+		}
+	}
+
+	_, err := ParseSchema(`{"type":"enum","name":"Suit","symbols":["SPADES"],"default":"HEARTS"}`)
+	if err == nil {
+		t.Fatal("Expected an error for an enum default that is not a symbol")
+	}
+	s, err := ParseSchema(`{"type":"enum","name":"Suit","symbols":["SPADES"],"default":"SPADES"}`)
+	assert(t, err, nil)
+	assert(t, s.(*EnumSchema).Default, "SPADES")
+	assert(t, len(s.(*EnumSchema).Properties), 0)
+}
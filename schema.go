@@ -1,11 +1,15 @@
 package avro
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -106,11 +110,22 @@ type Schema interface {
 }
 
 // StringSchema implements Schema and represents Avro string type.
-type StringSchema struct{}
+type StringSchema struct {
+	Properties map[string]string
+}
 
 // Returns a JSON representation of StringSchema.
-func (*StringSchema) String() string {
-	return `{"type": "string"}`
+func (this *StringSchema) String() string {
+	if len(this.Properties) == 0 {
+		return `{"type": "string"}`
+	}
+
+	bytes, err := this.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	return string(bytes)
 }
 
 // Returns a type constant for this StringSchema.
@@ -123,8 +138,14 @@ func (*StringSchema) GetName() string {
 	return type_string
 }
 
-// Doesn't return anything valuable for StringSchema.
-func (*StringSchema) Prop(key string) (string, bool) {
+// Gets a custom non-reserved property, e.g. a logical type, from this schema and a bool representing if it exists.
+func (this *StringSchema) Prop(key string) (string, bool) {
+	if this.Properties != nil {
+		if prop, ok := this.Properties[key]; ok {
+			return prop, true
+		}
+	}
+
 	return "", false
 }
 
@@ -135,15 +156,30 @@ func (*StringSchema) Validate(v reflect.Value) bool {
 }
 
 func (this *StringSchema) MarshalJSON() ([]byte, error) {
-	return []byte(`"string"`), nil
+	if len(this.Properties) == 0 {
+		return []byte(`"string"`), nil
+	}
+
+	return appendProperties([]byte(`{"type":"string"}`), this.Properties)
 }
 
 // BytesSchema implements Schema and represents Avro bytes type.
-type BytesSchema struct{}
+type BytesSchema struct {
+	Properties map[string]string
+}
 
 // Returns a JSON representation of BytesSchema.
-func (*BytesSchema) String() string {
-	return `{"type": "bytes"}`
+func (this *BytesSchema) String() string {
+	if len(this.Properties) == 0 {
+		return `{"type": "bytes"}`
+	}
+
+	bytes, err := this.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	return string(bytes)
 }
 
 // Returns a type constant for this BytesSchema.
@@ -156,8 +192,14 @@ func (*BytesSchema) GetName() string {
 	return type_bytes
 }
 
-// Doesn't return anything valuable for BytesSchema.
-func (*BytesSchema) Prop(key string) (string, bool) {
+// Gets a custom non-reserved property, e.g. a logical type, from this schema and a bool representing if it exists.
+func (this *BytesSchema) Prop(key string) (string, bool) {
+	if this.Properties != nil {
+		if prop, ok := this.Properties[key]; ok {
+			return prop, true
+		}
+	}
+
 	return "", false
 }
 
@@ -169,15 +211,30 @@ func (*BytesSchema) Validate(v reflect.Value) bool {
 }
 
 func (this *BytesSchema) MarshalJSON() ([]byte, error) {
-	return []byte(`"bytes"`), nil
+	if len(this.Properties) == 0 {
+		return []byte(`"bytes"`), nil
+	}
+
+	return appendProperties([]byte(`{"type":"bytes"}`), this.Properties)
 }
 
 // IntSchema implements Schema and represents Avro int type.
-type IntSchema struct{}
+type IntSchema struct {
+	Properties map[string]string
+}
 
 // Returns a JSON representation of IntSchema.
-func (*IntSchema) String() string {
-	return `{"type": "int"}`
+func (this *IntSchema) String() string {
+	if len(this.Properties) == 0 {
+		return `{"type": "int"}`
+	}
+
+	bytes, err := this.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	return string(bytes)
 }
 
 // Returns a type constant for this IntSchema.
@@ -190,8 +247,14 @@ func (*IntSchema) GetName() string {
 	return type_int
 }
 
-// Doesn't return anything valuable for IntSchema.
-func (*IntSchema) Prop(key string) (string, bool) {
+// Gets a custom non-reserved property, e.g. a logical type, from this schema and a bool representing if it exists.
+func (this *IntSchema) Prop(key string) (string, bool) {
+	if this.Properties != nil {
+		if prop, ok := this.Properties[key]; ok {
+			return prop, true
+		}
+	}
+
 	return "", false
 }
 
@@ -201,15 +264,30 @@ func (*IntSchema) Validate(v reflect.Value) bool {
 }
 
 func (this *IntSchema) MarshalJSON() ([]byte, error) {
-	return []byte(`"int"`), nil
+	if len(this.Properties) == 0 {
+		return []byte(`"int"`), nil
+	}
+
+	return appendProperties([]byte(`{"type":"int"}`), this.Properties)
 }
 
 // LongSchema implements Schema and represents Avro long type.
-type LongSchema struct{}
+type LongSchema struct {
+	Properties map[string]string
+}
 
 // Returns a JSON representation of LongSchema.
-func (*LongSchema) String() string {
-	return `{"type": "long"}`
+func (this *LongSchema) String() string {
+	if len(this.Properties) == 0 {
+		return `{"type": "long"}`
+	}
+
+	bytes, err := this.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	return string(bytes)
 }
 
 // Returns a type constant for this LongSchema.
@@ -222,8 +300,14 @@ func (*LongSchema) GetName() string {
 	return type_long
 }
 
-// Doesn't return anything valuable for LongSchema.
-func (*LongSchema) Prop(key string) (string, bool) {
+// Gets a custom non-reserved property, e.g. a logical type, from this schema and a bool representing if it exists.
+func (this *LongSchema) Prop(key string) (string, bool) {
+	if this.Properties != nil {
+		if prop, ok := this.Properties[key]; ok {
+			return prop, true
+		}
+	}
+
 	return "", false
 }
 
@@ -233,7 +317,11 @@ func (*LongSchema) Validate(v reflect.Value) bool {
 }
 
 func (this *LongSchema) MarshalJSON() ([]byte, error) {
-	return []byte(`"long"`), nil
+	if len(this.Properties) == 0 {
+		return []byte(`"long"`), nil
+	}
+
+	return appendProperties([]byte(`{"type":"long"}`), this.Properties)
 }
 
 // FloatSchema implements Schema and represents Avro float type.
@@ -530,6 +618,7 @@ func (this *RecursiveSchema) MarshalJSON() ([]byte, error) {
 type SchemaField struct {
 	Name    string      `json:"name,omitempty"`
 	Doc     string      `json:"doc,omitempty"`
+	Aliases []string    `json:"aliases,omitempty"`
 	Default interface{} `json:"default"`
 	Type    Schema      `json:"type,omitempty"`
 }
@@ -539,11 +628,13 @@ func (this *SchemaField) MarshalJSON() ([]byte, error) {
 		return json.Marshal(struct {
 			Name    string      `json:"name,omitempty"`
 			Doc     string      `json:"doc,omitempty"`
+			Aliases []string    `json:"aliases,omitempty"`
 			Default interface{} `json:"default"`
 			Type    Schema      `json:"type,omitempty"`
 		}{
 			Name:    this.Name,
 			Doc:     this.Doc,
+			Aliases: this.Aliases,
 			Default: this.Default,
 			Type:    this.Type,
 		})
@@ -551,11 +642,13 @@ func (this *SchemaField) MarshalJSON() ([]byte, error) {
 		return json.Marshal(struct {
 			Name    string      `json:"name,omitempty"`
 			Doc     string      `json:"doc,omitempty"`
+			Aliases []string    `json:"aliases,omitempty"`
 			Default interface{} `json:"default,omitempty"`
 			Type    Schema      `json:"type,omitempty"`
 		}{
 			Name:    this.Name,
 			Doc:     this.Doc,
+			Aliases: this.Aliases,
 			Default: this.Default,
 			Type:    this.Type,
 		})
@@ -574,6 +667,7 @@ type EnumSchema struct {
 	Aliases    []string
 	Doc        string
 	Symbols    []string
+	Default    string
 	Properties map[string]string
 }
 
@@ -620,13 +714,17 @@ func (this *EnumSchema) MarshalJSON() ([]byte, error) {
 		Namespace string   `json:"namespace,omitempty"`
 		Name      string   `json:"name,omitempty"`
 		Doc       string   `json:"doc,omitempty"`
+		Aliases   []string `json:"aliases,omitempty"`
 		Symbols   []string `json:"symbols,omitempty"`
+		Default   string   `json:"default,omitempty"`
 	}{
 		Type:      "enum",
 		Namespace: this.Namespace,
 		Name:      this.Name,
 		Doc:       this.Doc,
+		Aliases:   this.Aliases,
 		Symbols:   this.Symbols,
+		Default:   this.Default,
 	})
 }
 
@@ -799,6 +897,8 @@ func (this *UnionSchema) MarshalJSON() ([]byte, error) {
 // FixedSchema implements Schema and represents Avro fixed type.
 type FixedSchema struct {
 	Name       string
+	Namespace  string
+	Aliases    []string
 	Size       int
 	Properties map[string]string
 }
@@ -841,15 +941,24 @@ func (this *FixedSchema) Validate(v reflect.Value) bool {
 }
 
 func (this *FixedSchema) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Type string `json:"type,omitempty"`
-		Size int    `json:"size,omitempty"`
-		Name string `json:"name,omitempty"`
+	bytes, err := json.Marshal(struct {
+		Type      string   `json:"type,omitempty"`
+		Size      int      `json:"size,omitempty"`
+		Namespace string   `json:"namespace,omitempty"`
+		Name      string   `json:"name,omitempty"`
+		Aliases   []string `json:"aliases,omitempty"`
 	}{
-		Type: "fixed",
-		Size: this.Size,
-		Name: this.Name,
+		Type:      "fixed",
+		Size:      this.Size,
+		Namespace: this.Namespace,
+		Name:      this.Name,
+		Aliases:   this.Aliases,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return appendProperties(bytes, this.Properties)
 }
 
 // Parses a given file.
@@ -917,7 +1026,10 @@ func schemaByType(i interface{}, registry map[string]Schema, namespace string) (
 		default:
 			schema, ok := registry[getFullName(v, namespace)]
 			if !ok {
-				return nil, fmt.Errorf("Unknown type name: %s", v)
+				// names defined in the null namespace are visible from any other namespace
+				if schema, ok = registry[v]; !ok {
+					return nil, fmt.Errorf("Unknown type name: %s", v)
+				}
 			}
 
 			return schema, nil
@@ -931,17 +1043,17 @@ func schemaByType(i interface{}, registry map[string]Schema, namespace string) (
 		case type_boolean:
 			return new(BooleanSchema), nil
 		case type_int:
-			return new(IntSchema), nil
+			return &IntSchema{Properties: getPrimitiveProperties(v)}, nil
 		case type_long:
-			return new(LongSchema), nil
+			return &LongSchema{Properties: getPrimitiveProperties(v)}, nil
 		case type_float:
 			return new(FloatSchema), nil
 		case type_double:
 			return new(DoubleSchema), nil
 		case type_bytes:
-			return new(BytesSchema), nil
+			return &BytesSchema{Properties: getPrimitiveProperties(v)}, nil
 		case type_string:
-			return new(StringSchema), nil
+			return &StringSchema{Properties: getPrimitiveProperties(v)}, nil
 		case type_array:
 			items, err := schemaByType(v[schema_itemsField], registry, namespace)
 			if err != nil {
@@ -977,16 +1089,24 @@ func parseEnumSchema(v map[string]interface{}, registry map[string]Schema, names
 	schema := &EnumSchema{Name: v[schema_nameField].(string), Symbols: symbols}
 	setOptionalField(&schema.Namespace, v, schema_namespaceField)
 	setOptionalField(&schema.Doc, v, schema_docField)
+	schema.Aliases = getAliases(v)
 	schema.Properties = getProperties(v)
+	setOptionalField(&schema.Default, v, schema_defaultField)
+	if schema.Default != "" && !containsString(symbols, schema.Default) {
+		return nil, fmt.Errorf("Default %s is not a symbol of enum %s", schema.Default, schema.Name)
+	}
 
-	return addSchema(getFullName(v[schema_nameField].(string), namespace), schema, registry)
+	return addSchema(getFullName(schema.Name, getNamespace(v, namespace)), schema, registry)
 }
 
 func parseFixedSchema(v map[string]interface{}, registry map[string]Schema, namespace string) (Schema, error) {
-	if size, ok := v[schema_sizeField].(float64); !ok {
+	if size, ok := v[schema_sizeField].(float64); !ok || size < 0 || size != math.Trunc(size) || size > math.MaxInt32 {
 		return nil, InvalidFixedSize
 	} else {
-		return addSchema(getFullName(v[schema_nameField].(string), namespace), &FixedSchema{Name: v[schema_nameField].(string), Size: int(size), Properties: getProperties(v)}, registry)
+		schema := &FixedSchema{Name: v[schema_nameField].(string), Size: int(size), Properties: getProperties(v)}
+		setOptionalField(&schema.Namespace, v, schema_namespaceField)
+		schema.Aliases = getAliases(v)
+		return addSchema(getFullName(schema.Name, getNamespace(v, namespace)), schema, registry)
 	}
 }
 
@@ -1005,9 +1125,10 @@ func parseUnionSchema(v []interface{}, registry map[string]Schema, namespace str
 func parseRecordSchema(v map[string]interface{}, registry map[string]Schema, namespace string) (Schema, error) {
 	schema := &RecordSchema{Name: v[schema_nameField].(string)}
 	setOptionalField(&schema.Namespace, v, schema_namespaceField)
-	setOptionalField(&namespace, v, schema_namespaceField)
 	setOptionalField(&schema.Doc, v, schema_docField)
-	addSchema(getFullName(v[schema_nameField].(string), namespace), newRecursiveSchema(schema), registry)
+	schema.Aliases = getAliases(v)
+	namespace = getNamespace(v, namespace)
+	addSchema(getFullName(schema.Name, namespace), newRecursiveSchema(schema), registry)
 	fields := make([]*SchemaField, len(v[schema_fieldsField].([]interface{})))
 	for i := range fields {
 		field, err := parseSchemaField(v[schema_fieldsField].([]interface{})[i], registry, namespace)
@@ -1027,6 +1148,7 @@ func parseSchemaField(i interface{}, registry map[string]Schema, namespace strin
 	case map[string]interface{}:
 		schemaField := &SchemaField{Name: v[schema_nameField].(string)}
 		setOptionalField(&schemaField.Doc, v, schema_docField)
+		schemaField.Aliases = getAliases(v)
 		fieldType, err := schemaByType(v[schema_typeField], registry, namespace)
 		if err != nil {
 			return nil, err
@@ -1079,22 +1201,52 @@ func addSchema(name string, schema Schema, schemas map[string]Schema) (Schema, e
 	return schema, nil
 }
 
+// names containing a dot are already full names, others are qualified with a given namespace
 func getFullName(name string, namespace string) string {
-	if len(namespace) > 0 {
+	if strings.Contains(name, ".") {
+		return name
+	} else if len(namespace) > 0 {
 		return namespace + "." + name
 	} else {
 		return name
 	}
 }
 
+// gets the namespace of a named type which is either the part of its name before the last dot, its namespace
+// attribute or the namespace of the enclosing type
+func getNamespace(v map[string]interface{}, namespace string) string {
+	name, _ := v[schema_nameField].(string)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i]
+	}
+	setOptionalField(&namespace, v, schema_namespaceField)
+	return namespace
+}
+
+// gets the aliases of a named type or a record field
+func getAliases(v map[string]interface{}) []string {
+	rawAliases, _ := v[schema_aliasesField].([]interface{})
+	var aliases []string
+	for _, alias := range rawAliases {
+		if name, ok := alias.(string); ok {
+			aliases = append(aliases, name)
+		}
+	}
+	return aliases
+}
+
 // gets custom string properties from a given schema
 func getProperties(v map[string]interface{}) map[string]string {
 	props := make(map[string]string)
 
 	for name, value := range v {
 		if !isReserved(name) {
-			if val, ok := value.(string); ok {
+			switch val := value.(type) {
+			case string:
 				props[name] = val
+			case float64:
+				// numeric properties like the precision and scale of decimals are kept in their JSON form
+				props[name] = strconv.FormatFloat(val, 'f', -1, 64)
 			}
 		}
 	}
@@ -1102,9 +1254,57 @@ func getProperties(v map[string]interface{}) map[string]string {
 	return props
 }
 
+// same as getProperties but returns nil if there are no properties so that primitives without properties are equal
+// to the ones parsed from a type name
+func getPrimitiveProperties(v map[string]interface{}) map[string]string {
+	props := getProperties(v)
+	if len(props) == 0 {
+		return nil
+	}
+
+	return props
+}
+
+// appends given custom properties to a JSON object marshalled for a schema. The precision and scale of decimals are
+// written back as JSON numbers, all other properties as strings.
+func appendProperties(object []byte, props map[string]string) ([]byte, error) {
+	if len(props) == 0 {
+		return object, nil
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(object)+32*len(props)))
+	buf.Write(object[:len(object)-1])
+	for _, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value := props[name]
+		var raw []byte
+		if _, convErr := strconv.ParseInt(value, 10, 32); convErr == nil && (name == "precision" || name == "scale") {
+			raw = []byte(value)
+		} else if raw, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(raw)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
 func isReserved(name string) bool {
 	switch name {
-	case schema_aliasesField, schema_docField, schema_fieldsField, schema_itemsField, schema_nameField,
+	case schema_aliasesField, schema_defaultField, schema_docField, schema_fieldsField, schema_itemsField, schema_nameField,
 		schema_namespaceField, schema_sizeField, schema_symbolsField, schema_typeField, schema_valuesField:
 		return true
 	}
@@ -1112,6 +1312,15 @@ func isReserved(name string) bool {
 	return false
 }
 
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func dereference(v reflect.Value) reflect.Value {
 	if v.Kind() == reflect.Ptr {
 		return v.Elem()
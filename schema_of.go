@@ -0,0 +1,118 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Infers a Schema from the type of a given Go value, or of the value it points to, using reflection. Maps bool to
+// boolean, int32 to int, int64 to long, float32 to float, float64 to double, string to string, []byte to bytes, other
+// slices to arrays, maps with string keys to maps, pointers to a union of null and the pointed type and structs to
+// records named after their type. Record fields are the exported struct fields named after the `avro:"name"` tag or
+// else the field name, with options after a comma (e.g. `avro:"name,omitempty"`) ignored and fields tagged
+// `avro:"-"` left out. The inferred schema works with SpecificDatumWriter and SpecificDatumReader for the given type.
+// Returns an error if the type contains anything that cannot be mapped, e.g. an int or a map with int keys.
+func SchemaOf(v interface{}) (Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("Cannot infer a schema from nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	inferrer := &schemaInferrer{records: make(map[reflect.Type]bool)}
+	schema, err := inferrer.infer(t)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchema(string(raw))
+}
+
+type schemaInferrer struct {
+	// struct types that were already defined as records and are referred to by name afterwards
+	records map[reflect.Type]bool
+}
+
+// returns the JSON representation of the schema of a given type
+func (this *schemaInferrer) infer(t reflect.Type) (interface{}, error) {
+	if t == reflect.TypeOf([]byte(nil)) {
+		return type_bytes, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return type_boolean, nil
+	case reflect.Int32:
+		return type_int, nil
+	case reflect.Int64:
+		return type_long, nil
+	case reflect.Float32:
+		return type_float, nil
+	case reflect.Float64:
+		return type_double, nil
+	case reflect.String:
+		return type_string, nil
+	case reflect.Slice:
+		items, err := this.infer(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{schema_typeField: type_array, schema_itemsField: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Cannot infer a schema for %s: map keys must be strings", t)
+		}
+		values, err := this.infer(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{schema_typeField: type_map, schema_valuesField: values}, nil
+	case reflect.Ptr:
+		elem, err := this.infer(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{type_null, elem}, nil
+	case reflect.Struct:
+		return this.inferRecord(t)
+	}
+
+	return nil, fmt.Errorf("Cannot infer a schema for %s", t)
+}
+
+func (this *schemaInferrer) inferRecord(t reflect.Type) (interface{}, error) {
+	if t.Name() == "" {
+		return nil, fmt.Errorf("Cannot infer a schema for anonymous struct %s", t)
+	}
+	if this.records[t] {
+		return t.Name(), nil
+	}
+	this.records[t] = true
+
+	fields := make([]interface{}, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("avro"), ",")[0]
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldType, err := this.infer(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, map[string]interface{}{schema_nameField: name, schema_typeField: fieldType})
+	}
+
+	return map[string]interface{}{schema_typeField: type_record, schema_nameField: t.Name(), schema_fieldsField: fields}, nil
+}
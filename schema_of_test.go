@@ -0,0 +1,97 @@
+package avro
+
+import "testing"
+
+type inferredAddress struct {
+	Street  string
+	ZipCode int32 `avro:"zip_code,omitempty"`
+}
+
+type inferredPerson struct {
+	Name     string `avro:"name"`
+	Age      int32
+	Balance  int64
+	Height   float32
+	Score    float64
+	Active   bool
+	Avatar   []byte
+	Emails   []string
+	Tags     map[string]int64
+	Address  inferredAddress
+	Previous []*inferredAddress
+	Manager  *inferredPerson
+	Internal string `avro:"-"`
+	secret   string
+}
+
+func TestSchemaOf(t *testing.T) {
+	expected := MustParseSchema(`{"type": "record", "name": "inferredPerson", "fields": [
+		{"name": "name", "type": "string"},
+		{"name": "Age", "type": "int"},
+		{"name": "Balance", "type": "long"},
+		{"name": "Height", "type": "float"},
+		{"name": "Score", "type": "double"},
+		{"name": "Active", "type": "boolean"},
+		{"name": "Avatar", "type": "bytes"},
+		{"name": "Emails", "type": {"type": "array", "items": "string"}},
+		{"name": "Tags", "type": {"type": "map", "values": "long"}},
+		{"name": "Address", "type": {"type": "record", "name": "inferredAddress", "fields": [
+			{"name": "Street", "type": "string"},
+			{"name": "zip_code", "type": "int"}
+		]}},
+		{"name": "Previous", "type": {"type": "array", "items": ["null", "inferredAddress"]}},
+		{"name": "Manager", "type": ["null", "inferredPerson"]}
+	]}`)
+
+	for _, v := range []interface{}{inferredPerson{}, &inferredPerson{}} {
+		schema, err := SchemaOf(v)
+		assert(t, err, nil)
+		if !SchemaEqual(schema, expected) {
+			t.Fatalf("Unexpected schema: %s", schema)
+		}
+	}
+
+	primitive, err := SchemaOf(int64(0))
+	assert(t, err, nil)
+	assert(t, SchemaEqual(primitive, MustParseSchema(`"long"`)), true)
+	nullable, err := SchemaOf([]*string{})
+	assert(t, err, nil)
+	assert(t, SchemaEqual(nullable, MustParseSchema(`{"type": "array", "items": ["null", "string"]}`)), true)
+
+	for _, v := range []interface{}{nil, 1, map[int]string{}, struct{ Value int32 }{}, [2]int32{}} {
+		if _, err := SchemaOf(v); err == nil {
+			t.Fatalf("Expected an error for %T", v)
+		}
+	}
+}
+
+func TestSchemaOfRoundTrip(t *testing.T) {
+	schema, err := SchemaOf(&inferredPerson{})
+	assert(t, err, nil)
+
+	person := &inferredPerson{
+		Name:     "John Doe",
+		Age:      42,
+		Balance:  -100,
+		Height:   1.8,
+		Score:    4.5,
+		Active:   true,
+		Avatar:   []byte{0x01},
+		Emails:   []string{"john@example.com"},
+		Tags:     map[string]int64{"a": 1},
+		Address:  inferredAddress{Street: "Main St", ZipCode: 12345},
+		Previous: []*inferredAddress{{Street: "Old St", ZipCode: 1}},
+		Manager: &inferredPerson{
+			Name:     "Jane Roe",
+			Avatar:   []byte{},
+			Emails:   []string{},
+			Tags:     map[string]int64{},
+			Previous: []*inferredAddress{},
+		},
+	}
+	data, err := Marshal(schema, person)
+	assert(t, err, nil)
+	decoded := &inferredPerson{}
+	assert(t, Unmarshal(schema, data, decoded), nil)
+	assert(t, decoded, person)
+}
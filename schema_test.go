@@ -1,6 +1,7 @@
 package avro
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -259,6 +260,136 @@ func TestFixedSchema(t *testing.T) {
 	}
 }
 
+func TestFixedSchemaInvalidSize(t *testing.T) {
+	for _, size := range []string{`-1`, `1.5`, `"16"`, `4294967296`} {
+		_, err := ParseSchema(`{"type": "fixed", "name": "md5", "size": ` + size + `}`)
+		assert(t, err, InvalidFixedSize)
+	}
+}
+
+func TestFixedSchemaReferencedByName(t *testing.T) {
+	schema := MustParseSchema(`{"type": "record", "name": "Download", "namespace": "files", "fields": [
+		{"name": "hash", "type": {"type": "fixed", "name": "MD5", "size": 4}},
+		{"name": "previousHash", "type": ["null", "MD5"]},
+		{"name": "chunkHashes", "type": {"type": "array", "items": "files.MD5"}}
+	]}`).(*RecordSchema)
+	hash := schema.Fields[0].Type.(*FixedSchema)
+	assert(t, hash.Size, 4)
+	assert(t, schema.Fields[1].Type.(*UnionSchema).Types[1], hash)
+	assert(t, schema.Fields[2].Type.(*ArraySchema).Items, hash)
+
+	record := NewGenericRecord(schema)
+	record.Set("hash", []byte{0x01, 0x02, 0x03, 0x04})
+	record.Set("previousHash", []byte{0x05, 0x06, 0x07, 0x08})
+	record.Set("chunkHashes", []interface{}{[]byte{0x09, 0x0A, 0x0B, 0x0C}})
+	buf := &bytes.Buffer{}
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	assert(t, writer.Write(record, NewBinaryEncoder(buf)), nil)
+	// fixed values are written without a length
+	assert(t, buf.Bytes(), []byte{0x01, 0x02, 0x03, 0x04, 0x02, 0x05, 0x06, 0x07, 0x08, 0x02, 0x09, 0x0A, 0x0B, 0x0C, 0x00})
+
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	decoded := NewGenericRecord(schema)
+	assert(t, reader.Read(decoded, NewBinaryDecoder(buf.Bytes())), nil)
+	assert(t, decoded, record)
+	decoded = NewGenericRecord(schema)
+	assert(t, reader.Read(decoded, NewStreamBinaryDecoder(bytes.NewReader(buf.Bytes()))), nil)
+	assert(t, decoded, record)
+
+	record.Set("hash", []byte{0x05})
+	assert(t, writer.Write(record, NewBinaryEncoder(&bytes.Buffer{})), InvalidFixedSize)
+	assertError(t, reader.Read(NewGenericRecord(schema), NewBinaryDecoder(buf.Bytes()[:12])), EOF)
+}
+
+func TestSpecSchemas(t *testing.T) {
+	// examples from https://avro.apache.org/docs/current/spec.html#schema_complex
+	linkedList := `{"type": "record", "name": "LongList", "aliases": ["LinkedLongs"], "fields": [
+		{"name": "value", "type": "long"},
+		{"name": "next", "type": ["null", "LongList"]}
+	]}`
+	s, err := ParseSchema(linkedList)
+	assert(t, err, nil)
+	record := s.(*RecordSchema)
+	assert(t, record.Name, "LongList")
+	assert(t, record.Fields[0].Type.Type(), Long)
+	next := record.Fields[1].Type.(*UnionSchema)
+	assert(t, next.Types[0].Type(), Null)
+	assert(t, next.Types[1].Type(), Recursive)
+	assert(t, next.Types[1].(*RecursiveSchema).Actual, record)
+
+	s, err = ParseSchema(`{"type": "enum", "name": "Suit", "symbols" : ["SPADES", "HEARTS", "DIAMONDS", "CLUBS"]}`)
+	assert(t, err, nil)
+	assert(t, s.(*EnumSchema).Symbols, []string{"SPADES", "HEARTS", "DIAMONDS", "CLUBS"})
+
+	s, err = ParseSchema(`{"type": "array", "items": "string"}`)
+	assert(t, err, nil)
+	assert(t, s.(*ArraySchema).Items.Type(), String)
+
+	s, err = ParseSchema(`{"type": "map", "values": "long"}`)
+	assert(t, err, nil)
+	assert(t, s.(*MapSchema).Values.Type(), Long)
+
+	s, err = ParseSchema(`["null", "string"]`)
+	assert(t, err, nil)
+	assert(t, len(s.(*UnionSchema).Types), 2)
+
+	s, err = ParseSchema(`{"type": "fixed", "size": 16, "name": "md5"}`)
+	assert(t, err, nil)
+	assert(t, s.(*FixedSchema).Size, 16)
+}
+
+func TestSchemaFullNames(t *testing.T) {
+	raw := `{"type": "record", "name": "Example", "namespace": "org.apache.avro", "fields": [
+		{"name": "hash", "type": {"type": "fixed", "name": "md5", "size": 16}},
+		{"name": "suit", "type": {"type": "enum", "name": "com.example.Suit", "symbols": ["SPADES", "HEARTS"]}},
+		{"name": "checksum", "type": {"type": "fixed", "name": "crc", "namespace": "org.checks", "size": 4}},
+		{"name": "nested", "type": {"type": "record", "name": "a.b.Nested", "fields": [
+			{"name": "inner", "type": {"type": "enum", "name": "Inner", "symbols": ["X"]}},
+			{"name": "hash", "type": "org.apache.avro.md5"}
+		]}},
+		{"name": "sameHash", "type": "md5"},
+		{"name": "sameSuit", "type": "com.example.Suit"},
+		{"name": "sameChecksum", "type": "org.checks.crc"},
+		{"name": "sameInner", "type": "a.b.Inner"}
+	]}`
+	registry := make(map[string]Schema)
+	s, err := ParseSchemaWithRegistry(raw, registry)
+	assert(t, err, nil)
+
+	for _, name := range []string{"org.apache.avro.Example", "org.apache.avro.md5", "com.example.Suit",
+		"org.checks.crc", "a.b.Nested", "a.b.Inner"} {
+		if _, ok := registry[name]; !ok {
+			t.Errorf("Expected %s to be registered", name)
+		}
+	}
+	assert(t, len(registry), 6)
+
+	fields := s.(*RecordSchema).Fields
+	assert(t, fields[4].Type, fields[0].Type)
+	assert(t, fields[5].Type, fields[1].Type)
+	assert(t, fields[6].Type, fields[2].Type)
+	assert(t, fields[2].Type.(*FixedSchema).Namespace, "org.checks")
+	assert(t, fields[7].Type, fields[3].Type.(*RecordSchema).Fields[0].Type)
+
+	// a name defined in the null namespace is visible from other namespaces
+	s, err = ParseSchema(`{"type": "record", "name": "Outer", "fields": [
+		{"name": "md5", "type": {"type": "fixed", "name": "md5", "size": 16}},
+		{"name": "inner", "type": {"type": "record", "name": "Inner", "namespace": "ns", "fields": [
+			{"name": "hash", "type": "md5"}
+		]}}
+	]}`)
+	assert(t, err, nil)
+
+	_, err = ParseSchema(`{"type": "record", "name": "Outer", "namespace": "ns", "fields": [
+		{"name": "hash", "type": "other.md5"}
+	]}`)
+	if err == nil {
+		t.Fatal("Expected an error for an unknown full name")
+	}
+}
+
 func TestSchemaRegistryMap(t *testing.T) {
 	rawSchema1 := `{"type": "record", "name": "TestRecord", "fields": [
      	{"name": "longRecordField", "type": "long"}
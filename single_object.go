@@ -0,0 +1,90 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Marker bytes every single-object encoded message starts with.
+var single_object_marker = []byte{0xC3, 0x01}
+
+const single_object_header_size = 10
+
+// Reads the header of an Avro single-object encoded message, i.e. the two marker bytes followed by the 8 byte
+// little-endian CRC-64-AVRO fingerprint of the writer schema, leaving this decoder positioned at the start of the
+// encoded value. Returns the schema fingerprint, NotSingleObject if the message does not start with the marker or
+// an error if it occurs.
+func (this *BinaryDecoder) ReadSingleObjectHeader() (_ uint64, err error) {
+	defer this.wrapError("ReadSingleObjectHeader", this.pos, &err)
+	if err := checkEOF(this.buf, this.pos, single_object_header_size); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(this.buf[this.pos:this.pos+2], single_object_marker) {
+		return 0, NotSingleObject
+	}
+
+	fingerprint := binary.LittleEndian.Uint64(this.buf[this.pos+2:])
+	this.pos += single_object_header_size
+	return fingerprint, nil
+}
+
+// Writes the header of an Avro single-object encoded message for a given Schema, i.e. the two marker bytes followed
+// by the 8 byte little-endian CRC-64-AVRO fingerprint of the schema. The encoded value should be written right after.
+func (this *BinaryEncoder) WriteSingleObjectHeader(schema Schema) {
+	this.WriteRaw(single_object_marker)
+	this.WriteRaw(FingerprintBytes(schema))
+}
+
+// Framing tells how an Avro encoded message is framed.
+type Framing int
+
+const (
+	// The message is a bare Avro encoded value.
+	RawFraming Framing = iota
+
+	// The message is single-object encoded, i.e. the value is preceded by the marker and the writer schema fingerprint.
+	SingleObjectFraming
+)
+
+// Detects the framing of a message by peeking at its first bytes. A message starting with the single-object marker
+// is single-object encoded, in which case its header is read and the writer schema fingerprint returned. Any other
+// message is taken as a raw value and this decoder is left untouched. Either way this decoder is positioned at the
+// start of the encoded value. Returns the detected framing, the fingerprint and an error if the header is truncated.
+func (this *BinaryDecoder) ReadFraming() (Framing, uint64, error) {
+	if marker, err := this.Peek(len(single_object_marker)); err != nil || !bytes.Equal(marker, single_object_marker) {
+		return RawFraming, 0, nil
+	}
+	fingerprint, err := this.ReadSingleObjectHeader()
+	if err != nil {
+		return SingleObjectFraming, 0, err
+	}
+	return SingleObjectFraming, fingerprint, nil
+}
+
+// Decodes a message that is either single-object encoded or a raw value into a given value with GenericDatumReader.
+// Single-object encoded messages are decoded with the schema of a given map keyed by fingerprints (see Fingerprint)
+// and raw values with a given raw schema. Given value MUST be of pointer type.
+// Returns the detected framing, UnknownFingerprint if there is no schema for the fingerprint of a single-object
+// encoded message or an error if decoding fails.
+func DecodeFramed(data []byte, rawSchema Schema, schemas map[uint64]Schema, v interface{}) (Framing, error) {
+	dec := NewBinaryDecoder(data)
+	framing, fingerprint, err := dec.ReadFraming()
+	if err != nil {
+		return framing, err
+	}
+
+	schema := rawSchema
+	if framing == SingleObjectFraming {
+		var ok bool
+		if schema, ok = schemas[fingerprint]; !ok {
+			return framing, UnknownFingerprint
+		}
+	}
+	if schema == nil {
+		return framing, SchemaNotSet
+	}
+
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	return framing, reader.Read(v, dec)
+}
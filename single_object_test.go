@@ -0,0 +1,81 @@
+package avro
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSingleObjectEncoding(t *testing.T) {
+	schema := MustParseSchema(`"int"`)
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteSingleObjectHeader(schema)
+	enc.WriteInt(42)
+	assert(t, buf.Bytes(), []byte{0xC3, 0x01, 0x8f, 0x5c, 0x39, 0x3f, 0x1a, 0xd5, 0x75, 0x72, 0x54})
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	fingerprint, err := dec.ReadSingleObjectHeader()
+	assert(t, err, nil)
+	assert(t, fingerprint, Fingerprint(schema))
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(42))
+}
+
+func TestSingleObjectInvalidHeader(t *testing.T) {
+	dec := NewBinaryDecoder([]byte{0xC3, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0x54})
+	_, err := dec.ReadSingleObjectHeader()
+	assertError(t, err, NotSingleObject)
+	assert(t, dec.Tell(), int64(0))
+
+	dec = NewBinaryDecoder([]byte{0xC3, 0x01, 0, 0})
+	_, err = dec.ReadSingleObjectHeader()
+	assertError(t, err, EOF)
+}
+
+func TestDecodeFramed(t *testing.T) {
+	intSchema := MustParseSchema(`"int"`)
+	stringSchema := MustParseSchema(`"string"`)
+	schemas := map[uint64]Schema{Fingerprint(stringSchema): stringSchema}
+
+	buf := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buf)
+	enc.WriteSingleObjectHeader(stringSchema)
+	enc.WriteString("framed")
+	var str string
+	framing, err := DecodeFramed(buf.Bytes(), intSchema, schemas, &str)
+	assert(t, err, nil)
+	assert(t, framing, SingleObjectFraming)
+	assert(t, str, "framed")
+
+	dec := NewBinaryDecoder(buf.Bytes())
+	framing, fingerprint, err := dec.ReadFraming()
+	assert(t, err, nil)
+	assert(t, framing, SingleObjectFraming)
+	assert(t, fingerprint, Fingerprint(stringSchema))
+	assert(t, dec.Tell(), int64(single_object_header_size))
+
+	var number int32
+	framing, err = DecodeFramed([]byte{0x54}, intSchema, schemas, &number)
+	assert(t, err, nil)
+	assert(t, framing, RawFraming)
+	assert(t, number, int32(42))
+
+	dec = NewBinaryDecoder([]byte{0xC3, 0x02, 0x54})
+	framing, _, err = dec.ReadFraming()
+	assert(t, err, nil)
+	assert(t, framing, RawFraming)
+	assert(t, dec.Tell(), int64(0))
+	_, err = DecodeFramed([]byte{0x54}, nil, schemas, &number)
+	assert(t, err, SchemaNotSet)
+
+	// a truncated header and a header of an unknown schema
+	framing, err = DecodeFramed([]byte{0xC3, 0x01, 0x8f, 0x5c}, intSchema, schemas, &number)
+	assertError(t, err, EOF)
+	assert(t, framing, SingleObjectFraming)
+	buf.Reset()
+	enc.WriteSingleObjectHeader(intSchema)
+	enc.WriteInt(42)
+	_, err = DecodeFramed(buf.Bytes(), intSchema, schemas, &number)
+	assert(t, err, UnknownFingerprint)
+}
@@ -0,0 +1,164 @@
+package avro
+
+import "encoding/binary"
+
+// Implementation of the snappy block format (https://github.com/google/snappy/blob/master/format_description.txt)
+// used by the snappy data file codec.
+
+const (
+	snappy_tag_literal = 0x00
+	snappy_tag_copy1   = 0x01
+	snappy_tag_copy2   = 0x02
+	snappy_tag_copy4   = 0x03
+
+	snappy_max_copy_length = 64
+	snappy_max_copy_offset = 1<<16 - 1
+	snappy_hash_bits       = 14
+
+	// a 3 byte copy element expands to at most 64 bytes, so no block decompresses to more than 22 times its size
+	snappy_max_expansion = 22
+)
+
+// decompresses a snappy block
+func snappyDecode(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 || length > uint64(len(src)-n)*snappy_max_expansion {
+		return nil, InvalidSnappyData
+	}
+	dst := make([]byte, 0, length)
+
+	for i := n; i < len(src); {
+		tag := src[i]
+		i++
+		switch tag & 0x03 {
+		case snappy_tag_literal:
+			literalLength := uint64(tag >> 2)
+			if literalLength >= 60 {
+				extra := int(literalLength - 59)
+				if i+extra > len(src) {
+					return nil, InvalidSnappyData
+				}
+				literalLength = 0
+				for j := 0; j < extra; j++ {
+					literalLength |= uint64(src[i+j]) << uint(8*j)
+				}
+				i += extra
+			}
+			literalLength++
+			if literalLength > uint64(len(src)-i) {
+				return nil, InvalidSnappyData
+			}
+			dst = append(dst, src[i:i+int(literalLength)]...)
+			i += int(literalLength)
+			continue
+		case snappy_tag_copy1:
+			if i >= len(src) {
+				return nil, InvalidSnappyData
+			}
+			copyLength := 4 + int(tag>>2)&0x07
+			offset := int(tag&0xE0)<<3 | int(src[i])
+			i++
+			if err := snappyCopy(&dst, offset, copyLength); err != nil {
+				return nil, err
+			}
+		case snappy_tag_copy2:
+			if i+2 > len(src) {
+				return nil, InvalidSnappyData
+			}
+			offset := int(binary.LittleEndian.Uint16(src[i:]))
+			i += 2
+			if err := snappyCopy(&dst, offset, 1+int(tag>>2)); err != nil {
+				return nil, err
+			}
+		case snappy_tag_copy4:
+			if i+4 > len(src) {
+				return nil, InvalidSnappyData
+			}
+			offset := binary.LittleEndian.Uint32(src[i:])
+			i += 4
+			if offset > uint32(len(dst)) {
+				return nil, InvalidSnappyData
+			}
+			if err := snappyCopy(&dst, int(offset), 1+int(tag>>2)); err != nil {
+				return nil, err
+			}
+		}
+		if uint64(len(dst)) > length {
+			return nil, InvalidSnappyData
+		}
+	}
+
+	if uint64(len(dst)) != length {
+		return nil, InvalidSnappyData
+	}
+	return dst, nil
+}
+
+// appends length bytes starting offset bytes back from the end of dst, which may overlap with the appended bytes
+func snappyCopy(dst *[]byte, offset int, length int) error {
+	if offset <= 0 || offset > len(*dst) {
+		return InvalidSnappyData
+	}
+	start := len(*dst) - offset
+	for j := 0; j < length; j++ {
+		*dst = append(*dst, (*dst)[start+j])
+	}
+	return nil
+}
+
+// compresses data into a snappy block, greedily replacing repeated 4 byte sequences with copies
+func snappyEncode(src []byte) []byte {
+	dst := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(src)+len(src)/6+1)
+	dst = dst[:binary.PutUvarint(dst, uint64(len(src)))]
+
+	var table [1 << snappy_hash_bits]int
+	literalStart := 0
+	for i := 0; i+4 <= len(src); {
+		value := binary.LittleEndian.Uint32(src[i:])
+		hash := (value * 0x1E35A7BD) >> (32 - snappy_hash_bits)
+		// positions are stored off by one so that zero means no candidate
+		candidate := table[hash] - 1
+		table[hash] = i + 1
+		if candidate < 0 || i-candidate > snappy_max_copy_offset || binary.LittleEndian.Uint32(src[candidate:]) != value {
+			i++
+			continue
+		}
+
+		matchLength := 4
+		for i+matchLength < len(src) && src[candidate+matchLength] == src[i+matchLength] {
+			matchLength++
+		}
+		dst = snappyAppendLiteral(dst, src[literalStart:i])
+		for remaining := matchLength; remaining > 0; remaining -= snappy_max_copy_length {
+			copyLength := remaining
+			if copyLength > snappy_max_copy_length {
+				copyLength = snappy_max_copy_length
+			}
+			dst = append(dst, byte(copyLength-1)<<2|snappy_tag_copy2, byte(i-candidate), byte((i-candidate)>>8))
+		}
+		i += matchLength
+		literalStart = i
+	}
+
+	return snappyAppendLiteral(dst, src[literalStart:])
+}
+
+func snappyAppendLiteral(dst []byte, literal []byte) []byte {
+	if len(literal) == 0 {
+		return dst
+	}
+	n := len(literal) - 1
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2|snappy_tag_literal)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|snappy_tag_literal, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2|snappy_tag_literal, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2|snappy_tag_literal, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2|snappy_tag_literal, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
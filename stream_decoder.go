@@ -0,0 +1,396 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// the largest bytes or string value StreamBinaryDecoder allocates at once before its contents are read
+const stream_chunk_size = 64 * 1024
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// StreamBinaryDecoder implements Decoder and provides low-level support for deserializing Avro values read
+// incrementally from an io.Reader. Unlike BinaryDecoder it does not require the whole payload to be in memory.
+// Tell returns the logical number of bytes consumed so far. As a stream cannot be rewound, Seek only supports
+// moving forward; seeking backwards makes all subsequent reads fail with InvalidSeek.
+type StreamBinaryDecoder struct {
+	reader           byteReader
+	pos              int64
+	err              error
+	scratch          [8]byte
+	maxAllocSize     int64
+	maxArrayElements int64
+	maxMapEntries    int64
+}
+
+// Creates a new StreamBinaryDecoder to read from a given io.Reader. Readers that do not implement io.ByteReader
+// are wrapped with a bufio.Reader.
+func NewStreamBinaryDecoder(r io.Reader) *StreamBinaryDecoder {
+	return &StreamBinaryDecoder{
+		reader:           toByteReader(r),
+		maxAllocSize:     max_alloc_size,
+		maxArrayElements: max_array_elements,
+		maxMapEntries:    max_map_entries,
+	}
+}
+
+// Creates a new StreamBinaryDecoder to read from a given io.Reader that stops reading once a given context is
+// cancelled or its deadline passes. Reads blocked on the io.Reader at that moment return the context error right
+// away, as do all subsequent reads. Cancelling does not interrupt the blocked read of the underlying io.Reader
+// itself, so the io.Reader should still be closed to release it.
+func NewStreamBinaryDecoderContext(ctx context.Context, r io.Reader) *StreamBinaryDecoder {
+	return NewStreamBinaryDecoder(&contextReader{ctx: ctx, reader: r})
+}
+
+// Sets the maximum length in bytes of a bytes or string value this StreamBinaryDecoder reads. Reading a value with a
+// longer decoded length returns SizeLimitExceeded before anything is allocated. Defaults to 1 GiB.
+func (this *StreamBinaryDecoder) SetMaxAllocSize(size int64) {
+	this.maxAllocSize = size
+}
+
+// Sets the maximum number of items of a single array block this StreamBinaryDecoder reads. Reading a block with
+// more items returns SizeLimitExceeded. Defaults to 16777216.
+func (this *StreamBinaryDecoder) SetMaxArrayElements(count int64) {
+	this.maxArrayElements = count
+}
+
+// Sets the maximum number of entries of a single map block this StreamBinaryDecoder reads. Reading a block with
+// more entries returns SizeLimitExceeded. Defaults to 16777216.
+func (this *StreamBinaryDecoder) SetMaxMapEntries(count int64) {
+	this.maxMapEntries = count
+}
+
+// Reads a null value. Null values take zero bytes so this never consumes anything and always returns (nil, nil).
+func (this *StreamBinaryDecoder) ReadNull() (interface{}, error) {
+	return nil, nil
+}
+
+// Reads a boolean value. Returns a decoded value and an error if it occurs. A byte other than 0x00 or 0x01 gives
+// an InvalidBoolError, the position still moves past that byte.
+func (this *StreamBinaryDecoder) ReadBoolean() (bool, error) {
+	b, err := this.readByte()
+	if err != nil {
+		return false, err
+	}
+	if b != 0 && b != 1 {
+		return b == 1, &InvalidBoolError{Value: b, Pos: this.pos - 1}
+	}
+	return b == 1, nil
+}
+
+// Reads an int value. Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadInt() (int32, error) {
+	value, err := this.readVarint(max_int_buf_size, IntOverflow)
+	if err != nil {
+		return 0, err
+	}
+	v := uint32(value)
+	return int32((v >> 1) ^ -(v & 1)), nil
+}
+
+// Reads a long value. Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadLong() (int64, error) {
+	value, err := this.readVarint(max_long_buf_size, LongOverflow)
+	if err != nil {
+		return 0, err
+	}
+	return int64((value >> 1) ^ -(value & 1)), nil
+}
+
+// Reads a float value. Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadFloat() (float32, error) {
+	if err := this.readFull(this.scratch[:4]); err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(this.scratch[:4])), nil
+}
+
+// Reads a double value. Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadDouble() (float64, error) {
+	if err := this.readFull(this.scratch[:8]); err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(this.scratch[:8])), nil
+}
+
+// Reads a bytes value. Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadBytes() ([]byte, error) {
+	length, err := this.ReadLong()
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		return nil, NegativeBytesLength
+	}
+
+	return this.readLengthPrefixed(length)
+}
+
+// Reads a bytes value and copies it to a given io.Writer in chunks instead of returning it, so that values of any size
+// are passed on in constant memory. Returns the number of bytes written, UnexpectedEOF if the value is truncated or
+// an error if it occurs.
+func (this *StreamBinaryDecoder) ReadBytesTo(w io.Writer) (int64, error) {
+	length, err := this.ReadLong()
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, NegativeBytesLength
+	}
+	if this.err != nil {
+		return 0, this.err
+	}
+
+	n, err := io.CopyN(w, this.reader, length)
+	this.pos += n
+	if err == io.EOF {
+		return n, UnexpectedEOF
+	}
+	return n, err
+}
+
+// Reads a string value. Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadString() (string, error) {
+	length, err := this.ReadLong()
+	if err != nil {
+		return "", err
+	}
+	if length < 0 {
+		return "", InvalidStringLength
+	}
+
+	bytes, err := this.readLengthPrefixed(length)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// Reads an enum value (which is an Avro int value). Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadEnum() (int32, error) {
+	return this.ReadInt()
+}
+
+// Reads and returns the size of the first block of an array. If call to this return non-zero, then the caller
+// should read the indicated number of items and then call ArrayNext() to find out the number of items in the
+// next block. Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadArrayStart() (int64, error) {
+	return this.readItemCount(this.maxArrayElements)
+}
+
+// Processes the next block of an array and returns the number of items in the block.
+// Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ArrayNext() (int64, error) {
+	return this.readItemCount(this.maxArrayElements)
+}
+
+// Reads and returns the size of the first block of map entries. If call to this return non-zero, then the caller
+// should read the indicated number of items and then call MapNext() to find out the number of items in the
+// next block. Usage is similar to ReadArrayStart(). Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) ReadMapStart() (int64, error) {
+	return this.readItemCount(this.maxMapEntries)
+}
+
+// Processes the next block of map entries and returns the number of items in the block.
+// Returns a decoded value and an error if it occurs.
+func (this *StreamBinaryDecoder) MapNext() (int64, error) {
+	return this.readItemCount(this.maxMapEntries)
+}
+
+// Reads fixed sized binary object into the provided buffer.
+// Returns an error if it occurs.
+func (this *StreamBinaryDecoder) ReadFixed(bytes []byte) error {
+	return this.readFull(bytes)
+}
+
+// Reads fixed sized binary object into the provided buffer.
+// The second parameter is the position where the data needs to be written, the third is the size of binary object.
+// Returns InvalidBounds if the object does not fit into the buffer at the given position or an error if it occurs.
+func (this *StreamBinaryDecoder) ReadFixedWithBounds(bytes []byte, start int, length int) error {
+	if err := checkBounds(bytes, start, length); err != nil {
+		return err
+	}
+	return this.readFull(bytes[start : start+length])
+}
+
+// SetBlock is used for Avro Object Container Files where the data is split in blocks and sets a data block
+// for this decoder and sets the position to the start of this block.
+func (this *StreamBinaryDecoder) SetBlock(block *DataBlock) {
+	this.reader = bytes.NewReader(block.Data)
+	this.pos = 0
+	this.err = nil
+}
+
+// Seek moves the reading position of this StreamBinaryDecoder forward to a given value by discarding the bytes
+// in between. Seeking backwards is not possible on a stream and makes subsequent reads return InvalidSeek.
+func (this *StreamBinaryDecoder) Seek(pos int64) {
+	if this.err != nil {
+		return
+	}
+	if pos < this.pos {
+		this.err = InvalidSeek
+		return
+	}
+	n, err := io.CopyN(ioutil.Discard, this.reader, pos-this.pos)
+	this.pos += n
+	if err != nil {
+		this.err = streamError(err)
+	}
+}
+
+// Tell returns the number of bytes consumed by this StreamBinaryDecoder so far.
+func (this *StreamBinaryDecoder) Tell() int64 {
+	return this.pos
+}
+
+func (this *StreamBinaryDecoder) readByte() (byte, error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	b, err := this.reader.ReadByte()
+	if err != nil {
+		return 0, streamError(err)
+	}
+	this.pos++
+	return b, nil
+}
+
+func (this *StreamBinaryDecoder) readFull(bytes []byte) error {
+	if this.err != nil {
+		return this.err
+	}
+	n, err := io.ReadFull(this.reader, bytes)
+	this.pos += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		return UnexpectedEOF
+	}
+	if err != nil {
+		return streamError(err)
+	}
+	return nil
+}
+
+// reads the contents of a bytes or string value of a given length. Long values are read in chunks so that a corrupted
+// length cannot allocate more memory than the stream actually holds.
+func (this *StreamBinaryDecoder) readLengthPrefixed(length int64) ([]byte, error) {
+	if length > this.maxAllocSize {
+		return nil, SizeLimitExceeded
+	}
+	if length <= stream_chunk_size {
+		value := make([]byte, length)
+		if err := this.readFull(value); err != nil {
+			if err == EOF {
+				return nil, UnexpectedEOF
+			}
+			return nil, err
+		}
+		return value, nil
+	}
+
+	if this.err != nil {
+		return nil, this.err
+	}
+	value := bytes.NewBuffer(make([]byte, 0, stream_chunk_size))
+	n, err := io.CopyN(value, this.reader, length)
+	this.pos += n
+	if err == io.EOF {
+		return nil, UnexpectedEOF
+	}
+	if err != nil {
+		return nil, streamError(err)
+	}
+	return value.Bytes(), nil
+}
+
+func (this *StreamBinaryDecoder) readVarint(maxSize int, overflow error) (uint64, error) {
+	var value uint64
+	for offset := 0; ; offset++ {
+		if offset == maxSize {
+			return 0, overflow
+		}
+		b, err := this.readByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint64(b&0x7F) << uint(7*offset)
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+}
+
+func (this *StreamBinaryDecoder) readItemCount(limit int64) (int64, error) {
+	count, err := this.ReadLong()
+	if err != nil {
+		return 0, err
+	}
+	if count < 0 {
+		if _, err := this.ReadLong(); err != nil {
+			return 0, err
+		}
+		count = -count
+	}
+	if count > limit {
+		return 0, SizeLimitExceeded
+	}
+	return count, nil
+}
+
+// contextReader is an io.Reader that returns the error of a context once it is done, even while a read of the
+// underlying io.Reader is blocked
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+type contextReadResult struct {
+	n   int
+	err error
+}
+
+func (this *contextReader) Read(p []byte) (int, error) {
+	if err := this.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if this.ctx.Done() == nil {
+		return this.reader.Read(p)
+	}
+
+	// the read goes to a separate buffer as an abandoned read may still complete after returning
+	buf := make([]byte, len(p))
+	done := make(chan contextReadResult, 1)
+	go func() {
+		n, err := this.reader.Read(buf)
+		done <- contextReadResult{n, err}
+	}()
+	select {
+	case result := <-done:
+		return copy(p, buf[:result.n]), result.err
+	case <-this.ctx.Done():
+		return 0, this.ctx.Err()
+	}
+}
+
+func toByteReader(r io.Reader) byteReader {
+	if br, ok := r.(byteReader); ok {
+		return br
+	}
+	return bufio.NewReader(r)
+}
+
+// translates the io package end of stream errors to EOF and leaves the rest as is
+func streamError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return EOF
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+package avro
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStreamPositioning(t *testing.T) {
+	buf, types, expected := getTestData()
+	dec := NewStreamBinaryDecoder(bytes.NewReader(buf))
+	for i := 0; i < len(types); i++ {
+		var value interface{}
+		var err error
+		switch types[i] {
+		case type_boolean:
+			value, err = dec.ReadBoolean()
+		case type_int:
+			value, err = dec.ReadInt()
+		case type_long:
+			value, err = dec.ReadLong()
+		case type_float:
+			value, err = dec.ReadFloat()
+		case type_double:
+			value, err = dec.ReadDouble()
+		case type_bytes:
+			_, err = dec.ReadBytes()
+			value = expected[i]
+		case type_string:
+			value, err = dec.ReadString()
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error reading %s: %v", types[i], err)
+		}
+		if value != expected[i] {
+			t.Fatalf("Unexpected %s: expected %v, actual %v", types[i], expected[i], value)
+		}
+	}
+	assert(t, dec.Tell(), int64(len(buf)))
+
+	if _, err := dec.ReadLong(); err != EOF {
+		t.Fatalf("Unexpected error at end of stream: expected %v, actual %v", EOF, err)
+	}
+}
+
+func TestStreamNonByteReader(t *testing.T) {
+	for value, encoded := range goodStrings {
+		dec := NewStreamBinaryDecoder(io.LimitReader(bytes.NewReader(encoded), int64(len(encoded))))
+		if actual, err := dec.ReadString(); err != nil || actual != value {
+			t.Fatalf("Unexpected string: expected %v, actual %v, error %v", value, actual, err)
+		}
+	}
+}
+
+func TestStreamTruncated(t *testing.T) {
+	for index := 0; index < len(badBytes); index++ {
+		pair := badBytes[index]
+		expected := pair[0].(error)
+		if _, err := NewStreamBinaryDecoder(bytes.NewReader(pair[1].([]byte))).ReadBytes(); err != expected {
+			t.Fatalf("Unexpected error for bytes: expected %v, actual %v", expected, err)
+		}
+	}
+
+	if _, err := NewStreamBinaryDecoder(bytes.NewReader([]byte{0x00, 0x00})).ReadDouble(); err != UnexpectedEOF {
+		t.Fatalf("Unexpected error for double: expected %v, actual %v", UnexpectedEOF, err)
+	}
+	if _, err := NewStreamBinaryDecoder(bytes.NewReader(nil)).ReadDouble(); err != EOF {
+		t.Fatalf("Unexpected error for double: expected %v, actual %v", EOF, err)
+	}
+}
+
+func TestStreamHugeLength(t *testing.T) {
+	// a length of 2^62 followed by a few bytes must fail without allocating the whole length up front
+	huge := []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01, 0x61, 0x62}
+	_, err := NewStreamBinaryDecoder(bytes.NewReader(huge)).ReadBytes()
+	assert(t, err, SizeLimitExceeded)
+	dec := NewStreamBinaryDecoder(bytes.NewReader(huge))
+	dec.SetMaxAllocSize(math.MaxInt64)
+	_, err = dec.ReadBytes()
+	assert(t, err, UnexpectedEOF)
+	dec = NewStreamBinaryDecoder(bytes.NewReader(huge))
+	dec.SetMaxAllocSize(math.MaxInt64)
+	_, err = dec.ReadString()
+	assert(t, err, UnexpectedEOF)
+
+	long := make([]byte, 3*stream_chunk_size)
+	for i := range long {
+		long[i] = byte(i)
+	}
+	buf := &bytes.Buffer{}
+	NewBinaryEncoder(buf).WriteBytes(long)
+	value, err := NewStreamBinaryDecoder(buf).ReadBytes()
+	assert(t, err, nil)
+	assert(t, value, long)
+}
+
+func TestStreamContextCancel(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close()
+	go pipeWriter.Write([]byte{0x02})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dec := NewStreamBinaryDecoderContext(ctx, pipeReader)
+	value, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, value, int32(1))
+
+	// nothing else is written so the next read blocks until the context is cancelled
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = dec.ReadInt()
+	assert(t, err, context.Canceled)
+	_, err = dec.ReadString()
+	assert(t, err, context.Canceled)
+}
+
+func TestStreamContextDeadline(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := NewStreamBinaryDecoderContext(ctx, pipeReader).ReadLong()
+	assert(t, err, context.DeadlineExceeded)
+}
+
+func TestStreamContextRead(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, c := range []context.Context{context.Background(), ctx} {
+		for value, encoded := range goodStrings {
+			if actual, err := NewStreamBinaryDecoderContext(c, bytes.NewReader(encoded)).ReadString(); err != nil || actual != value {
+				t.Fatalf("Unexpected string: expected %v, actual %v, error %v", value, actual, err)
+			}
+		}
+	}
+}
+
+func TestStreamSeek(t *testing.T) {
+	dec := NewStreamBinaryDecoder(bytes.NewReader([]byte{0x02, 0x04, 0x06, 0x08}))
+	dec.Seek(2)
+	assert(t, dec.Tell(), int64(2))
+	value, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, value, int64(3))
+
+	dec.Seek(1)
+	if _, err := dec.ReadLong(); err != InvalidSeek {
+		t.Fatalf("Unexpected error after seeking backwards: expected %v, actual %v", InvalidSeek, err)
+	}
+}
+
+func TestStreamSetBlock(t *testing.T) {
+	dec := NewStreamBinaryDecoder(bytes.NewReader(nil))
+	dec.SetBlock(&DataBlock{Data: []byte{0x06, 0x66, 0x6F, 0x6F}, BlockSize: 4})
+	value, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, value, "foo")
+	assert(t, dec.Tell(), int64(4))
+}
+
+func streamBenchmarkPayload() []byte {
+	buffer := &bytes.Buffer{}
+	enc := NewBinaryEncoder(buffer)
+	for i := 0; i < 10000; i++ {
+		enc.WriteLong(int64(i * i))
+		enc.WriteString("a string value to decode")
+	}
+	return buffer.Bytes()
+}
+
+func BenchmarkBinaryDecoderWholePayload(b *testing.B) {
+	payload := streamBenchmarkPayload()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ := ioutil.ReadAll(io.LimitReader(bytes.NewReader(payload), int64(len(payload))))
+		dec := NewBinaryDecoder(buf)
+		for j := 0; j < 10000; j++ {
+			dec.ReadLong()
+			dec.ReadString()
+		}
+	}
+}
+
+func BenchmarkStreamBinaryDecoder(b *testing.B) {
+	payload := streamBenchmarkPayload()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewStreamBinaryDecoder(io.LimitReader(bytes.NewReader(payload), int64(len(payload))))
+		for j := 0; j < 10000; j++ {
+			dec.ReadLong()
+			dec.ReadString()
+		}
+	}
+}
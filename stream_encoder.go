@@ -0,0 +1,134 @@
+package avro
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// StreamBinaryEncoder implements Encoder and provides low-level support for serializing Avro values straight to an
+// io.Writer, so that large outputs never have to be held in memory. It is the counterpart of StreamBinaryDecoder.
+// As the Encoder methods do not return errors, the first error of the io.Writer is kept and returned by Err, and all
+// writes after it are dropped. Wrap the io.Writer with a bufio.Writer if it is slow to write to in small pieces.
+type StreamBinaryEncoder struct {
+	writer  io.Writer
+	pos     int64
+	err     error
+	scratch [max_varint_size]byte
+}
+
+// Creates a new StreamBinaryEncoder that will write to a given io.Writer.
+func NewStreamBinaryEncoder(w io.Writer) *StreamBinaryEncoder {
+	return &StreamBinaryEncoder{writer: w}
+}
+
+// Returns the first error that occurred writing to the underlying io.Writer, or nil if all writes succeeded.
+func (this *StreamBinaryEncoder) Err() error {
+	return this.err
+}
+
+// Writes a null value. Doesn't actually do anything in this implementation.
+func (this *StreamBinaryEncoder) WriteNull(_ interface{}) {
+	//do nothing
+}
+
+// Tell returns the number of bytes written to the underlying io.Writer so far.
+func (this *StreamBinaryEncoder) Tell() int64 {
+	return this.pos
+}
+
+// Writes a boolean value.
+func (this *StreamBinaryEncoder) WriteBoolean(x bool) {
+	if x {
+		this.scratch[0] = 0x01
+	} else {
+		this.scratch[0] = 0x00
+	}
+	this.write(this.scratch[:1])
+}
+
+// Writes an int value.
+func (this *StreamBinaryEncoder) WriteInt(x int32) {
+	this.WriteLong(int64(x))
+}
+
+// Writes a long value.
+func (this *StreamBinaryEncoder) WriteLong(x int64) {
+	this.write(this.scratch[:putVarint(this.scratch[:], x)])
+}
+
+// Writes a float value.
+func (this *StreamBinaryEncoder) WriteFloat(x float32) {
+	binary.LittleEndian.PutUint32(this.scratch[:4], math.Float32bits(x))
+	this.write(this.scratch[:4])
+}
+
+// Writes a double value.
+func (this *StreamBinaryEncoder) WriteDouble(x float64) {
+	binary.LittleEndian.PutUint64(this.scratch[:8], math.Float64bits(x))
+	this.write(this.scratch[:8])
+}
+
+// Writes raw bytes to this Encoder, e.g. a fixed value or a value already encoded in Avro binary format.
+func (this *StreamBinaryEncoder) WriteRaw(x []byte) {
+	this.write(x)
+}
+
+// Writes a bytes value.
+func (this *StreamBinaryEncoder) WriteBytes(x []byte) {
+	this.WriteLong(int64(len(x)))
+	this.write(x)
+}
+
+// Writes a string value.
+func (this *StreamBinaryEncoder) WriteString(x string) {
+	this.WriteLong(int64(len(x)))
+	if sw, ok := this.writer.(io.StringWriter); ok && this.err == nil {
+		n, err := sw.WriteString(x)
+		this.pos += int64(n)
+		if err != nil {
+			this.err = err
+			return
+		}
+		x = x[n:]
+	}
+	this.write([]byte(x))
+}
+
+// WriteArrayStart should be called when starting to serialize an array providing it with a number of items in
+// array block.
+func (this *StreamBinaryEncoder) WriteArrayStart(count int64) {
+	this.WriteLong(count)
+}
+
+// WriteArrayNext should be called after finishing writing an array block either passing it the number of items in
+// next block or 0 indicating the end of array.
+func (this *StreamBinaryEncoder) WriteArrayNext(count int64) {
+	this.WriteLong(count)
+}
+
+// WriteMapStart should be called when starting to serialize a map providing it with a number of items in
+// map block.
+func (this *StreamBinaryEncoder) WriteMapStart(count int64) {
+	this.WriteLong(count)
+}
+
+// WriteMapNext should be called after finishing writing a map block either passing it the number of items in
+// next block or 0 indicating the end of map.
+func (this *StreamBinaryEncoder) WriteMapNext(count int64) {
+	this.WriteLong(count)
+}
+
+// writes all of given bytes to the underlying io.Writer, retrying short writes, unless an error occurred before
+func (this *StreamBinaryEncoder) write(p []byte) {
+	for len(p) > 0 && this.err == nil {
+		n, err := this.writer.Write(p)
+		this.pos += int64(n)
+		p = p[n:]
+		if err != nil {
+			this.err = err
+		} else if n == 0 {
+			this.err = io.ErrShortWrite
+		}
+	}
+}
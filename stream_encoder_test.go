@@ -0,0 +1,121 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// writes values of every kind to a given Encoder
+func writeStreamEncoderValues(enc Encoder) {
+	enc.WriteNull(nil)
+	enc.WriteBoolean(true)
+	enc.WriteInt(-300)
+	enc.WriteLong(int64(1) << 40)
+	enc.WriteFloat(1.5)
+	enc.WriteDouble(-2.25)
+	enc.WriteBytes([]byte{1, 2, 3})
+	enc.WriteString("stream")
+	enc.WriteRaw([]byte{0xDE, 0xAD})
+	enc.WriteArrayStart(1)
+	enc.WriteLong(7)
+	enc.WriteArrayNext(0)
+	enc.WriteMapStart(1)
+	enc.WriteString("k")
+	enc.WriteString("v")
+	enc.WriteMapNext(0)
+}
+
+// shortWriter writes at most one byte per call without reporting an error
+type shortWriter struct {
+	buf bytes.Buffer
+}
+
+func (this *shortWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return this.buf.Write(p[:1])
+}
+
+func TestStreamBinaryEncoder(t *testing.T) {
+	expected := NewBinaryEncoder(nil)
+	writeStreamEncoderValues(expected)
+
+	buf := &bytes.Buffer{}
+	enc := NewStreamBinaryEncoder(buf)
+	writeStreamEncoderValues(enc)
+	assert(t, enc.Err(), nil)
+	assert(t, buf.Bytes(), expected.Bytes())
+	assert(t, enc.Tell(), int64(buf.Len()))
+
+	dec := NewStreamBinaryDecoder(buf)
+	flag, err := dec.ReadBoolean()
+	assert(t, err, nil)
+	assert(t, flag, true)
+	i, err := dec.ReadInt()
+	assert(t, err, nil)
+	assert(t, i, int32(-300))
+	l, err := dec.ReadLong()
+	assert(t, err, nil)
+	assert(t, l, int64(1)<<40)
+	f, err := dec.ReadFloat()
+	assert(t, err, nil)
+	assert(t, f, float32(1.5))
+	d, err := dec.ReadDouble()
+	assert(t, err, nil)
+	assert(t, d, -2.25)
+	b, err := dec.ReadBytes()
+	assert(t, err, nil)
+	assert(t, b, []byte{1, 2, 3})
+	s, err := dec.ReadString()
+	assert(t, err, nil)
+	assert(t, s, "stream")
+
+	short := &shortWriter{}
+	enc = NewStreamBinaryEncoder(short)
+	writeStreamEncoderValues(enc)
+	assert(t, enc.Err(), nil)
+	assert(t, short.buf.Bytes(), expected.Bytes())
+
+	enc = NewStreamBinaryEncoder(&failingWriter{remaining: 5})
+	writeStreamEncoderValues(enc)
+	assert(t, enc.Err(), io.ErrShortWrite)
+	assert(t, enc.Tell(), int64(5))
+}
+
+func TestStreamBinaryEncoderDatum(t *testing.T) {
+	schema := MustParseSchema(fuzzTestSchema)
+	record := NewGenericRecord(schema)
+	record.Set("flag", true)
+	record.Set("int", int32(-300))
+	record.Set("long", int64(1)<<40)
+	record.Set("float", float32(1.5))
+	record.Set("double", 2.5)
+	record.Set("bytes", []byte{1, 2, 3})
+	record.Set("string", "stream")
+	record.Set("enum", "HEARTS")
+	record.Set("fixed", []byte{0xDE, 0xAD, 0xBE, 0xEF})
+	record.Set("array", []interface{}{nil, int64(7)})
+	record.Set("map", map[string]interface{}{"k": "v"})
+	record.Set("union", "text")
+
+	buf := &bytes.Buffer{}
+	enc := NewStreamBinaryEncoder(buf)
+	writer := NewGenericDatumWriter()
+	writer.SetSchema(schema)
+	assert(t, writer.Write(record, enc), nil)
+	assert(t, enc.Err(), nil)
+
+	reader := NewGenericDatumReader()
+	reader.SetSchema(schema)
+	decoded := NewGenericRecord(schema)
+	assert(t, reader.Read(decoded, NewStreamBinaryDecoder(buf)), nil)
+	for _, field := range schema.(*RecordSchema).Fields {
+		value := decoded.Get(field.Name)
+		if enum, ok := value.(*GenericEnum); ok {
+			value = enum.Get()
+		}
+		assert(t, value, record.Get(field.Name))
+	}
+}
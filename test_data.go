@@ -7,6 +7,7 @@ var goodBooleans map[bool][]byte = map[bool][]byte{
 
 var badBooleans map[error][]byte = map[error][]byte{
 	InvalidBool: []byte{0x02},
+	EOF:         []byte{},
 }
 
 var goodInts map[int32][]byte = map[int32][]byte{
@@ -68,7 +69,7 @@ var goodBytes = [][]byte{
 var badBytes [][]interface{} = [][]interface{}{
 	[]interface{}{EOF, []byte(nil)},                                    //empty array with no length
 	[]interface{}{NegativeBytesLength, []byte{0x05, 0x03, 0xFF, 0x0A}}, //negative length
-	[]interface{}{EOF, []byte{0x08, 0xFF}},                             //length > array size
+	[]interface{}{UnexpectedEOF, []byte{0x08, 0xFF}},                   //length > array size
 }
 
 var goodStrings map[string][]byte = map[string][]byte{
@@ -85,5 +86,5 @@ var goodStrings map[string][]byte = map[string][]byte{
 var badStrings [][]interface{} = [][]interface{}{
 	[]interface{}{EOF, []byte(nil)},                                          //empty array with no length
 	[]interface{}{InvalidStringLength, []byte{0x05, 0x66, 0x6F, 0x6F, 0x6F}}, //negative length
-	[]interface{}{EOF, []byte{0x08, 0x66}},                                   //length > array size
+	[]interface{}{UnexpectedEOF, []byte{0x08, 0x66}},                         //length > array size
 }
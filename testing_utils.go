@@ -2,6 +2,7 @@ package avro
 
 import (
 	crand "crypto/rand"
+	"errors"
 	"math/rand"
 	"reflect"
 	"runtime"
@@ -15,6 +16,14 @@ func assert(t *testing.T, actual interface{}, expected interface{}) {
 	}
 }
 
+// checks that an error is or wraps an expected error, e.g. a DecodeError wrapping EOF
+func assertError(t *testing.T, actual error, expected error) {
+	if !errors.Is(actual, expected) {
+		_, fn, line, _ := runtime.Caller(1)
+		t.Errorf("Expected error %v, actual %v\n@%s:%d", expected, actual, fn, line)
+	}
+}
+
 func randomBytes(n int) []byte {
 	b := make([]byte, n)
 	crand.Read(b)